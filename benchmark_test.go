@@ -0,0 +1,277 @@
+package memdb_test
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"slices"
+	"strconv"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/outofforest/memdb"
+	"github.com/outofforest/memdb/indices"
+)
+
+var (
+	indexInt64  = indices.NewFieldIndex(&o, &o.Int64)
+	indexUint32 = indices.NewMultiIndex(indices.NewFieldIndex(&o, &o.Uint32), indices.NewFieldIndex(&o, &o.Bool))
+)
+
+func testFixedKeyDB(tb testing.TB) *memdb.MemDB {
+	db, err := memdb.NewMemDB(memdb.Config{
+		Entities: []reflect.Type{reflect.TypeFor[TestObject]()},
+		Indices:  []memdb.Index{indexInt64, indexUint32},
+	})
+	require.NoError(tb, err)
+	return db
+}
+
+func testFixedKeyObjects(n int) []*TestObject {
+	objs := make([]*TestObject, 0, n)
+	for i := range n {
+		o := &TestObject{
+			Int64:  int64(i % 1000),
+			Uint32: uint32(i % 10),
+			Bool:   i%2 == 0,
+		}
+		binary.BigEndian.PutUint64(o.ID[:], uint64(i+1))
+		objs = append(objs, o)
+	}
+	return objs
+}
+
+func TestTxn_InsertAllocs(t *testing.T) {
+	requireT := require.New(t)
+
+	db := testFixedKeyDB(t)
+	txn := db.Txn(true)
+	obj := testFixedKeyObjects(1)[0]
+	requireT.NoError(insert(txn, objectTableID, unsafe.Pointer(obj)))
+
+	// Nodes of the trees have been already copied by the transaction, so keys are the only possible allocations.
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = txn.Insert(objectTableID, unsafe.Pointer(obj))
+	})
+	requireT.Zero(allocs)
+}
+
+func BenchmarkInsert(b *testing.B) {
+	objs := testFixedKeyObjects(b.N)
+	db := testFixedKeyDB(b)
+	txn := db.Txn(true)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := range b.N {
+		if _, err := txn.Insert(objectTableID, unsafe.Pointer(objs[i])); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUpdate(b *testing.B) {
+	objs := testFixedKeyObjects(1000)
+	db := testFixedKeyDB(b)
+	txn := db.Txn(true)
+	for _, o := range objs {
+		require.NoError(b, insert(txn, objectTableID, unsafe.Pointer(o)))
+	}
+	require.NoError(b, txn.Commit())
+
+	updated := make([]*TestObject, 0, len(objs))
+	for _, o := range objs {
+		o2 := *o
+		o2.Int64++
+		updated = append(updated, &o2)
+	}
+
+	txn = db.Txn(true)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := range b.N {
+		if _, err := txn.Insert(objectTableID, unsafe.Pointer(updated[i%len(updated)])); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDelete(b *testing.B) {
+	objs := testFixedKeyObjects(b.N)
+	db := testFixedKeyDB(b)
+	txn := db.Txn(true)
+	for _, o := range objs {
+		require.NoError(b, insert(txn, objectTableID, unsafe.Pointer(o)))
+	}
+	require.NoError(b, txn.Commit())
+
+	txn = db.Txn(true)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := range b.N {
+		if _, err := txn.Delete(objectTableID, unsafe.Pointer(objs[i])); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFirst(b *testing.B) {
+	objs := testFixedKeyObjects(1000)
+	db := testFixedKeyDB(b)
+	txn := db.Txn(true)
+	for _, o := range objs {
+		require.NoError(b, insert(txn, objectTableID, unsafe.Pointer(o)))
+	}
+	require.NoError(b, txn.Commit())
+
+	txn = db.Txn(false)
+	args := make([][]any, 0, len(objs))
+	for _, o := range objs {
+		args = append(args, []any{o.Uint32, o.Bool})
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := range b.N {
+		if _, err := txn.First(objectTableID, indexUint32.ID(), args[i%len(args)]...); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkIterator(b *testing.B) {
+	objs := testFixedKeyObjects(1000)
+	db := testFixedKeyDB(b)
+	txn := db.Txn(true)
+	for _, o := range objs {
+		require.NoError(b, insert(txn, objectTableID, unsafe.Pointer(o)))
+	}
+	require.NoError(b, txn.Commit())
+
+	txn = db.Txn(false)
+	args := []any{int64(500)}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		it, err := txn.Iterator(objectTableID, indexInt64.ID(), args...)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for o := it.Next(); o != nil; o = it.Next() {
+		}
+	}
+}
+
+func testWideDB(tb testing.TB) *memdb.MemDB {
+	db, err := memdb.NewMemDB(memdb.Config{
+		Entities: []reflect.Type{reflect.TypeFor[TestObject]()},
+		Indices: []memdb.Index{
+			indexFoo,
+			indices.NewFieldIndex(&o, &o.Baz),
+			indices.NewFieldIndex(&o, &o.Int8),
+			indices.NewFieldIndex(&o, &o.Int16),
+			indices.NewFieldIndex(&o, &o.Int32),
+			indexInt64,
+			indices.NewFieldIndex(&o, &o.Uint8),
+			indices.NewFieldIndex(&o, &o.Uint16),
+			indexUint32,
+			indices.NewFieldIndex(&o, &o.Uint64),
+			indices.NewFieldIndex(&o, &o.Bool),
+			indexFooCount,
+		},
+	})
+	require.NoError(tb, err)
+	return db
+}
+
+func testWideObjects(n int) []unsafe.Pointer {
+	objs := make([]unsafe.Pointer, 0, n)
+	for i := range n {
+		o := &TestObject{
+			Foo:    strconv.Itoa(i % 100),
+			Baz:    strconv.Itoa(i),
+			Int8:   int8(i),
+			Int16:  int16(i),
+			Int32:  int32(i),
+			Int64:  int64(i),
+			Uint8:  uint8(i),
+			Uint16: uint16(i),
+			Uint32: uint32(i),
+			Uint64: uint64(i),
+			Bool:   i%2 == 0,
+		}
+		binary.BigEndian.PutUint64(o.ID[:], uint64(i+1))
+		objs = append(objs, unsafe.Pointer(o))
+	}
+	return objs
+}
+
+// BenchmarkWideInsert compares inserting objects into the table having many indices one by one and in batches.
+func BenchmarkWideInsert(b *testing.B) {
+	for _, size := range []int{1, 8, 32, 128, 512, 2048} {
+		objs := testWideObjects(size)
+
+		b.Run(fmt.Sprintf("Insert/%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				txn := testWideDB(b).Txn(true)
+				for _, o := range objs {
+					if _, err := txn.Insert(objectTableID, o); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("InsertBatch/%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				txn := testWideDB(b).Txn(true)
+				if _, err := txn.InsertBatch(objectTableID, objs); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkColdStart compares loading objects given in random order into the empty table having many indices
+// one by one and by bulk load.
+func BenchmarkColdStart(b *testing.B) {
+	for _, size := range []int{1000, 10000, 100000} {
+		objs := testWideObjects(size)
+		rand.New(rand.NewPCG(0, 0)).Shuffle(len(objs), func(i, j int) {
+			objs[i], objs[j] = objs[j], objs[i]
+		})
+
+		b.Run(fmt.Sprintf("Insert/%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				txn := testWideDB(b).Txn(true)
+				for _, o := range objs {
+					if _, err := txn.Insert(objectTableID, o); err != nil {
+						b.Fatal(err)
+					}
+				}
+				if err := txn.Commit(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("BulkLoad/%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				txn := testWideDB(b).Txn(true)
+				if err := txn.BulkLoad(objectTableID, slices.Values(objs)); err != nil {
+					b.Fatal(err)
+				}
+				if err := txn.Commit(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,295 @@
+package memdb
+
+import (
+	"bytes"
+	"iter"
+	"slices"
+	"sync"
+	"unsafe"
+
+	"github.com/pkg/errors"
+
+	"github.com/outofforest/iradix"
+)
+
+// BulkLoad inserts objects into the empty table. It is much faster than calling Insert for each object
+// because previous versions of objects are not looked up and each index is built in a single pass from sorted keys,
+// in parallel with other indices. Keys of all the objects are kept in memory until the index is built.
+// Objects must have unique primary keys. Indexers must be safe for concurrent use, see Indexer.
+//
+// References and validators are checked after all the objects are indexed, so they see the entire table.
+// Tables having triggers can't be bulk loaded. If error is returned, transaction should be discarded.
+func (txn *Txn) BulkLoad(table uint64, objs iter.Seq[unsafe.Pointer]) error {
+	tableSchema, ok := txn.getState().schema[table]
+	if !ok {
+		return errors.Errorf("invalid table '%d'", table)
+	}
+	if tableSchema.isView {
+		return errors.Errorf("table '%d' is a view", table)
+	}
+	if len(tableSchema.triggers) > 0 {
+		return errors.Errorf("table '%d' has triggers", table)
+	}
+
+	idSchema := tableSchema.indices[IDIndexID]
+	idTxn := txn.writableIndex(idSchema.id)
+	if idTxn.Root().Iterator().Next() != nil {
+		return errors.Errorf("table '%d' is not empty", table)
+	}
+
+	seqIndexer, _ := idSchema.Indexer.(SequenceIndexer)
+	var rows []bulkRow
+	var ids []byte
+	for obj := range objs {
+		if seqIndexer != nil {
+			txn.assignSequence(idSchema, seqIndexer, obj)
+		}
+		obj = tableSchema.copyOnInsert(obj)
+		var row bulkRow
+		var err error
+		ids, row, err = appendRow(ids, idSchema, obj)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, row)
+	}
+
+	// Rows are stored in primary key order, so the ID index is built in a single pass too.
+	slices.SortFunc(rows, func(a, b bulkRow) int {
+		return bytes.Compare(ids[a.idStart:a.idEnd], ids[b.idStart:b.idEnd])
+	})
+	for i, row := range rows {
+		id := ids[row.idStart:row.idEnd]
+		if i > 0 && bytes.Equal(id, ids[rows[i-1].idStart:rows[i-1].idEnd]) {
+			return errors.Errorf("duplicated primary key in table '%d'", table)
+		}
+		idTxn.Insert(id, row.obj)
+	}
+
+	txn.indexRows(&scratch{}, tableSchema, rows, ids, loadIndex)
+	txn.instrumentation.RowsInserted(table, uint64(len(rows)))
+	if txn.mutations != nil {
+		for _, row := range rows {
+			txn.mutations.record(table, tableSchema, row.obj)
+		}
+	}
+	return txn.checkRows(table, tableSchema, rows, ids)
+}
+
+// InsertBatch inserts objects into the table, replacing the stored ones having the same primary keys.
+// Previous versions of objects are returned in the same order. Secondary indices of the entire batch
+// are updated concurrently, so it is faster than calling Insert for each object if entity has many indices.
+// Indexers must be safe for concurrent use, see Indexer.
+//
+// References and validators are checked after all the objects are indexed, so they see the entire batch.
+// Tables having triggers can't be updated in batches. If error is returned, transaction should be discarded.
+func (txn *Txn) InsertBatch(table uint64, objs []unsafe.Pointer) ([]unsafe.Pointer, error) {
+	tableSchema, ok := txn.getState().schema[table]
+	if !ok {
+		return nil, errors.Errorf("invalid table '%d'", table)
+	}
+	if tableSchema.isView {
+		return nil, errors.Errorf("table '%d' is a view", table)
+	}
+	if len(tableSchema.triggers) > 0 {
+		return nil, errors.Errorf("table '%d' has triggers", table)
+	}
+
+	idSchema := tableSchema.indices[IDIndexID]
+	idTxn := txn.writableIndex(idSchema.id)
+	seqIndexer, _ := idSchema.Indexer.(SequenceIndexer)
+	rows := make([]bulkRow, 0, len(objs))
+	previous := make([]unsafe.Pointer, 0, len(objs))
+	var ids []byte
+	for _, obj := range objs {
+		if seqIndexer != nil {
+			txn.assignSequence(idSchema, seqIndexer, obj)
+		}
+		obj = tableSchema.copyOnInsert(obj)
+		var row bulkRow
+		var err error
+		ids, row, err = appendRow(ids, idSchema, obj)
+		if err != nil {
+			return nil, err
+		}
+		row.previous = idTxn.Insert(ids[row.idStart:row.idEnd], obj)
+		rows = append(rows, row)
+		previous = append(previous, tableSchema.copyOnRead(row.previous))
+	}
+
+	txn.indexRows(&txn.scratch, tableSchema, rows, ids, updateRows)
+	txn.instrumentation.RowsInserted(table, uint64(len(rows)))
+	if txn.mutations != nil {
+		for _, row := range rows {
+			txn.mutations.record(table, tableSchema, row.obj)
+		}
+	}
+	if err := txn.checkRows(table, tableSchema, rows, ids); err != nil {
+		return nil, err
+	}
+	return previous, nil
+}
+
+// minParallelRows is the number of rows below which updating indices concurrently is slower
+// than doing it one by one.
+const minParallelRows = 32
+
+type bulkRow struct {
+	obj      unsafe.Pointer
+	previous unsafe.Pointer
+	idStart  int
+	idEnd    int
+}
+
+// appendRow appends primary key of the object to ids.
+func appendRow(ids []byte, idSchema *IndexSchema, obj unsafe.Pointer) ([]byte, bulkRow, error) {
+	idSize := idSchema.Indexer.SizeFromObject(obj)
+	if idSize == 0 {
+		return nil, bulkRow{}, errors.New("empty primary key")
+	}
+	start := len(ids)
+	ids = append(ids, make([]byte, idSize)...)
+	end := start + int(idSchema.Indexer.FromObject(ids[start:], obj))
+	return ids[:end], bulkRow{obj: obj, idStart: start, idEnd: end}, nil
+}
+
+// indexFunc adds rows to the index.
+type indexFunc func(
+	sc *scratch,
+	indexTxn *iradix.Txn[unsafe.Pointer],
+	indexSchema *IndexSchema,
+	rows []bulkRow,
+	ids []byte,
+)
+
+// indexRows adds rows to secondary indices of the table using indexFn. If there are enough rows, indices are updated
+// concurrently, otherwise they are updated one by one using the scratch.
+func (txn *Txn) indexRows(sc *scratch, tableSchema *tableSchema, rows []bulkRow, ids []byte, indexFn indexFunc) {
+	indexTxns := make([]*iradix.Txn[unsafe.Pointer], 0, len(tableSchema.indices))
+	indexSchemas := make([]*IndexSchema, 0, len(tableSchema.indices))
+	for indexID, indexSchema := range tableSchema.indices {
+		if indexID == IDIndexID {
+			continue
+		}
+		indexTxns = append(indexTxns, txn.writableIndex(indexSchema.id))
+		indexSchemas = append(indexSchemas, indexSchema)
+	}
+
+	if len(indexSchemas) < 2 || len(rows) < minParallelRows {
+		for i, indexSchema := range indexSchemas {
+			indexFn(sc, indexTxns[i], indexSchema, rows, ids)
+		}
+		return
+	}
+
+	// Each index is stored in its own tree, so they might be built concurrently.
+	var wg sync.WaitGroup
+	for i, indexSchema := range indexSchemas {
+		wg.Go(func() {
+			indexFn(&scratch{}, indexTxns[i], indexSchema, rows, ids)
+		})
+	}
+	wg.Wait()
+}
+
+// checkRows verifies references and validators of the indexed rows.
+func (txn *Txn) checkRows(table uint64, tableSchema *tableSchema, rows []bulkRow, ids []byte) error {
+	for _, row := range rows {
+		id := ids[row.idStart:row.idEnd]
+		for _, ref := range tableSchema.references {
+			if ref.deferred {
+				s := txn.getState()
+				s.pending = append(s.pending, pendingCheck{ref: ref, id: id})
+				continue
+			}
+			if err := txn.checkParents(ref, row.obj, id); err != nil {
+				return err
+			}
+		}
+		if err := txn.validate(table, tableSchema, row.obj, id); err != nil {
+			return err
+		}
+		if len(tableSchema.views) > 0 {
+			txn.recordChange(table, id, row.previous)
+		}
+	}
+	return nil
+}
+
+// updateRows updates the index row by row reusing the key buffer. Rows are processed in order,
+// so the object inserted twice ends up in the index only once.
+func updateRows(
+	sc *scratch,
+	indexTxn *iradix.Txn[unsafe.Pointer],
+	indexSchema *IndexSchema,
+	rows []bulkRow,
+	ids []byte,
+) {
+	for _, row := range rows {
+		updateIndex(sc, indexTxn, indexSchema, row.previous, row.obj, ids[row.idStart:row.idEnd])
+	}
+}
+
+// indexEntry is the index key pointing to the object.
+type indexEntry struct {
+	key []byte
+	obj unsafe.Pointer
+}
+
+// loadIndex builds the index of the empty table. Keys of all the rows are computed and sorted first, so each node
+// is added to the rightmost path of the tree, which is never copied and never requires moving edges of the node.
+func loadIndex(
+	sc *scratch,
+	indexTxn *iradix.Txn[unsafe.Pointer],
+	indexSchema *IndexSchema,
+	rows []bulkRow,
+	ids []byte,
+) {
+	defer sc.release(sc.mark())
+
+	entries := make([]indexEntry, 0, len(rows))
+	aggregateIndexer, isAggregate := indexSchema.Indexer.(AggregateIndexer)
+	multiIndexer, isMulti := indexSchema.Indexer.(MultiKeyIndexer)
+	for _, row := range rows {
+		id := ids[row.idStart:row.idEnd]
+		switch {
+		case isAggregate:
+			if key := aggregateKey(sc, aggregateIndexer, row.obj); key != nil {
+				entries = append(entries, indexEntry{key: key, obj: row.obj})
+			}
+		case isMulti:
+			for _, key := range multiIndexer.KeysFromObject(row.obj) {
+				if key := multiKey(sc, indexSchema, key, id); key != nil {
+					entries = append(entries, indexEntry{key: key, obj: row.obj})
+				}
+			}
+		default:
+			if key := indexKey(sc, indexSchema, row.obj, id); key != nil {
+				entries = append(entries, indexEntry{key: key, obj: row.obj})
+			}
+		}
+	}
+
+	// Sort is stable, so if many objects have the same key in the unique index, the last one is stored,
+	// like if they were inserted one by one.
+	slices.SortStableFunc(entries, func(a, b indexEntry) int {
+		return bytes.Compare(a.key, b.key)
+	})
+
+	for i := 0; i < len(entries); {
+		j := i + 1
+		for j < len(entries) && bytes.Equal(entries[j].key, entries[i].key) {
+			j++
+		}
+
+		value := entries[j-1].obj
+		if isAggregate {
+			value = nil
+			for _, e := range entries[i:j] {
+				value = aggregateIndexer.Add(value, e.obj)
+			}
+		}
+		indexTxn.Insert(entries[i].key, value)
+		i = j
+	}
+}
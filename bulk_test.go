@@ -0,0 +1,180 @@
+package memdb_test
+
+import (
+	"encoding/binary"
+	"reflect"
+	"slices"
+	"strconv"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/outofforest/memdb"
+	"github.com/outofforest/memdb/indices"
+)
+
+func testBulkDB(t *testing.T, validators ...memdb.Validator) *memdb.MemDB {
+	db, err := memdb.NewMemDB(memdb.Config{
+		Entities:   []reflect.Type{reflect.TypeFor[TestObject]()},
+		Indices:    []memdb.Index{indexFoo, indexFooBaz, indexFooBazDesc, indexFooCount},
+		Validators: validators,
+	})
+	require.NoError(t, err)
+	return db
+}
+
+func testBulkObjects(n int) []unsafe.Pointer {
+	objs := make([]unsafe.Pointer, 0, n)
+	for i := range n {
+		o := &TestObject{
+			Foo: strconv.Itoa(i % 7),
+			Baz: strconv.Itoa(i % 3),
+		}
+		binary.BigEndian.PutUint64(o.ID[:], uint64(n-i))
+		objs = append(objs, unsafe.Pointer(o))
+	}
+	return objs
+}
+
+func readIndex(t *testing.T, txn *memdb.Txn, index uint64) []unsafe.Pointer {
+	seq, err := txn.All(objectTableID, index)
+	require.NoError(t, err)
+	return slices.Collect(seq)
+}
+
+func TestTxn_BulkLoad(t *testing.T) {
+	requireT := require.New(t)
+
+	objs := testBulkObjects(1000)
+
+	expectedDB := testBulkDB(t)
+	txn := expectedDB.Txn(true)
+	for _, o := range objs {
+		requireT.NoError(insert(txn, objectTableID, o))
+	}
+	requireT.NoError(txn.Commit())
+
+	db := testBulkDB(t)
+	txn = db.Txn(true)
+	requireT.NoError(txn.BulkLoad(objectTableID, slices.Values(objs)))
+	requireT.NoError(txn.Commit())
+
+	expectedTxn := expectedDB.Txn(false)
+	txn = db.Txn(false)
+	for _, index := range []uint64{memdb.IDIndexID, indexFoo.ID(), indexFooBaz.ID(), indexFooBazDesc.ID()} {
+		requireT.Equal(readIndex(t, expectedTxn, index), readIndex(t, txn, index))
+	}
+
+	count, err := txn.Aggregate(objectTableID, indexFooCount.ID(), "3")
+	requireT.NoError(err)
+	expectedCount, err := expectedTxn.Aggregate(objectTableID, indexFooCount.ID(), "3")
+	requireT.NoError(err)
+	requireT.Equal((*indices.Aggregate[uint64])(expectedCount).Count, (*indices.Aggregate[uint64])(count).Count)
+	requireT.NotZero((*indices.Aggregate[uint64])(count).Count)
+
+	// Table is not empty anymore.
+	txn = db.Txn(true)
+	requireT.ErrorContains(txn.BulkLoad(objectTableID, slices.Values(objs)), "is not empty")
+}
+
+func TestTxn_BulkLoadErrors(t *testing.T) {
+	requireT := require.New(t)
+
+	objs := testBulkObjects(10)
+
+	txn := testBulkDB(t).Txn(true)
+	err := txn.BulkLoad(objectTableID, slices.Values(append(objs, objs[0])))
+	requireT.ErrorContains(err, "duplicated primary key")
+
+	txn = testBulkDB(t, memdb.NewValidator(func(txn *memdb.Txn, o *TestObject) error {
+		if o.Foo == "5" {
+			return errInvalidName
+		}
+		return nil
+	})).Txn(true)
+	requireT.ErrorIs(txn.BulkLoad(objectTableID, slices.Values(objs)), errInvalidName)
+
+	db, err := memdb.NewMemDB(memdb.Config{
+		Entities: []reflect.Type{reflect.TypeFor[TestObject]()},
+		Triggers: []memdb.Trigger{
+			memdb.NewTrigger(memdb.AfterInsert, func(txn *memdb.Txn, oldObj, newObj *TestObject) error {
+				return nil
+			}),
+		},
+	})
+	requireT.NoError(err)
+	requireT.ErrorContains(db.Txn(true).BulkLoad(objectTableID, slices.Values(objs)), "has triggers")
+}
+
+func TestTxn_InsertBatch(t *testing.T) {
+	requireT := require.New(t)
+
+	objs := testBulkObjects(1000)
+	updates := testBulkObjects(500)
+	for _, o := range updates {
+		(*TestObject)(o).Foo += "u"
+	}
+	// Object updated twice in the same batch.
+	updates = append(updates, unsafe.Pointer(&TestObject{ID: (*TestObject)(updates[0]).ID, Foo: "x", Baz: "y"}))
+
+	expectedDB := testBulkDB(t)
+	txn := expectedDB.Txn(true)
+	var expectedPrevious []unsafe.Pointer
+	for _, o := range append(slices.Clone(objs), updates...) {
+		previous, err := txn.Insert(objectTableID, o)
+		requireT.NoError(err)
+		expectedPrevious = append(expectedPrevious, previous)
+	}
+	requireT.NoError(txn.Commit())
+
+	db := testBulkDB(t)
+	txn = db.Txn(true)
+	previous, err := txn.InsertBatch(objectTableID, objs)
+	requireT.NoError(err)
+	previous2, err := txn.InsertBatch(objectTableID, updates)
+	requireT.NoError(err)
+	requireT.NoError(txn.Commit())
+	requireT.Equal(expectedPrevious, append(previous, previous2...))
+
+	expectedTxn := expectedDB.Txn(false)
+	txn = db.Txn(false)
+	for _, index := range []uint64{memdb.IDIndexID, indexFoo.ID(), indexFooBaz.ID(), indexFooBazDesc.ID()} {
+		requireT.Equal(readIndex(t, expectedTxn, index), readIndex(t, txn, index))
+	}
+
+	for _, foo := range []string{"3", "3u", "x"} {
+		count, err := txn.Aggregate(objectTableID, indexFooCount.ID(), foo)
+		requireT.NoError(err)
+		expectedCount, err := expectedTxn.Aggregate(objectTableID, indexFooCount.ID(), foo)
+		requireT.NoError(err)
+		requireT.Equal((*indices.Aggregate[uint64])(expectedCount).Count, (*indices.Aggregate[uint64])(count).Count)
+	}
+}
+
+func TestTxn_InsertBatchErrors(t *testing.T) {
+	requireT := require.New(t)
+
+	objs := testBulkObjects(10)
+
+	txn := testBulkDB(t, memdb.NewValidator(func(txn *memdb.Txn, o *TestObject) error {
+		if o.Foo == "5" {
+			return errInvalidName
+		}
+		return nil
+	})).Txn(true)
+	_, err := txn.InsertBatch(objectTableID, objs)
+	requireT.ErrorIs(err, errInvalidName)
+
+	db, err := memdb.NewMemDB(memdb.Config{
+		Entities: []reflect.Type{reflect.TypeFor[TestObject]()},
+		Triggers: []memdb.Trigger{
+			memdb.NewTrigger(memdb.AfterInsert, func(txn *memdb.Txn, oldObj, newObj *TestObject) error {
+				return nil
+			}),
+		},
+	})
+	requireT.NoError(err)
+	_, err = db.Txn(true).InsertBatch(objectTableID, objs)
+	requireT.ErrorContains(err, "has triggers")
+}
@@ -0,0 +1,397 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/pkg/errors"
+
+	"github.com/outofforest/memdb/internal/tags"
+)
+
+type listedPackage struct {
+	ImportPath string
+	Name       string
+	Dir        string
+	Export     string
+	GoFiles    []string
+}
+
+type entity struct {
+	Name    string
+	Indices []index
+}
+
+type index struct {
+	Name       string
+	Var        string
+	Indexer    string
+	Unique     bool
+	Components []component
+	FixedSize  uint64
+	VarSizes   []string
+	HasDesc    bool
+}
+
+type component struct {
+	Field string
+	Put   string
+	Arg   string
+	Desc  bool
+}
+
+type templateData struct {
+	Package    string
+	StdImports []string
+	Imports    []string
+	ConfigFunc string
+	Entities   []entity
+}
+
+// generate produces code of indexers for entities defined in the package stored in dir.
+func generate(dir, output, configFunc string) ([]byte, error) {
+	pkgs, err := listPackages(dir)
+	if err != nil {
+		return nil, err
+	}
+	target := pkgs[len(pkgs)-1]
+	exports := map[string]string{}
+	for _, p := range pkgs {
+		exports[p.ImportPath] = p.Export
+	}
+
+	fset := token.NewFileSet()
+	files := make([]*ast.File, 0, len(target.GoFiles))
+	for _, f := range target.GoFiles {
+		if f == output {
+			continue
+		}
+		file, err := parser.ParseFile(fset, filepath.Join(target.Dir, f), nil, parser.ParseComments)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		files = append(files, file)
+	}
+
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "gc", func(path string) (io.ReadCloser, error) {
+			export := exports[path]
+			if export == "" {
+				return nil, errors.Errorf("no export data for package %s", path)
+			}
+			return os.Open(export)
+		}),
+		// Package may reference symbols which are not generated yet.
+		Error: func(err error) {},
+	}
+	pkg, _ := conf.Check(target.ImportPath, fset, files, nil)
+
+	imports := map[string]bool{
+		"reflect":                              true,
+		"unsafe":                               true,
+		"github.com/outofforest/memdb":         true,
+		"github.com/outofforest/memdb/indices": true,
+	}
+	qualifier := func(p *types.Package) string {
+		if p == pkg {
+			return ""
+		}
+		imports[p.Path()] = true
+		return p.Name()
+	}
+
+	data := templateData{
+		Package:    target.Name,
+		ConfigFunc: configFunc,
+	}
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				if _, ok := typeSpec.Type.(*ast.StructType); !ok || typeSpec.TypeParams != nil {
+					continue
+				}
+				e, err := entityFromType(pkg.Scope().Lookup(typeSpec.Name.Name), qualifier)
+				if err != nil {
+					return nil, err
+				}
+				if e != nil {
+					data.Entities = append(data.Entities, *e)
+				}
+			}
+		}
+	}
+	if len(data.Entities) == 0 {
+		return nil, errors.Errorf("no entities found in package %s", target.ImportPath)
+	}
+
+	for imp := range imports {
+		if strings.Contains(strings.Split(imp, "/")[0], ".") {
+			data.Imports = append(data.Imports, imp)
+		} else {
+			data.StdImports = append(data.StdImports, imp)
+		}
+	}
+	sort.Strings(data.StdImports)
+	sort.Strings(data.Imports)
+
+	buf := &bytes.Buffer{}
+	if err := codeTemplate.Execute(buf, data); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	code, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, errors.Wrapf(err, "formatting generated code failed:\n%s", buf.String())
+	}
+	return code, nil
+}
+
+func listPackages(dir string) ([]listedPackage, error) {
+	cmd := exec.Command("go", "list", "-e", "-export", "-deps", "-json=ImportPath,Name,Dir,Export,GoFiles", ".")
+	cmd.Dir = dir
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Wrap(err, "listing packages failed")
+	}
+
+	var pkgs []listedPackage
+	decoder := json.NewDecoder(bytes.NewReader(out))
+	for decoder.More() {
+		var p listedPackage
+		if err := decoder.Decode(&p); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		pkgs = append(pkgs, p)
+	}
+	if len(pkgs) == 0 {
+		return nil, errors.Errorf("no package found in %s", dir)
+	}
+	return pkgs, nil
+}
+
+func entityFromType(obj types.Object, qualifier types.Qualifier) (*entity, error) {
+	st, ok := obj.Type().Underlying().(*types.Struct)
+	if !ok {
+		return nil, nil
+	}
+
+	fields := make([]tags.Field, 0, st.NumFields())
+	fieldTypes := map[string]types.Type{}
+	for i := range st.NumFields() {
+		f := st.Field(i)
+		fields = append(fields, tags.Field{
+			Name: f.Name(),
+			Tag:  reflect.StructTag(st.Tag(i)).Get(tags.Name),
+		})
+		fieldTypes[f.Name()] = f.Type()
+	}
+
+	tagIndices, err := tags.Indices(fields)
+	if err != nil {
+		return nil, errors.Wrapf(err, "entity %s", obj.Name())
+	}
+	if len(tagIndices) == 0 {
+		return nil, nil
+	}
+
+	e := &entity{
+		Name: obj.Name(),
+	}
+	for _, ti := range tagIndices {
+		i := index{
+			Name:    ti.Name,
+			Var:     obj.Name() + upperFirst(ti.Name) + "Index",
+			Indexer: lowerFirst(obj.Name()) + upperFirst(ti.Name) + "Indexer",
+			Unique:  ti.Unique,
+		}
+		for _, tc := range ti.Components {
+			c, size, err := componentForField(tc.Field, fieldTypes[tc.Field], qualifier)
+			if err != nil {
+				return nil, errors.Wrapf(err, "entity %s, field %s", obj.Name(), tc.Field)
+			}
+			c.Desc = tc.Desc
+			if c.Desc {
+				c.Arg = "indices.DescArg(" + c.Arg + ")"
+				i.HasDesc = true
+			}
+			if size == 0 {
+				i.VarSizes = append(i.VarSizes, "uint64(len(e."+tc.Field+")) + 1")
+			}
+			i.FixedSize += size
+			i.Components = append(i.Components, c)
+		}
+		e.Indices = append(e.Indices, i)
+	}
+	return e, nil
+}
+
+// componentForField returns component for the field and its size. Zero size means it is variable.
+func componentForField(field string, t types.Type, qualifier types.Qualifier) (component, uint64, error) {
+	if named, ok := t.(*types.Named); ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "time" &&
+		named.Obj().Name() == "Time" {
+		return component{Field: field, Put: "PutTime", Arg: "indices.TimeArg{}"}, 12, nil
+	}
+
+	typeName := types.TypeString(t, qualifier)
+
+	switch u := t.Underlying().(type) {
+	case *types.Array:
+		if b, ok := u.Elem().(*types.Basic); ok && b.Kind() == types.Uint8 && u.Len() == 16 {
+			return component{Field: field, Put: "PutID", Arg: "indices.IDArg[" + typeName + "]{}"}, 16, nil
+		}
+	case *types.Basic:
+		switch u.Kind() {
+		case types.Bool:
+			return component{Field: field, Put: "PutBool", Arg: "indices.BoolArg[" + typeName + "]{}"}, 1, nil
+		case types.String:
+			return component{Field: field, Put: "PutString", Arg: "indices.StringArg[" + typeName + "]{}"}, 0, nil
+		case types.Int8, types.Int16, types.Int32, types.Int64:
+			return component{Field: field, Put: "PutInt", Arg: "indices.IntArg[" + typeName + "]{}"},
+				uint64(basicSizes[u.Kind()]), nil
+		case types.Uint8, types.Uint16, types.Uint32, types.Uint64:
+			return component{Field: field, Put: "PutUint", Arg: "indices.UintArg[" + typeName + "]{}"},
+				uint64(basicSizes[u.Kind()]), nil
+		}
+	}
+	return component{}, 0, errors.Errorf("unsupported type %s", typeName)
+}
+
+var basicSizes = map[types.BasicKind]int{
+	types.Int8:   1,
+	types.Int16:  2,
+	types.Int32:  4,
+	types.Int64:  8,
+	types.Uint8:  1,
+	types.Uint16: 2,
+	types.Uint32: 4,
+	types.Uint64: 8,
+}
+
+func upperFirst(s string) string {
+	r := []rune(s)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+func lowerFirst(s string) string {
+	r := []rune(s)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
+
+var codeTemplate = template.Must(template.New("code").Funcs(template.FuncMap{
+	"quote": strconv.Quote,
+	"join":  strings.Join,
+}).Parse(`// Code generated by memdbgen. DO NOT EDIT.
+
+package {{ .Package }}
+
+import (
+{{- range .StdImports }}
+	{{ quote . }}
+{{- end }}
+{{ range .Imports }}
+	{{ quote . }}
+{{- end }}
+)
+
+var (
+{{- range $e := .Entities }}
+	// {{ $e.Name }}TableID is the ID of {{ $e.Name }} table.
+	{{ $e.Name }}TableID = memdb.TableID(reflect.TypeFor[{{ $e.Name }}]())
+{{- end }}
+)
+
+var (
+{{- range $e := .Entities }}
+{{- range $e.Indices }}
+	// {{ .Var }} is the {{ .Name }} index of {{ $e.Name }}.
+	{{ .Var }} = indices.NewCustomIndex[{{ $e.Name }}]({{ quote .Name }}, {{ .Indexer }}{}, {{ .Unique }})
+{{- end }}
+{{- end }}
+)
+{{- if .ConfigFunc }}
+
+// {{ .ConfigFunc }} returns memdb config containing entities and indices defined in the package.
+func {{ .ConfigFunc }}() memdb.Config {
+	return memdb.Config{
+		Entities: []reflect.Type{
+		{{- range .Entities }}
+			reflect.TypeFor[{{ .Name }}](),
+		{{- end }}
+		},
+		Indices: []memdb.Index{
+		{{- range $e := .Entities }}
+		{{- range $e.Indices }}
+			{{ .Var }},
+		{{- end }}
+		{{- end }}
+		},
+	}
+}
+{{- end }}
+{{- range $e := .Entities }}
+{{- range $e.Indices }}
+
+var {{ .Indexer }}Args = []memdb.ArgSerializer{
+{{- range .Components }}
+	{{ .Arg }},
+{{- end }}
+}
+
+type {{ .Indexer }} struct{}
+
+func (i {{ .Indexer }}) Args() []memdb.ArgSerializer {
+	return {{ .Indexer }}Args
+}
+
+func (i {{ .Indexer }}) SizeFromObject(o unsafe.Pointer) uint64 {
+{{- if .VarSizes }}
+	e := (*{{ $e.Name }})(o)
+	return {{ join .VarSizes " + " }}{{ if .FixedSize }} + {{ .FixedSize }}{{ end }}
+{{- else }}
+	return {{ .FixedSize }}
+{{- end }}
+}
+
+func (i {{ .Indexer }}) FromObject(b []byte, o unsafe.Pointer) uint64 {
+	e := (*{{ $e.Name }})(o)
+	var n uint64
+{{- if .HasDesc }}
+	var m uint64
+{{- end }}
+{{- range .Components }}
+{{- if .Desc }}
+	m = indices.{{ .Put }}(b[n:], e.{{ .Field }})
+	indices.Negate(b[n : n+m])
+	n += m
+{{- else }}
+	n += indices.{{ .Put }}(b[n:], e.{{ .Field }})
+{{- end }}
+{{- end }}
+	return n
+}
+{{- end }}
+{{- end }}
+`))
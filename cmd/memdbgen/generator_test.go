@@ -0,0 +1,26 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateExample(t *testing.T) {
+	requireT := require.New(t)
+
+	dir := filepath.Join("internal", "example")
+	code, err := generate(dir, "memdb_gen.go", "MemDBConfig")
+	requireT.NoError(err)
+
+	expected, err := os.ReadFile(filepath.Join(dir, "memdb_gen.go"))
+	requireT.NoError(err)
+	requireT.Equal(string(expected), string(code), "generated code is outdated, run go generate")
+}
+
+func TestGenerateUnsupportedType(t *testing.T) {
+	_, err := generate(filepath.Join("testdata", "unsupported"), "memdb_gen.go", "MemDBConfig")
+	require.EqualError(t, err, "entity Entity, field Value: unsupported type int")
+}
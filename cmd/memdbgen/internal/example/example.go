@@ -0,0 +1,31 @@
+// Package example contains entities used to test code generated by memdbgen.
+package example
+
+import (
+	"time"
+
+	"github.com/outofforest/memdb"
+)
+
+//go:generate go run github.com/outofforest/memdb/cmd/memdbgen
+
+// Status is the status of the job.
+type Status string
+
+// User is the user entity.
+type User struct {
+	ID    memdb.ID
+	Email string `memdb:"index=byEmail,unique"`
+	Name  string
+	Age   uint8 `memdb:"index=byAge,desc"`
+}
+
+// Job is the job entity.
+type Job struct {
+	ID       memdb.ID
+	Owner    memdb.ID  `memdb:"index=byOwner,order=1;index=byOwnerStatus"`
+	Created  time.Time `memdb:"index=byOwner,order=2,desc"`
+	Status   Status    `memdb:"index;index=byOwnerStatus"`
+	Priority int32     `memdb:"index"`
+	Done     bool      `memdb:"index=byDone"`
+}
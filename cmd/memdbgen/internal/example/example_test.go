@@ -0,0 +1,99 @@
+package example
+
+import (
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/outofforest/memdb"
+	"github.com/outofforest/memdb/indices"
+)
+
+func TestGeneratedIndexers(t *testing.T) {
+	requireT := require.New(t)
+
+	var j Job
+	reflectIndex := indices.NewMultiIndex(
+		indices.NewFieldIndex(&j, &j.Owner),
+		indices.NewReverseIndex(indices.NewFieldIndex(&j, &j.Created)),
+	)
+
+	j = Job{
+		ID:      memdb.NewID[memdb.ID](),
+		Owner:   memdb.NewID[memdb.ID](),
+		Created: time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC),
+	}
+
+	expected := make([]byte, reflectIndex.Schema().Indexer.SizeFromObject(unsafe.Pointer(&j)))
+	reflectIndex.Schema().Indexer.FromObject(expected, unsafe.Pointer(&j))
+
+	indexer := JobByOwnerIndex.Schema().Indexer
+	b := make([]byte, indexer.SizeFromObject(unsafe.Pointer(&j)))
+	requireT.Len(b, len(expected))
+	requireT.EqualValues(len(b), indexer.FromObject(b, unsafe.Pointer(&j)))
+	requireT.Equal(expected, b)
+
+	args := indexer.Args()
+	requireT.Len(args, 2)
+	b = make([]byte, args[0].SizeFromArg(j.Owner)+args[1].SizeFromArg(j.Created))
+	n := args[0].FromArg(b, j.Owner)
+	args[1].FromArg(b[n:], j.Created)
+	requireT.Equal(expected, b)
+}
+
+func TestGeneratedConfig(t *testing.T) {
+	requireT := require.New(t)
+
+	db, err := memdb.NewMemDB(MemDBConfig())
+	requireT.NoError(err)
+
+	owner := memdb.NewID[memdb.ID]()
+	now := time.Now()
+	jobs := []*Job{
+		{ID: memdb.ID{1}, Owner: owner, Created: now, Status: "done", Done: true},
+		{ID: memdb.ID{2}, Owner: owner, Created: now.Add(time.Second), Status: "pending"},
+		{ID: memdb.ID{3}, Owner: memdb.NewID[memdb.ID](), Created: now, Status: "pending"},
+	}
+	user := &User{ID: owner, Email: "user@example.com", Age: 30}
+
+	txn := db.Txn(true)
+	for _, j := range jobs {
+		_, err := txn.Insert(JobTableID, unsafe.Pointer(j))
+		requireT.NoError(err)
+	}
+	_, err = txn.Insert(UserTableID, unsafe.Pointer(user))
+	requireT.NoError(err)
+	requireT.NoError(txn.Commit())
+
+	txn = db.Txn(false)
+
+	u, err := txn.First(UserTableID, UserByEmailIndex.ID(), "user@example.com")
+	requireT.NoError(err)
+	requireT.Equal(user, (*User)(u))
+
+	u, err = txn.First(UserTableID, UserByAgeIndex.ID(), uint8(30))
+	requireT.NoError(err)
+	requireT.Equal(user, (*User)(u))
+
+	it, err := txn.Iterator(JobTableID, JobByOwnerIndex.ID(), owner)
+	requireT.NoError(err)
+	requireT.Equal(jobs[1], (*Job)(it.Next()))
+	requireT.Equal(jobs[0], (*Job)(it.Next()))
+	requireT.Nil(it.Next())
+
+	it, err = txn.Iterator(JobTableID, JobStatusIndex.ID(), Status("pending"))
+	requireT.NoError(err)
+	requireT.Equal(jobs[1], (*Job)(it.Next()))
+	requireT.Equal(jobs[2], (*Job)(it.Next()))
+	requireT.Nil(it.Next())
+
+	j, err := txn.First(JobTableID, JobByOwnerStatusIndex.ID(), owner, "done")
+	requireT.NoError(err)
+	requireT.Equal(jobs[0], (*Job)(j))
+
+	j, err = txn.First(JobTableID, JobByDoneIndex.ID(), true)
+	requireT.NoError(err)
+	requireT.Equal(jobs[0], (*Job)(j))
+}
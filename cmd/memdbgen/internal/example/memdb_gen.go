@@ -0,0 +1,214 @@
+// Code generated by memdbgen. DO NOT EDIT.
+
+package example
+
+import (
+	"reflect"
+	"unsafe"
+
+	"github.com/outofforest/memdb"
+	"github.com/outofforest/memdb/indices"
+)
+
+var (
+	// UserTableID is the ID of User table.
+	UserTableID = memdb.TableID(reflect.TypeFor[User]())
+	// JobTableID is the ID of Job table.
+	JobTableID = memdb.TableID(reflect.TypeFor[Job]())
+)
+
+var (
+	// UserByEmailIndex is the byEmail index of User.
+	UserByEmailIndex = indices.NewCustomIndex[User]("byEmail", userByEmailIndexer{}, true)
+	// UserByAgeIndex is the byAge index of User.
+	UserByAgeIndex = indices.NewCustomIndex[User]("byAge", userByAgeIndexer{}, false)
+	// JobByOwnerIndex is the byOwner index of Job.
+	JobByOwnerIndex = indices.NewCustomIndex[Job]("byOwner", jobByOwnerIndexer{}, false)
+	// JobByOwnerStatusIndex is the byOwnerStatus index of Job.
+	JobByOwnerStatusIndex = indices.NewCustomIndex[Job]("byOwnerStatus", jobByOwnerStatusIndexer{}, false)
+	// JobStatusIndex is the Status index of Job.
+	JobStatusIndex = indices.NewCustomIndex[Job]("Status", jobStatusIndexer{}, false)
+	// JobPriorityIndex is the Priority index of Job.
+	JobPriorityIndex = indices.NewCustomIndex[Job]("Priority", jobPriorityIndexer{}, false)
+	// JobByDoneIndex is the byDone index of Job.
+	JobByDoneIndex = indices.NewCustomIndex[Job]("byDone", jobByDoneIndexer{}, false)
+)
+
+// MemDBConfig returns memdb config containing entities and indices defined in the package.
+func MemDBConfig() memdb.Config {
+	return memdb.Config{
+		Entities: []reflect.Type{
+			reflect.TypeFor[User](),
+			reflect.TypeFor[Job](),
+		},
+		Indices: []memdb.Index{
+			UserByEmailIndex,
+			UserByAgeIndex,
+			JobByOwnerIndex,
+			JobByOwnerStatusIndex,
+			JobStatusIndex,
+			JobPriorityIndex,
+			JobByDoneIndex,
+		},
+	}
+}
+
+var userByEmailIndexerArgs = []memdb.ArgSerializer{
+	indices.StringArg[string]{},
+}
+
+type userByEmailIndexer struct{}
+
+func (i userByEmailIndexer) Args() []memdb.ArgSerializer {
+	return userByEmailIndexerArgs
+}
+
+func (i userByEmailIndexer) SizeFromObject(o unsafe.Pointer) uint64 {
+	e := (*User)(o)
+	return uint64(len(e.Email)) + 1
+}
+
+func (i userByEmailIndexer) FromObject(b []byte, o unsafe.Pointer) uint64 {
+	e := (*User)(o)
+	var n uint64
+	n += indices.PutString(b[n:], e.Email)
+	return n
+}
+
+var userByAgeIndexerArgs = []memdb.ArgSerializer{
+	indices.DescArg(indices.UintArg[uint8]{}),
+}
+
+type userByAgeIndexer struct{}
+
+func (i userByAgeIndexer) Args() []memdb.ArgSerializer {
+	return userByAgeIndexerArgs
+}
+
+func (i userByAgeIndexer) SizeFromObject(o unsafe.Pointer) uint64 {
+	return 1
+}
+
+func (i userByAgeIndexer) FromObject(b []byte, o unsafe.Pointer) uint64 {
+	e := (*User)(o)
+	var n uint64
+	var m uint64
+	m = indices.PutUint(b[n:], e.Age)
+	indices.Negate(b[n : n+m])
+	n += m
+	return n
+}
+
+var jobByOwnerIndexerArgs = []memdb.ArgSerializer{
+	indices.IDArg[memdb.ID]{},
+	indices.DescArg(indices.TimeArg{}),
+}
+
+type jobByOwnerIndexer struct{}
+
+func (i jobByOwnerIndexer) Args() []memdb.ArgSerializer {
+	return jobByOwnerIndexerArgs
+}
+
+func (i jobByOwnerIndexer) SizeFromObject(o unsafe.Pointer) uint64 {
+	return 28
+}
+
+func (i jobByOwnerIndexer) FromObject(b []byte, o unsafe.Pointer) uint64 {
+	e := (*Job)(o)
+	var n uint64
+	var m uint64
+	n += indices.PutID(b[n:], e.Owner)
+	m = indices.PutTime(b[n:], e.Created)
+	indices.Negate(b[n : n+m])
+	n += m
+	return n
+}
+
+var jobByOwnerStatusIndexerArgs = []memdb.ArgSerializer{
+	indices.IDArg[memdb.ID]{},
+	indices.StringArg[Status]{},
+}
+
+type jobByOwnerStatusIndexer struct{}
+
+func (i jobByOwnerStatusIndexer) Args() []memdb.ArgSerializer {
+	return jobByOwnerStatusIndexerArgs
+}
+
+func (i jobByOwnerStatusIndexer) SizeFromObject(o unsafe.Pointer) uint64 {
+	e := (*Job)(o)
+	return uint64(len(e.Status)) + 1 + 16
+}
+
+func (i jobByOwnerStatusIndexer) FromObject(b []byte, o unsafe.Pointer) uint64 {
+	e := (*Job)(o)
+	var n uint64
+	n += indices.PutID(b[n:], e.Owner)
+	n += indices.PutString(b[n:], e.Status)
+	return n
+}
+
+var jobStatusIndexerArgs = []memdb.ArgSerializer{
+	indices.StringArg[Status]{},
+}
+
+type jobStatusIndexer struct{}
+
+func (i jobStatusIndexer) Args() []memdb.ArgSerializer {
+	return jobStatusIndexerArgs
+}
+
+func (i jobStatusIndexer) SizeFromObject(o unsafe.Pointer) uint64 {
+	e := (*Job)(o)
+	return uint64(len(e.Status)) + 1
+}
+
+func (i jobStatusIndexer) FromObject(b []byte, o unsafe.Pointer) uint64 {
+	e := (*Job)(o)
+	var n uint64
+	n += indices.PutString(b[n:], e.Status)
+	return n
+}
+
+var jobPriorityIndexerArgs = []memdb.ArgSerializer{
+	indices.IntArg[int32]{},
+}
+
+type jobPriorityIndexer struct{}
+
+func (i jobPriorityIndexer) Args() []memdb.ArgSerializer {
+	return jobPriorityIndexerArgs
+}
+
+func (i jobPriorityIndexer) SizeFromObject(o unsafe.Pointer) uint64 {
+	return 4
+}
+
+func (i jobPriorityIndexer) FromObject(b []byte, o unsafe.Pointer) uint64 {
+	e := (*Job)(o)
+	var n uint64
+	n += indices.PutInt(b[n:], e.Priority)
+	return n
+}
+
+var jobByDoneIndexerArgs = []memdb.ArgSerializer{
+	indices.BoolArg[bool]{},
+}
+
+type jobByDoneIndexer struct{}
+
+func (i jobByDoneIndexer) Args() []memdb.ArgSerializer {
+	return jobByDoneIndexerArgs
+}
+
+func (i jobByDoneIndexer) SizeFromObject(o unsafe.Pointer) uint64 {
+	return 1
+}
+
+func (i jobByDoneIndexer) FromObject(b []byte, o unsafe.Pointer) uint64 {
+	e := (*Job)(o)
+	var n uint64
+	n += indices.PutBool(b[n:], e.Done)
+	return n
+}
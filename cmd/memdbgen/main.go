@@ -0,0 +1,38 @@
+// Command memdbgen generates reflection-free indexers for entities annotated with memdb struct tags.
+//
+// It is intended to be used with go generate:
+//
+//	//go:generate go run github.com/outofforest/memdb/cmd/memdbgen
+//
+// Every struct type in the package having at least one field tagged with `memdb:"..."` is treated as an entity.
+// For each index defined by tags, exported variable <Entity><Index>Index is generated. Function returning
+// memdb config containing all the entities and indices is generated too.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	output := flag.String("output", "memdb_gen.go", "name of the generated file")
+	configFunc := flag.String("config", "MemDBConfig", "name of the generated config function, empty to skip")
+	flag.Parse()
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+
+	code, err := generate(dir, *output, *configFunc)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "memdbgen: %s\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(filepath.Join(dir, *output), code, 0o600); err != nil {
+		fmt.Fprintf(os.Stderr, "memdbgen: %s\n", err)
+		os.Exit(1)
+	}
+}
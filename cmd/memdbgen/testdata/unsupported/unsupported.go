@@ -0,0 +1,8 @@
+package unsupported
+
+import "github.com/outofforest/memdb"
+
+type Entity struct {
+	ID    memdb.ID
+	Value int `memdb:"index"`
+}
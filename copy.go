@@ -0,0 +1,206 @@
+package memdb
+
+import (
+	"reflect"
+	"sync"
+	"unsafe"
+)
+
+// CopyMode defines when objects are copied.
+type CopyMode uint8
+
+const (
+	// CopyOnInsert stores copies of inserted objects, so objects passed to Insert might be modified later.
+	CopyOnInsert CopyMode = 1 << iota
+
+	// CopyOnRead returns copies of stored objects from queries and as previous objects from Insert and Delete,
+	// so returned objects might be modified.
+	CopyOnRead
+
+	// CopyOnInsertAndRead copies objects both on insert and on read.
+	CopyOnInsertAndRead = CopyOnInsert | CopyOnRead
+)
+
+// Cloner is implemented by entities copying themselves. Clone must return the deep copy of the object.
+type Cloner[T any] interface {
+	Clone() *T
+}
+
+// CopyPolicy defines when entities stored in the table are copied. It is the safe alternative to the contract
+// forbidding modification of inserted objects, paid by the cost of copying.
+type CopyPolicy interface {
+	// Type returns type of entity policy is created for.
+	Type() reflect.Type
+
+	// Mode returns the moments objects are copied at.
+	Mode() CopyMode
+
+	// Copy returns the deep copy of the object.
+	Copy(o unsafe.Pointer) unsafe.Pointer
+}
+
+// NewCopyPolicy creates copy policy of the entity. If entity implements Cloner, its Clone method is used,
+// otherwise objects are copied using reflection. Reflection copies all the pointers, slices, maps and interfaces
+// reachable from the object, while functions and channels are shared.
+func NewCopyPolicy[T any](mode CopyMode) CopyPolicy {
+	return &copyPolicy[T]{mode: mode}
+}
+
+type copyPolicy[T any] struct {
+	mode CopyMode
+}
+
+func (p *copyPolicy[T]) Type() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+func (p *copyPolicy[T]) Mode() CopyMode {
+	return p.mode
+}
+
+func (p *copyPolicy[T]) Copy(o unsafe.Pointer) unsafe.Pointer {
+	if cloner, ok := any((*T)(o)).(Cloner[T]); ok {
+		return unsafe.Pointer(cloner.Clone())
+	}
+	return deepCopy(reflect.TypeFor[T](), o)
+}
+
+// copyOnInsert returns the object to be stored.
+func (t *tableSchema) copyOnInsert(o unsafe.Pointer) unsafe.Pointer {
+	if t.copyPolicy == nil || t.copyPolicy.Mode()&CopyOnInsert == 0 {
+		return o
+	}
+	return t.copyPolicy.Copy(o)
+}
+
+// copyOnRead returns the object to be returned to the caller.
+func (t *tableSchema) copyOnRead(o unsafe.Pointer) unsafe.Pointer {
+	if o == nil || t.copyPolicy == nil || t.copyPolicy.Mode()&CopyOnRead == 0 {
+		return o
+	}
+	return t.copyPolicy.Copy(o)
+}
+
+// deepCopy copies the object of the given type using reflection.
+func deepCopy(t reflect.Type, o unsafe.Pointer) unsafe.Pointer {
+	v := reflect.New(t)
+	v.Elem().Set(reflect.NewAt(t, o).Elem())
+
+	c := &copier{
+		visited: map[visitKey]reflect.Value{
+			// Object might reference itself.
+			{pointer: o, t: v.Type()}: v,
+		},
+	}
+	c.fix(v.Elem())
+	return v.UnsafePointer()
+}
+
+var referenceTypes sync.Map
+
+// hasReferences returns true if value of the type references memory which must be copied.
+func hasReferences(t reflect.Type) bool {
+	if v, ok := referenceTypes.Load(t); ok {
+		return v.(bool)
+	}
+
+	var result bool
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+		result = true
+	case reflect.Array:
+		result = hasReferences(t.Elem())
+	case reflect.Struct:
+		for i := range t.NumField() {
+			if hasReferences(t.Field(i).Type) {
+				result = true
+				break
+			}
+		}
+	default:
+	}
+	referenceTypes.Store(t, result)
+	return result
+}
+
+type visitKey struct {
+	pointer unsafe.Pointer
+	t       reflect.Type
+}
+
+// copier replaces memory referenced by shallow copy of the object with its copies.
+type copier struct {
+	visited map[visitKey]reflect.Value
+}
+
+// fix copies memory referenced by the value. Value must be settable.
+func (c *copier) fix(v reflect.Value) {
+	if !hasReferences(v.Type()) {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return
+		}
+		// Pointers to the same memory are copied once, so shared memory and cycles are preserved.
+		key := visitKey{pointer: v.UnsafePointer(), t: v.Type()}
+		if p, ok := c.visited[key]; ok {
+			v.Set(p)
+			return
+		}
+		p := reflect.New(v.Type().Elem())
+		c.visited[key] = p
+		p.Elem().Set(v.Elem())
+		c.fix(p.Elem())
+		v.Set(p)
+	case reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		e := reflect.New(v.Elem().Type()).Elem()
+		e.Set(v.Elem())
+		c.fix(e)
+		v.Set(e)
+	case reflect.Struct:
+		for i := range v.NumField() {
+			f := v.Field(i)
+			if !f.CanSet() {
+				// Unexported fields are copied too.
+				f = reflect.NewAt(f.Type(), unsafe.Pointer(f.UnsafeAddr())).Elem()
+			}
+			c.fix(f)
+		}
+	case reflect.Array:
+		for i := range v.Len() {
+			c.fix(v.Index(i))
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			return
+		}
+		s := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(s, v)
+		for i := range s.Len() {
+			c.fix(s.Index(i))
+		}
+		v.Set(s)
+	case reflect.Map:
+		if v.IsNil() {
+			return
+		}
+		m := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for it := v.MapRange(); it.Next(); {
+			k := reflect.New(v.Type().Key()).Elem()
+			k.Set(it.Key())
+			c.fix(k)
+			e := reflect.New(v.Type().Elem()).Elem()
+			e.Set(it.Value())
+			c.fix(e)
+			m.SetMapIndex(k, e)
+		}
+		v.Set(m)
+	default:
+	}
+}
@@ -0,0 +1,234 @@
+package memdb_test
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/outofforest/memdb"
+	"github.com/outofforest/memdb/indices"
+)
+
+type copyNode struct {
+	Value int
+	Next  *copyNode
+}
+
+type copyObject struct {
+	ID      memdb.ID
+	Node    *copyNode
+	Shared  *copyNode
+	Slice   []*copyNode
+	Map     map[string][]int
+	Any     any
+	Func    func() int
+	private []string
+}
+
+type clonedObject struct {
+	ID    memdb.ID
+	Value string
+}
+
+func (o *clonedObject) Clone() *clonedObject {
+	return &clonedObject{ID: o.ID, Value: o.Value + " cloned"}
+}
+
+func TestCopyPolicy_Reflection(t *testing.T) {
+	requireT := require.New(t)
+
+	node := &copyNode{Value: 1}
+	node.Next = &copyNode{Value: 2, Next: node}
+	obj := &copyObject{
+		ID:      memdb.ID{1},
+		Node:    node,
+		Shared:  node,
+		Slice:   []*copyNode{node, nil},
+		Map:     map[string][]int{"a": {1, 2}},
+		Any:     &copyNode{Value: 3},
+		Func:    func() int { return 4 },
+		private: []string{"a"},
+	}
+
+	policy := memdb.NewCopyPolicy[copyObject](memdb.CopyOnInsert)
+	requireT.Equal(reflect.TypeFor[copyObject](), policy.Type())
+	requireT.Equal(memdb.CopyOnInsert, policy.Mode())
+
+	c := (*copyObject)(policy.Copy(unsafe.Pointer(obj)))
+	requireT.NotSame(obj, c)
+	requireT.Equal(obj.ID, c.ID)
+	requireT.Equal(4, c.Func())
+
+	// Cycles and shared memory are preserved.
+	requireT.NotSame(node, c.Node)
+	requireT.Same(c.Node, c.Node.Next.Next)
+	requireT.Same(c.Node, c.Shared)
+	requireT.Same(c.Node, c.Slice[0])
+	requireT.Nil(c.Slice[1])
+	requireT.Equal(2, c.Node.Next.Value)
+
+	// Modifications of the original don't affect the copy.
+	node.Next.Value = 5
+	obj.Map["a"][0] = 5
+	obj.Map["b"] = nil
+	obj.Any.(*copyNode).Value = 5
+	obj.private[0] = "b"
+	requireT.Equal(2, c.Node.Next.Value)
+	requireT.Equal(map[string][]int{"a": {1, 2}}, c.Map)
+	requireT.Equal(&copyNode{Value: 3}, c.Any)
+	requireT.Equal([]string{"a"}, c.private)
+}
+
+func TestCopyPolicy_Cloner(t *testing.T) {
+	requireT := require.New(t)
+
+	obj := &clonedObject{ID: memdb.ID{1}, Value: "a"}
+	c := memdb.NewCopyPolicy[clonedObject](memdb.CopyOnRead).Copy(unsafe.Pointer(obj))
+	requireT.Equal(&clonedObject{ID: memdb.ID{1}, Value: "a cloned"}, (*clonedObject)(c))
+}
+
+func testCopyDB(t *testing.T, mode memdb.CopyMode) *memdb.MemDB {
+	db, err := memdb.NewMemDB(memdb.Config{
+		Entities:        []reflect.Type{reflect.TypeFor[TestObject]()},
+		Indices:         []memdb.Index{indexFoo},
+		CopyPolicies:    []memdb.CopyPolicy{memdb.NewCopyPolicy[TestObject](mode)},
+		DetectMutations: true,
+	})
+	require.NoError(t, err)
+	return db
+}
+
+func TestTxn_CopyOnInsert(t *testing.T) {
+	requireT := require.New(t)
+
+	db := testCopyDB(t, memdb.CopyOnInsert)
+
+	obj := &TestObject{ID: memdb.ID{1}, Foo: "a"}
+	txn := db.Txn(true)
+	requireT.NoError(insert(txn, objectTableID, unsafe.Pointer(obj)))
+	requireT.NoError(txn.Commit())
+
+	obj.Foo = "b"
+	requireT.NoError(db.Verify())
+
+	txn = db.Txn(false)
+	stored, err := txn.First(objectTableID, indexFoo.ID(), "a")
+	requireT.NoError(err)
+	requireT.NotSame(obj, (*TestObject)(stored))
+	requireT.Equal("a", (*TestObject)(stored).Foo)
+
+	// Stored objects are returned without copying.
+	stored2, err := txn.First(objectTableID, memdb.IDIndexID, obj.ID)
+	requireT.NoError(err)
+	requireT.Equal(stored, stored2)
+}
+
+func TestTxn_CopyOnRead(t *testing.T) {
+	requireT := require.New(t)
+
+	db := testCopyDB(t, memdb.CopyOnRead)
+
+	obj := &TestObject{ID: memdb.ID{1}, Foo: "a"}
+	txn := db.Txn(true)
+	requireT.NoError(insert(txn, objectTableID, unsafe.Pointer(obj)))
+	requireT.NoError(txn.Commit())
+
+	txn = db.Txn(false)
+	read, err := txn.First(objectTableID, indexFoo.ID(), "a")
+	requireT.NoError(err)
+	requireT.NotSame(obj, (*TestObject)(read))
+	(*TestObject)(read).Foo = "b"
+
+	it, err := txn.Iterator(objectTableID, indexFoo.ID())
+	requireT.NoError(err)
+	read2 := it.Next()
+	requireT.NotEqual(read, read2)
+	requireT.NotSame(obj, (*TestObject)(read2))
+	requireT.Equal("a", (*TestObject)(read2).Foo)
+	requireT.Nil(it.Next())
+
+	values, read3 := mustDistinct(t, txn)
+	requireT.Equal([]any{"a"}, values)
+	requireT.NotSame(obj, (*TestObject)(read3))
+
+	// Previous objects are copied too.
+	txn = db.Txn(true)
+	previous, err := txn.Insert(objectTableID, unsafe.Pointer(&TestObject{ID: memdb.ID{1}, Foo: "c"}))
+	requireT.NoError(err)
+	requireT.NotSame(obj, (*TestObject)(previous))
+	requireT.Equal(obj, (*TestObject)(previous))
+	requireT.NoError(db.Verify())
+}
+
+func TestTxn_CopyOnReadAggregate(t *testing.T) {
+	requireT := require.New(t)
+
+	db, err := memdb.NewMemDB(memdb.Config{
+		Entities:        []reflect.Type{reflect.TypeFor[TestObject]()},
+		Indices:         []memdb.Index{indexFoo, indexFooCount},
+		CopyPolicies:    []memdb.CopyPolicy{memdb.NewCopyPolicy[TestObject](memdb.CopyOnRead)},
+		DetectMutations: true,
+	})
+	requireT.NoError(err)
+
+	txn := db.Txn(true)
+	requireT.NoError(insert(txn, objectTableID, unsafe.Pointer(&TestObject{ID: memdb.ID{1}, Foo: "a"})))
+	requireT.NoError(insert(txn, objectTableID, unsafe.Pointer(&TestObject{ID: memdb.ID{2}, Foo: "a"})))
+	requireT.NoError(insert(txn, objectTableID, unsafe.Pointer(&TestObject{ID: memdb.ID{3}, Foo: "b"})))
+	requireT.NoError(txn.Commit())
+
+	// Aggregates are returned as stored, not copied as entities.
+	txn = db.Txn(false)
+	it, err := txn.Iterator(objectTableID, indexFooCount.ID())
+	requireT.NoError(err)
+	var counts []uint64
+	for o := it.Next(); o != nil; o = it.Next() {
+		counts = append(counts, (*indices.Aggregate[uint64])(o).Count)
+	}
+	requireT.Equal([]uint64{2, 1}, counts)
+
+	storedA, err := txn.Aggregate(objectTableID, indexFooCount.ID(), "a")
+	requireT.NoError(err)
+	storedB, err := txn.Aggregate(objectTableID, indexFooCount.ID(), "b")
+	requireT.NoError(err)
+
+	first, err := txn.First(objectTableID, indexFooCount.ID(), "b")
+	requireT.NoError(err)
+	requireT.Equal(storedB, first)
+	requireT.Equal(uint64(1), (*indices.Aggregate[uint64])(first).Count)
+
+	aggregates, err := txn.All(objectTableID, indexFooCount.ID(), "a")
+	requireT.NoError(err)
+	for o := range aggregates {
+		requireT.Equal(storedA, o)
+		requireT.Equal(uint64(2), (*indices.Aggregate[uint64])(o).Count)
+	}
+	requireT.NoError(db.Verify())
+}
+
+func mustDistinct(t *testing.T, txn *memdb.Txn) ([]any, unsafe.Pointer) {
+	it, err := txn.Distinct(objectTableID, indexFoo.ID())
+	require.NoError(t, err)
+	return it.Next()
+}
+
+func TestCopyPolicy_Config(t *testing.T) {
+	requireT := require.New(t)
+
+	_, err := memdb.NewMemDB(memdb.Config{
+		Entities: []reflect.Type{reflect.TypeFor[TestObject]()},
+		CopyPolicies: []memdb.CopyPolicy{
+			memdb.NewCopyPolicy[TestObject](memdb.CopyOnRead),
+			memdb.NewCopyPolicy[TestObject](memdb.CopyOnInsert),
+		},
+	})
+	requireT.ErrorContains(err, "duplicated copy policy")
+
+	_, err = memdb.NewMemDB(memdb.Config{
+		Entities:     []reflect.Type{reflect.TypeFor[TestObject]()},
+		CopyPolicies: []memdb.CopyPolicy{memdb.NewCopyPolicy[copyObject](memdb.CopyOnInsertAndRead)},
+	})
+	requireT.ErrorContains(err, "copy policy for undefined entity")
+}
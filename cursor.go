@@ -0,0 +1,107 @@
+package memdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"unsafe"
+
+	"github.com/pkg/errors"
+)
+
+const cursorHeaderSize = 24
+
+// Cursor is the opaque position of the iterator. It contains the index key of the last returned entity
+// and the revision of the snapshot it was taken from. Passed as the last argument to Txn.Iterator, it makes
+// the iterator continue right after that entity.
+type Cursor []byte
+
+// Revision returns revision of the snapshot the cursor was taken from.
+func (c Cursor) Revision() uint64 {
+	if len(c) < cursorHeaderSize {
+		return 0
+	}
+	return binary.BigEndian.Uint64(c)
+}
+
+func newCursor(revision, table, index uint64, key []byte) Cursor {
+	c := make(Cursor, cursorHeaderSize+len(key))
+	binary.BigEndian.PutUint64(c, revision)
+	binary.BigEndian.PutUint64(c[8:], table)
+	binary.BigEndian.PutUint64(c[16:], index)
+	copy(c[cursorHeaderSize:], key)
+	return c
+}
+
+// key returns the index key stored in the cursor after verifying it was taken from the same index.
+func (c Cursor) key(table, index uint64) ([]byte, error) {
+	if len(c) <= cursorHeaderSize {
+		return nil, errors.New("invalid cursor")
+	}
+	if binary.BigEndian.Uint64(c[8:]) != table || binary.BigEndian.Uint64(c[16:]) != index {
+		return nil, errors.New("cursor was taken from another index")
+	}
+	return c[cursorHeaderSize:], nil
+}
+
+func (r *radixIterator) Cursor() Cursor {
+	key := r.key()
+	if key == nil {
+		return nil
+	}
+	return newCursor(r.revision, r.table, r.index, key)
+}
+
+// key returns the index key of the last returned result. Nil is returned if key can't be determined.
+func (r *radixIterator) key() []byte {
+	switch r.indexSchema.Indexer.(type) {
+	case AggregateIndexer:
+		return nil
+	case MultiKeyIndexer:
+		if r.seek.back {
+			return nil
+		}
+	}
+
+	if r.lastObj != nil {
+		id, err := primaryKey(nil, r.idSchema, r.lastObj)
+		if err != nil {
+			return nil
+		}
+		r.lastKey = indexKey(nil, r.indexSchema, r.lastObj, id)
+		r.lastObj = nil
+	}
+	return r.lastKey
+}
+
+// multiKey returns the key the object has been returned for. It is the lowest key of the object placed after
+// the previous one.
+func (r *radixIterator) multiKey(indexer MultiKeyIndexer, obj unsafe.Pointer) []byte {
+	id, err := primaryKey(nil, r.idSchema, obj)
+	if err != nil {
+		return nil
+	}
+
+	var result []byte
+	for _, key := range indexer.KeysFromObject(obj) {
+		k := multiKey(nil, r.indexSchema, key, id)
+		switch {
+		case k == nil || !bytes.HasPrefix(k, r.seek.prefix):
+			continue
+		case r.lastKey != nil && bytes.Compare(k, r.lastKey) <= 0:
+			continue
+		case r.lastKey == nil && bytes.Compare(k, r.seek.lowerBound) < 0:
+			continue
+		case result == nil || bytes.Compare(k, result) < 0:
+			result = k
+		}
+	}
+	return result
+}
+
+// seek describes the position the iterator has been moved to.
+type seek struct {
+	prefix     []byte
+	lowerBound []byte
+	lastKey    []byte
+	back       bool
+}
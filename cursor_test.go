@@ -0,0 +1,132 @@
+package memdb_test
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/outofforest/memdb"
+	"github.com/outofforest/memdb/indices"
+)
+
+var indexFooBaz = indices.NewNamedIndex("fooBaz", indices.NewFuncIndex(indices.Keys(func(o *TestObject) []string {
+	return []string{o.Foo, o.Baz}
+})))
+
+func testCursorDB(t *testing.T) *memdb.MemDB {
+	db, err := memdb.NewMemDB(memdb.Config{
+		Entities: []reflect.Type{reflect.TypeFor[TestObject]()},
+		Indices:  []memdb.Index{indexFoo, indexFooBaz},
+	})
+	require.NoError(t, err)
+
+	txn := db.Txn(true)
+	for _, o := range []*TestObject{
+		{ID: memdb.ID{1}, Foo: "a", Baz: "c"},
+		{ID: memdb.ID{2}, Foo: "b", Baz: "a"},
+		{ID: memdb.ID{3}, Foo: "a", Baz: "b"},
+		{ID: memdb.ID{4}, Foo: "a", Baz: "a"},
+		{ID: memdb.ID{5}, Foo: "b", Baz: "c"},
+	} {
+		require.NoError(t, insert(txn, objectTableID, unsafe.Pointer(o)))
+	}
+	require.NoError(t, txn.Commit())
+	return db
+}
+
+func readPage(t *testing.T, txn *memdb.Txn, index uint64, size int, args ...any) ([]byte, memdb.Cursor) {
+	it, err := txn.Iterator(objectTableID, index, args...)
+	require.NoError(t, err)
+
+	var result []byte
+	for o := it.Next(); o != nil; o = it.Next() {
+		result = append(result, (*TestObject)(o).ID[0])
+		if len(result) == size {
+			break
+		}
+	}
+	return result, it.Cursor()
+}
+
+func readPages(t *testing.T, txn *memdb.Txn, index uint64, args ...any) [][]byte {
+	var result [][]byte
+	var cursor memdb.Cursor
+	for {
+		page, c := readPage(t, txn, index, 2, append(args, cursor)...)
+		if len(page) == 0 {
+			return result
+		}
+		result = append(result, page)
+		cursor = c
+	}
+}
+
+func TestCursor_NonUnique(t *testing.T) {
+	requireT := require.New(t)
+	db := testCursorDB(t)
+	txn := db.Txn(false)
+
+	requireT.Equal([][]byte{{1, 3}, {4, 2}, {5}}, readPages(t, txn, indexFoo.ID()))
+	requireT.Equal([][]byte{{1, 3}, {4}}, readPages(t, txn, indexFoo.ID(), "a"))
+	requireT.Equal([][]byte{{1, 2}, {3, 4}, {5}}, readPages(t, txn, memdb.IDIndexID))
+}
+
+func TestCursor_MultiKey(t *testing.T) {
+	requireT := require.New(t)
+	db := testCursorDB(t)
+	txn := db.Txn(false)
+
+	requireT.Equal([][]byte{{1, 2}, {3, 4}, {2, 3}, {5, 1}, {5}}, readPages(t, txn, indexFooBaz.ID()))
+	requireT.Equal([][]byte{{1, 2}, {3, 4}}, readPages(t, txn, indexFooBaz.ID(), "a"))
+}
+
+func TestCursor_Revision(t *testing.T) {
+	requireT := require.New(t)
+	db := testCursorDB(t)
+	txn := db.Txn(false)
+	requireT.EqualValues(1, txn.Revision())
+
+	page, cursor := readPage(t, txn, indexFoo.ID(), 2)
+	requireT.Equal([]byte{1, 3}, page)
+	requireT.EqualValues(1, cursor.Revision())
+
+	// Cursor is valid in newer snapshot.
+	txn = db.Txn(true)
+	requireT.NoError(insert(txn, objectTableID, unsafe.Pointer(&TestObject{ID: memdb.ID{0}, Foo: "a"})))
+	requireT.NoError(insert(txn, objectTableID, unsafe.Pointer(&TestObject{ID: memdb.ID{6}, Foo: "a"})))
+	requireT.NoError(txn.Commit())
+	requireT.EqualValues(2, txn.Revision())
+
+	txn = db.Txn(false)
+	page, cursor = readPage(t, txn, indexFoo.ID(), 2, cursor)
+	requireT.Equal([]byte{4, 6}, page)
+	requireT.EqualValues(2, cursor.Revision())
+}
+
+func TestCursor_Errors(t *testing.T) {
+	requireT := require.New(t)
+	db := testCursorDB(t)
+	txn := db.Txn(false)
+
+	_, cursor := readPage(t, txn, indexFoo.ID(), 1, "a")
+	requireT.NotNil(cursor)
+
+	_, err := txn.Iterator(objectTableID, memdb.IDIndexID, cursor)
+	requireT.ErrorContains(err, "cursor was taken from another index")
+
+	_, err = txn.Iterator(objectTableID, indexFoo.ID(), "b", cursor)
+	requireT.ErrorContains(err, "cursor does not match the arguments")
+
+	_, err = txn.Iterator(objectTableID, indexFoo.ID(), memdb.From, "a", cursor)
+	requireT.ErrorContains(err, "operators can't be used together with cursor")
+
+	_, err = txn.Iterator(objectTableID, indexFoo.ID(), memdb.Cursor{0x01})
+	requireT.ErrorContains(err, "invalid cursor")
+
+	// No cursor is returned for empty result.
+	page, cursor := readPage(t, txn, indexFoo.ID(), 1, "c")
+	requireT.Empty(page)
+	requireT.Nil(cursor)
+}
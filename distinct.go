@@ -0,0 +1,185 @@
+package memdb
+
+import (
+	"bytes"
+	"unsafe"
+
+	"github.com/pkg/errors"
+
+	"github.com/outofforest/iradix"
+)
+
+// Distinct is used to construct a DistinctIterator over distinct values of the key component following
+// the components given in args. If no args are given, distinct values of the first component are returned.
+// Rows sharing the value are skipped by seeking the index, so the cost depends on the number of distinct values,
+// not on the number of rows. Indexer must implement DecodingIndexer.
+//
+// Number of rows sharing the value is not returned. Nodes of the radix tree don't store sizes of their subtrees,
+// so counting would require visiting every row, which is exactly what skipping avoids. Counts are maintained
+// by the count index (indices.NewCountIndex) created for the same components.
+func (txn *Txn) Distinct(table, index uint64, args ...any) (DistinctIterator, error) {
+	tableSchema, ok := txn.getState().schema[table]
+	if !ok {
+		return nil, errors.Errorf("invalid table '%d'", table)
+	}
+	indexSchema, ok := tableSchema.indices[index]
+	if !ok {
+		return nil, errors.Errorf("invalid index '%d'", index)
+	}
+	if _, ok := indexSchema.Indexer.(AggregateIndexer); ok {
+		return nil, errors.Errorf("index '%d' is aggregate", index)
+	}
+	decoder, ok := indexSchema.Indexer.(DecodingIndexer)
+	if !ok {
+		return nil, errors.Errorf("index '%d' does not support decoding", index)
+	}
+
+	for _, a := range args {
+		if _, ok := a.(Operator); ok {
+			return nil, errors.New("operators can't be used to find distinct values")
+		}
+	}
+	argDefs := indexSchema.Indexer.Args()
+	if len(args) >= len(argDefs) {
+		return nil, errors.Errorf("too many arguments, received: %d, acceptable: %d", len(args),
+			len(argDefs)-1)
+	}
+
+	var keySize uint64
+	for i, a := range args {
+		keySize += argDefs[i].SizeFromArg(a)
+	}
+	prefix := make([]byte, keySize)
+	var n uint64
+	for i, a := range args {
+		n += argDefs[i].FromArg(prefix[n:], a)
+	}
+
+	return &distinctIterator{
+		root:           txn.readableIndex(indexSchema.id, true).Root(),
+		prefix:         prefix,
+		numOfValues:    len(args) + 1,
+		argDefs:        argDefs,
+		decoder:        decoder,
+		tableSchema:    tableSchema,
+		idSchema:       tableSchema.indices[IDIndexID],
+		indexSchema:    indexSchema,
+		nextLowerBound: prefix,
+
+		table:           table,
+		index:           index,
+		instrumentation: txn.instrumentation,
+		mutations:       txn.mutations,
+	}, nil
+}
+
+// DistinctIterator is used to iterate over distinct values of the key components.
+type DistinctIterator interface {
+	// Next returns the values of the leading key components and the first object having them.
+	// If there are no more results nil is returned for both.
+	Next() ([]any, unsafe.Pointer)
+
+	// Close reports the query to the instrumentation if iteration is stopped before Next returns nil.
+	// It is a noop if the iterator is exhausted or already closed.
+	Close()
+}
+
+type distinctIterator struct {
+	root        *iradix.Node[unsafe.Pointer]
+	prefix      []byte
+	numOfValues int
+	argDefs     []ArgSerializer
+	decoder     DecodingIndexer
+
+	tableSchema *tableSchema
+	idSchema    *IndexSchema
+	indexSchema *IndexSchema
+
+	// nextLowerBound is the lowest key of the next distinct value, nil if there are no more values.
+	nextLowerBound []byte
+
+	table           uint64
+	index           uint64
+	instrumentation Instrumentation
+	scanned         uint64
+	mutations       *mutationDetector
+}
+
+func (i *distinctIterator) Next() ([]any, unsafe.Pointer) {
+	values, obj := i.next()
+	if obj == nil {
+		i.Close()
+		return nil, nil
+	}
+	i.scanned++
+	if i.mutations != nil {
+		i.mutations.verifyRead(i.table, i.tableSchema, obj)
+	}
+	return values, i.tableSchema.copyOnRead(obj)
+}
+
+func (i *distinctIterator) Close() {
+	if i.instrumentation != nil {
+		i.instrumentation.QueryFinished(i.table, i.index, i.scanned)
+		i.instrumentation = nil
+	}
+}
+
+func (i *distinctIterator) next() ([]any, unsafe.Pointer) {
+	if i.nextLowerBound == nil {
+		return nil, nil
+	}
+
+	iter := i.root.Iterator()
+	if len(i.prefix) > 0 {
+		iter.SeekPrefix(i.prefix)
+	}
+	iter.SeekLowerBound(i.nextLowerBound[len(i.prefix):])
+	if i.instrumentation != nil {
+		i.instrumentation.Seek(i.table, i.index)
+	}
+
+	r := &radixIterator{
+		iter:        iter,
+		seek:        seek{prefix: i.prefix, lowerBound: i.nextLowerBound},
+		idSchema:    i.idSchema,
+		indexSchema: i.indexSchema,
+	}
+	obj := r.Next()
+	if obj == nil {
+		i.nextLowerBound = nil
+		return nil, nil
+	}
+
+	key := r.key()
+	values := i.decoder.Decode(key)
+	if len(values) < i.numOfValues {
+		i.nextLowerBound = nil
+		return nil, nil
+	}
+	values = values[:i.numOfValues]
+
+	var size uint64
+	for j, v := range values {
+		size += i.argDefs[j].SizeFromArg(v)
+	}
+	i.nextLowerBound = successor(key[:size])
+	if !bytes.HasPrefix(i.nextLowerBound, i.prefix) {
+		i.nextLowerBound = nil
+	}
+	return values, obj
+}
+
+// successor returns the lowest key greater than all the keys having the prefix.
+// Nil is returned if there is no such key.
+func successor(prefix []byte) []byte {
+	for j := len(prefix) - 1; j >= 0; j-- {
+		if prefix[j] < 0xff {
+			b := make([]byte, j+1)
+			copy(b, prefix)
+			b[j]++
+			return b
+		}
+	}
+	return nil
+}
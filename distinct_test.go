@@ -0,0 +1,102 @@
+package memdb_test
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/outofforest/memdb"
+	"github.com/outofforest/memdb/indices"
+)
+
+var (
+	indexFooBazDesc = indices.NewMultiIndex(indexFoo, indices.NewReverseIndex(indices.NewFieldIndex(&o, &o.Baz)))
+	indexFooCount   = indices.NewCountIndex(indexFoo)
+)
+
+type distinctValue struct {
+	Values []any
+	ID     byte
+}
+
+func readDistinct(t *testing.T, txn *memdb.Txn, index uint64, args ...any) []distinctValue {
+	it, err := txn.Distinct(objectTableID, index, args...)
+	require.NoError(t, err)
+
+	var result []distinctValue
+	for values, obj := it.Next(); obj != nil; values, obj = it.Next() {
+		result = append(result, distinctValue{Values: values, ID: (*TestObject)(obj).ID[0]})
+	}
+	return result
+}
+
+func TestTxn_Distinct(t *testing.T) {
+	requireT := require.New(t)
+
+	db, err := memdb.NewMemDB(memdb.Config{
+		Entities: []reflect.Type{reflect.TypeFor[TestObject]()},
+		Indices:  []memdb.Index{indexFoo, indexFooBaz, indexFooBazDesc, indexFooCount},
+	})
+	requireT.NoError(err)
+
+	txn := db.Txn(true)
+	for _, o := range []*TestObject{
+		{ID: memdb.ID{1}, Foo: "a", Baz: "c"},
+		{ID: memdb.ID{2}, Foo: "b", Baz: "a"},
+		{ID: memdb.ID{3}, Foo: "a", Baz: "b"},
+		{ID: memdb.ID{4}, Foo: "a", Baz: "a"},
+		{ID: memdb.ID{5}, Foo: "b", Baz: "c"},
+		{ID: memdb.ID{6}, Foo: "a", Baz: "c"},
+	} {
+		requireT.NoError(insert(txn, objectTableID, unsafe.Pointer(o)))
+	}
+	requireT.NoError(txn.Commit())
+
+	txn = db.Txn(false)
+	requireT.Equal([]distinctValue{
+		{Values: []any{"a"}, ID: 1},
+		{Values: []any{"b"}, ID: 2},
+	}, readDistinct(t, txn, indexFoo.ID()))
+
+	// Leading components of multi-field index.
+	requireT.Equal([]distinctValue{
+		{Values: []any{"a"}, ID: 1},
+		{Values: []any{"b"}, ID: 5},
+	}, readDistinct(t, txn, indexFooBazDesc.ID()))
+	requireT.Equal([]distinctValue{
+		{Values: []any{"a", "c"}, ID: 1},
+		{Values: []any{"a", "b"}, ID: 3},
+		{Values: []any{"a", "a"}, ID: 4},
+	}, readDistinct(t, txn, indexFooBazDesc.ID(), "a"))
+	requireT.Empty(readDistinct(t, txn, indexFooBazDesc.ID(), "c"))
+
+	// Multi-key index.
+	requireT.Equal([]distinctValue{
+		{Values: []any{"a"}, ID: 1},
+		{Values: []any{"b"}, ID: 2},
+		{Values: []any{"c"}, ID: 1},
+	}, readDistinct(t, txn, indexFooBaz.ID()))
+
+	// Iteration might be continued after iterator is closed.
+	it, err := txn.Distinct(objectTableID, indexFoo.ID())
+	requireT.NoError(err)
+	values, obj := it.Next()
+	requireT.Equal([]any{"a"}, values)
+	requireT.NotNil(obj)
+	it.Close()
+	values, obj = it.Next()
+	requireT.Equal([]any{"b"}, values)
+	requireT.NotNil(obj)
+	values, obj = it.Next()
+	requireT.Nil(values)
+	requireT.Nil(obj)
+
+	_, err = txn.Distinct(objectTableID, indexFoo.ID(), "a")
+	requireT.ErrorContains(err, "too many arguments")
+	_, err = txn.Distinct(objectTableID, indexFooBazDesc.ID(), memdb.From, "a")
+	requireT.ErrorContains(err, "operators can't be used")
+	_, err = txn.Distinct(objectTableID, indexFooCount.ID())
+	requireT.ErrorContains(err, "is aggregate")
+}
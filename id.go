@@ -2,7 +2,10 @@ package memdb
 
 import (
 	"crypto/rand"
+	"encoding/binary"
 	"reflect"
+	"sync"
+	"time"
 	"unsafe"
 
 	"github.com/samber/lo"
@@ -30,19 +33,74 @@ func NewID[T idConstraint]() T {
 	return id
 }
 
+// NewTimeID generates new time-ordered ID.
+// The layout follows UUIDv7: 48-bit unix timestamp in milliseconds, version, 12-bit counter, variant and
+// 62 random bits. IDs generated by the process are strictly increasing, so entities inserted using them
+// are ordered by creation time in the ID index.
+func NewTimeID[T idConstraint]() T {
+	var id [IDLength]byte
+	lo.Must(rand.Read(id[8:]))
+
+	ms, counter := timeIDs.next(time.Now().UnixMilli())
+
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], ms)
+	copy(id[:6], b[2:])
+	binary.BigEndian.PutUint16(id[6:], 0x7000|counter)
+	id[8] = 0x80 | id[8]&0x3f
+	return id
+}
+
+var timeIDs timeIDGenerator
+
+type timeIDGenerator struct {
+	mu      sync.Mutex
+	ms      uint64
+	counter uint16
+}
+
+// next returns timestamp and counter for the next ID. If the clock has not moved forward since the previous ID,
+// counter is incremented and when it overflows, timestamp is moved forward artificially.
+func (g *timeIDGenerator) next(ms int64) (uint64, uint16) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if uint64(ms) > g.ms {
+		g.ms = uint64(ms)
+		g.counter = 0
+		return g.ms, g.counter
+	}
+
+	g.counter++
+	if g.counter > 0xfff {
+		g.ms++
+		g.counter = 0
+	}
+	return g.ms, g.counter
+}
+
 var idType = reflect.TypeFor[ID]()
 
-var _ Indexer = IDIndexer{}
+var _ FieldIndexer = IDIndexer{}
 var _ ArgSerializer = IDIndexer{}
+var _ DecodingIndexer = IDIndexer{}
 
 // IDIndexer is used to index ID fields.
-type IDIndexer struct{}
+type IDIndexer struct {
+	// Offset is the offset of the ID field in the entity.
+	Offset uintptr
+}
 
 // Args returns arg serializer.
 func (i IDIndexer) Args() []ArgSerializer {
 	return []ArgSerializer{i}
 }
 
+// Fields returns the indexed field.
+func (i IDIndexer) Fields() []IndexField {
+	return []IndexField{{Offset: i.Offset, Type: idType}}
+}
+
 // SizeFromObject returns expected index slice size given the object.
 func (i IDIndexer) SizeFromObject(o unsafe.Pointer) uint64 {
 	return IDLength
@@ -55,13 +113,24 @@ func (i IDIndexer) SizeFromArg(arg any) uint64 {
 
 // FromArg sets index slice given the argument.
 func (i IDIndexer) FromArg(b []byte, arg any) uint64 {
-	id := reflect.ValueOf(arg).Convert(idType).Interface().(ID)
+	id, ok := arg.(ID)
+	if !ok {
+		id = reflect.ValueOf(arg).Convert(idType).Interface().(ID)
+	}
 	copy(b, id[:])
 	return IDLength
 }
 
 // FromObject sets index slice given the object.
 func (i IDIndexer) FromObject(b []byte, o unsafe.Pointer) uint64 {
-	copy(b, unsafe.Slice((*byte)(o), IDLength))
+	copy(b, unsafe.Slice((*byte)(unsafe.Add(o, i.Offset)), IDLength))
 	return IDLength
 }
+
+// Decode returns the ID stored in the key.
+func (i IDIndexer) Decode(key []byte) []any {
+	if len(key) < IDLength {
+		return nil
+	}
+	return []any{ID(key[:IDLength])}
+}
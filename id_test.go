@@ -1,8 +1,10 @@
 package memdb
 
 import (
+	"bytes"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -23,6 +25,72 @@ func TestEntityIDIndexer(t *testing.T) {
 	verify(requireT, indexer, []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}, v, v.ID)
 }
 
+func TestEntityIDIndexerWithOffset(t *testing.T) {
+	t.Parallel()
+
+	requireT := require.New(t)
+	v := &struct {
+		Value uint64
+		ID    ID
+	}{}
+
+	indexer := IDIndexer{Offset: 8}
+
+	v.ID = [16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	verify(requireT, indexer, []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}, v, v.ID)
+}
+
+func TestNewTimeID(t *testing.T) {
+	t.Parallel()
+
+	requireT := require.New(t)
+
+	start := uint64(time.Now().UnixMilli())
+	previous := NewTimeID[ID]()
+	for range 10000 {
+		id := NewTimeID[ID]()
+		requireT.Equal(-1, bytes.Compare(previous[:], id[:]))
+		requireT.Equal(byte(0x70), id[6]&0xf0)
+		requireT.Equal(byte(0x80), id[8]&0xc0)
+		previous = id
+	}
+
+	ms := uint64(previous[0])<<40 | uint64(previous[1])<<32 | uint64(previous[2])<<24 | uint64(previous[3])<<16 |
+		uint64(previous[4])<<8 | uint64(previous[5])
+	requireT.GreaterOrEqual(ms, start)
+}
+
+func TestTimeIDGenerator(t *testing.T) {
+	t.Parallel()
+
+	requireT := require.New(t)
+
+	var g timeIDGenerator
+	ms, counter := g.next(100)
+	requireT.EqualValues(100, ms)
+	requireT.EqualValues(0, counter)
+
+	// Clock going back does not break the order.
+	ms, counter = g.next(99)
+	requireT.EqualValues(100, ms)
+	requireT.EqualValues(1, counter)
+
+	for range 0xffe {
+		g.next(100)
+	}
+	ms, counter = g.next(100)
+	requireT.EqualValues(101, ms)
+	requireT.EqualValues(0, counter)
+
+	ms, counter = g.next(101)
+	requireT.EqualValues(101, ms)
+	requireT.EqualValues(1, counter)
+
+	ms, counter = g.next(200)
+	requireT.EqualValues(200, ms)
+	requireT.EqualValues(0, counter)
+}
+
 func verify(
 	requireT *require.Assertions,
 	indexer ArgSerializerIndexer,
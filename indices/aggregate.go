@@ -0,0 +1,215 @@
+package indices
+
+import (
+	"reflect"
+	"unsafe"
+
+	"github.com/pkg/errors"
+
+	"github.com/outofforest/iradix"
+	"github.com/outofforest/memdb"
+)
+
+// Number is the constraint of the field aggregated by AggregateIndex.
+type Number interface {
+	~int8 | ~int16 | ~int32 | ~int64 | ~uint8 | ~uint16 | ~uint32 | ~uint64
+}
+
+// Aggregate is the aggregate of entities sharing the key of the group index.
+type Aggregate[V Number] struct {
+	// Count is the number of entities in the group.
+	Count uint64
+
+	// Sum is the sum of field values. It overflows the same way as the field type does.
+	Sum V
+
+	// Min is the minimum field value.
+	Min V
+
+	// Max is the maximum field value.
+	Max V
+
+	values *iradix.Node[*aggregateValue[V]]
+}
+
+type aggregateValue[V Number] struct {
+	value V
+	count uint64
+}
+
+// AggregateIndex maintains count, sum, minimum and maximum of the field value for each key of the group index.
+type AggregateIndex[T any, V Number] struct {
+	id      uint64
+	name    string
+	tableID uint64
+	indexer *aggregateIndexer[V]
+}
+
+// NewAggregateIndex creates new index aggregating the field of entities grouped by the keys of the group index.
+func NewAggregateIndex[T any, V Number](groupIndex Index[T], ePtr *T, fieldPtr *V) *AggregateIndex[T, V] {
+	offset, fieldName := fieldOffset(ePtr, fieldPtr)
+	index := newAggregateIndex[T, V](groupIndex, "aggregate("+groupIndex.Name()+","+fieldName+")")
+	index.indexer.offset = offset
+	index.indexer.hasValue = true
+	return index
+}
+
+// NewCountIndex creates new index counting entities grouped by the keys of the group index.
+func NewCountIndex[T any](groupIndex Index[T]) *AggregateIndex[T, uint64] {
+	return newAggregateIndex[T, uint64](groupIndex, "count("+groupIndex.Name()+")")
+}
+
+func newAggregateIndex[T any, V Number](groupIndex Index[T], name string) *AggregateIndex[T, V] {
+	var _ Index[T] = (*AggregateIndex[T, V])(nil)
+
+	groupIndexer := groupIndex.Schema().Indexer
+	if _, ok := groupIndexer.(memdb.MultiKeyIndexer); ok {
+		panic(errors.Errorf("group index %q produces many keys", groupIndex.Name()))
+	}
+
+	var encode func(b []byte, v V)
+	switch reflect.TypeFor[V]().Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		encode = func(b []byte, v V) { PutInt(b, int64(v)) }
+	default:
+		encode = func(b []byte, v V) { PutUint(b, uint64(v)) }
+	}
+
+	index := &AggregateIndex[T, V]{
+		name:    name,
+		tableID: memdb.TableID(reflect.TypeFor[T]()),
+		indexer: &aggregateIndexer[V]{
+			subIndexer: groupIndexer,
+			args:       groupIndexer.Args(),
+			encode:     encode,
+		},
+	}
+	index.id = memdb.IndexID(index.name)
+	return index
+}
+
+// ID returns ID of the index.
+func (i *AggregateIndex[T, V]) ID() uint64 {
+	return i.id
+}
+
+// Name returns name of the index.
+func (i *AggregateIndex[T, V]) Name() string {
+	return i.name
+}
+
+// Schema returns memdb index schema.
+func (i *AggregateIndex[T, V]) Schema() *memdb.IndexSchema {
+	return &memdb.IndexSchema{
+		Unique:  true,
+		Indexer: i.indexer,
+	}
+}
+
+// Type returns type of entity index is created for.
+func (i *AggregateIndex[T, V]) Type() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+// Get returns the aggregate of the group identified by the arguments of the group index.
+// Zero aggregate is returned if group is empty.
+func (i *AggregateIndex[T, V]) Get(txn *memdb.Txn, args ...any) (Aggregate[V], error) {
+	agg, err := txn.Aggregate(i.tableID, i.id, args...)
+	if err != nil || agg == nil {
+		return Aggregate[V]{}, err
+	}
+	return *(*Aggregate[V])(agg), nil
+}
+
+func (i *AggregateIndex[T, V]) dummyTDefiner(t T) {
+	panic("it should never be called")
+}
+
+var _ memdb.AggregateIndexer = &aggregateIndexer[uint64]{}
+
+type aggregateIndexer[V Number] struct {
+	subIndexer memdb.Indexer
+	args       []memdb.ArgSerializer
+	offset     uintptr
+	hasValue   bool
+	encode     func(b []byte, v V)
+}
+
+func (i *aggregateIndexer[V]) Args() []memdb.ArgSerializer {
+	return i.args
+}
+
+func (i *aggregateIndexer[V]) SizeFromObject(o unsafe.Pointer) uint64 {
+	return i.subIndexer.SizeFromObject(o)
+}
+
+func (i *aggregateIndexer[V]) FromObject(b []byte, o unsafe.Pointer) uint64 {
+	return i.subIndexer.FromObject(b, o)
+}
+
+func (i *aggregateIndexer[V]) Add(agg, o unsafe.Pointer) unsafe.Pointer {
+	a := &Aggregate[V]{}
+	if agg != nil {
+		*a = *(*Aggregate[V])(agg)
+	}
+	a.Count++
+	if i.hasValue {
+		v := valueByOffset[V](o, i.offset)
+		a.Sum += v
+		i.update(a, v, true)
+	}
+	return unsafe.Pointer(a)
+}
+
+func (i *aggregateIndexer[V]) Remove(agg, o unsafe.Pointer) unsafe.Pointer {
+	if agg == nil || (*Aggregate[V])(agg).Count <= 1 {
+		return nil
+	}
+
+	a := &Aggregate[V]{}
+	*a = *(*Aggregate[V])(agg)
+	a.Count--
+	if i.hasValue {
+		v := valueByOffset[V](o, i.offset)
+		a.Sum -= v
+		i.update(a, v, false)
+	}
+	return unsafe.Pointer(a)
+}
+
+// update adds or removes the value from the set of values and recomputes minimum and maximum.
+func (i *aggregateIndexer[V]) update(a *Aggregate[V], v V, add bool) {
+	var key [8]byte
+	i.encode(key[:], v)
+
+	root := a.values
+	if root == nil {
+		root = iradix.New[*aggregateValue[V]]()
+	}
+	txn := iradix.NewTxn(root)
+
+	var count uint64
+	if e := txn.Get(key[:]); e != nil {
+		count = e.count
+	}
+	if add {
+		count++
+	} else {
+		count--
+	}
+	if count == 0 {
+		txn.Delete(key[:])
+	} else {
+		txn.Insert(key[:], &aggregateValue[V]{value: v, count: count})
+	}
+	a.values = txn.Commit()
+
+	it := a.values.Iterator()
+	a.Min = it.Next().value
+
+	// All the keys are shorter than the one used to seek, so iterator is moved past the last value.
+	it = a.values.Iterator()
+	it.SeekLowerBound([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+	it.Back(1)
+	a.Max = it.Next().value
+}
@@ -0,0 +1,168 @@
+package indices
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/outofforest/memdb"
+)
+
+func TestAggregateIndexName(t *testing.T) {
+	t.Parallel()
+
+	requireT := require.New(t)
+	var v o
+
+	groupIndex := NewFieldIndex(&v, &v.Value4)
+
+	index := NewAggregateIndex(groupIndex, &v, &v.Value3.ValueInt32)
+	requireT.Equal("aggregate(Value4,Value3.ValueInt32)", index.Name())
+	requireT.Equal(reflect.TypeFor[o](), index.Type())
+	requireT.True(index.Schema().Unique)
+	requireT.Len(index.Schema().Indexer.Args(), 1)
+
+	requireT.Equal("count(Value4)", NewCountIndex(groupIndex).Name())
+}
+
+func TestAggregateIndexMultiKeyGroup(t *testing.T) {
+	t.Parallel()
+
+	requireT := require.New(t)
+
+	requireT.Panics(func() {
+		NewCountIndex(NewFuncIndex(Keys(func(v *o) []uint64 {
+			return []uint64{v.Value1}
+		})))
+	})
+}
+
+func TestEntityWithAggregateIndex(t *testing.T) {
+	requireT := require.New(t)
+
+	var v o
+	groupIndex := NewMultiIndex(
+		NewFieldIndex(&v, &v.Value4),
+		NewFieldIndex(&v, &v.Value1),
+	)
+	index := NewAggregateIndex(groupIndex, &v, &v.Value3.ValueInt32)
+
+	db, err := memdb.NewMemDB(memdb.Config{
+		Entities: []reflect.Type{reflect.TypeFor[o]()},
+		Indices:  []memdb.Index{index},
+	})
+	requireT.NoError(err)
+
+	insert := func(txn *memdb.Txn, id byte, tenant string, status uint64, value int32) {
+		e := &o{
+			ID:     memdb.ID{id},
+			Value1: status,
+			Value3: subO2{ValueInt32: value},
+			Value4: tenant,
+		}
+		_, err := txn.Insert(oTableID, unsafe.Pointer(e))
+		requireT.NoError(err)
+	}
+
+	txn := db.Txn(true)
+	insert(txn, 1, "a", 1, 10)
+	insert(txn, 2, "a", 1, -5)
+	insert(txn, 3, "a", 1, 10)
+	insert(txn, 4, "a", 2, 7)
+	insert(txn, 5, "b", 1, 3)
+	requireT.NoError(txn.Commit())
+
+	snapshot := db.Txn(false)
+
+	agg, err := index.Get(snapshot, "a", uint64(1))
+	requireT.NoError(err)
+	requireT.Equal(uint64(3), agg.Count)
+	requireT.Equal(int32(15), agg.Sum)
+	requireT.Equal(int32(-5), agg.Min)
+	requireT.Equal(int32(10), agg.Max)
+
+	agg, err = index.Get(snapshot, "a", uint64(3))
+	requireT.NoError(err)
+	requireT.Zero(agg.Count)
+
+	// Groups of the tenant.
+	it, err := snapshot.Iterator(oTableID, index.ID(), "a")
+	requireT.NoError(err)
+	var counts []uint64
+	for agg := it.Next(); agg != nil; agg = it.Next() {
+		counts = append(counts, (*Aggregate[int32])(agg).Count)
+	}
+	requireT.Equal([]uint64{3, 1}, counts)
+
+	txn = db.Txn(true)
+	// Moves entity to another group.
+	insert(txn, 2, "a", 2, -5)
+	_, err = txn.Delete(oTableID, unsafe.Pointer(&o{ID: memdb.ID{1}}))
+	requireT.NoError(err)
+	_, err = txn.Delete(oTableID, unsafe.Pointer(&o{ID: memdb.ID{5}}))
+	requireT.NoError(err)
+	requireT.NoError(txn.Commit())
+
+	txn = db.Txn(false)
+
+	agg, err = index.Get(txn, "a", uint64(1))
+	requireT.NoError(err)
+	requireT.Equal(Aggregate[int32]{Count: 1, Sum: 10, Min: 10, Max: 10}, withoutValues(agg))
+
+	agg, err = index.Get(txn, "a", uint64(2))
+	requireT.NoError(err)
+	requireT.Equal(Aggregate[int32]{Count: 2, Sum: 2, Min: -5, Max: 7}, withoutValues(agg))
+
+	agg, err = index.Get(txn, "b", uint64(1))
+	requireT.NoError(err)
+	requireT.Zero(agg.Count)
+
+	// Snapshot is not affected.
+	agg, err = index.Get(snapshot, "a", uint64(1))
+	requireT.NoError(err)
+	requireT.Equal(Aggregate[int32]{Count: 3, Sum: 15, Min: -5, Max: 10}, withoutValues(agg))
+
+	agg, err = index.Get(snapshot, "b", uint64(1))
+	requireT.NoError(err)
+	requireT.Equal(uint64(1), agg.Count)
+
+	_, err = index.Get(txn, "a")
+	requireT.Error(err)
+}
+
+func TestAddCountIndex(t *testing.T) {
+	requireT := require.New(t)
+
+	var v o
+	index := NewCountIndex(NewFieldIndex(&v, &v.Value1))
+
+	db, err := memdb.NewMemDB(memdb.Config{
+		Entities: []reflect.Type{reflect.TypeFor[o]()},
+	})
+	requireT.NoError(err)
+
+	txn := db.Txn(true)
+	for i, value := range []uint64{1, 1, 2} {
+		_, err := txn.Insert(oTableID, unsafe.Pointer(&o{ID: memdb.ID{byte(i + 1)}, Value1: value}))
+		requireT.NoError(err)
+	}
+	requireT.NoError(txn.Commit())
+
+	requireT.NoError(db.AddIndex(index))
+
+	txn = db.Txn(false)
+	agg, err := index.Get(txn, uint64(1))
+	requireT.NoError(err)
+	requireT.Equal(Aggregate[uint64]{Count: 2}, agg)
+
+	agg, err = index.Get(txn, uint64(2))
+	requireT.NoError(err)
+	requireT.Equal(Aggregate[uint64]{Count: 1}, agg)
+}
+
+func withoutValues[V Number](agg Aggregate[V]) Aggregate[V] {
+	agg.values = nil
+	return agg
+}
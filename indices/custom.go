@@ -0,0 +1,55 @@
+package indices
+
+import (
+	"reflect"
+
+	"github.com/outofforest/memdb"
+)
+
+// CustomIndex is an index using provided indexer.
+type CustomIndex[T any] struct {
+	id      uint64
+	name    string
+	indexer memdb.Indexer
+	unique  bool
+}
+
+// NewCustomIndex creates new index with the given name using provided indexer.
+func NewCustomIndex[T any](name string, indexer memdb.Indexer, unique bool) *CustomIndex[T] {
+	var _ Index[T] = (*CustomIndex[T])(nil)
+
+	index := &CustomIndex[T]{
+		name:    name,
+		indexer: indexer,
+		unique:  unique,
+	}
+	index.id = memdb.IndexID(index.name)
+	return index
+}
+
+// ID returns ID of the index.
+func (i *CustomIndex[T]) ID() uint64 {
+	return i.id
+}
+
+// Name returns name of the index.
+func (i *CustomIndex[T]) Name() string {
+	return i.name
+}
+
+// Schema returns memdb index schema.
+func (i *CustomIndex[T]) Schema() *memdb.IndexSchema {
+	return &memdb.IndexSchema{
+		Unique:  i.unique,
+		Indexer: i.indexer,
+	}
+}
+
+// Type returns type of entity index is created for.
+func (i *CustomIndex[T]) Type() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+func (i *CustomIndex[T]) dummyTDefiner(t T) {
+	panic("it should never be called")
+}
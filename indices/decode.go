@@ -0,0 +1,87 @@
+package indices
+
+import (
+	"bytes"
+	"encoding/binary"
+	"time"
+	"unsafe"
+
+	"github.com/outofforest/memdb"
+)
+
+// keyDecoder is implemented by indexers able to decode their key components.
+type keyDecoder interface {
+	// decode returns values of key components and the number of bytes they occupy.
+	decode(key []byte) ([]any, uint64, bool)
+}
+
+func decodeKey(d keyDecoder, key []byte) []any {
+	values, _, ok := d.decode(key)
+	if !ok {
+		return nil
+	}
+	return values
+}
+
+func decodeFixed[F any](key []byte, size uint64, get func(b []byte) F) ([]any, uint64, bool) {
+	if uint64(len(key)) < size {
+		return nil, 0, false
+	}
+	return []any{get(key[:size])}, size, true
+}
+
+func decodeString(key []byte) ([]any, uint64, bool) {
+	n := bytes.IndexByte(key, 0x00)
+	if n < 0 {
+		return nil, 0, false
+	}
+	return []any{string(key[:n])}, uint64(n) + 1, true
+}
+
+// decodeNegated decodes key component encoded in reversed order.
+func decodeNegated(d keyDecoder, key []byte) ([]any, uint64, bool) {
+	key = bytes.Clone(key)
+	negate(key)
+	return d.decode(key)
+}
+
+func getBool(b []byte) bool {
+	return b[0] != 0x00
+}
+
+func getTime(b []byte) time.Time {
+	return time.Unix(int64(binary.BigEndian.Uint64(b)^0x8000000000000000)+secondsOffset,
+		int64(binary.BigEndian.Uint32(b[8:]))).UTC()
+}
+
+func getInt[F ~int8 | ~int16 | ~int32 | ~int64](b []byte) F {
+	var v F
+	switch unsafe.Sizeof(v) {
+	case 1:
+		return F(int8(b[0] ^ 0x80))
+	case 2:
+		return F(int16(binary.BigEndian.Uint16(b) ^ 0x8000))
+	case 4:
+		return F(int32(binary.BigEndian.Uint32(b) ^ 0x80000000))
+	default:
+		return F(int64(binary.BigEndian.Uint64(b) ^ 0x8000000000000000))
+	}
+}
+
+func getUint[F ~uint8 | ~uint16 | ~uint32 | ~uint64](b []byte) F {
+	var v F
+	switch unsafe.Sizeof(v) {
+	case 1:
+		return F(b[0])
+	case 2:
+		return F(binary.BigEndian.Uint16(b))
+	case 4:
+		return F(binary.BigEndian.Uint32(b))
+	default:
+		return F(binary.BigEndian.Uint64(b))
+	}
+}
+
+func getID(b []byte) memdb.ID {
+	return memdb.ID(b)
+}
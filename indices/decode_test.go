@@ -0,0 +1,140 @@
+package indices
+
+import (
+	"math"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+
+	"github.com/outofforest/memdb"
+)
+
+func indexKey(index memdb.Index, v *o) []byte {
+	indexer := index.Schema().Indexer
+	b := make([]byte, indexer.SizeFromObject(unsafe.Pointer(v)))
+	return b[:indexer.FromObject(b, unsafe.Pointer(v))]
+}
+
+func decode(index memdb.Index, key []byte) []any {
+	return index.Schema().Indexer.(memdb.DecodingIndexer).Decode(key)
+}
+
+func TestDecodeFieldIndex(t *testing.T) {
+	t.Parallel()
+
+	requireT := require.New(t)
+
+	ts := time.Date(2024, 5, 6, 7, 8, 9, 10, time.UTC)
+	v := o{
+		Value3: subO2{
+			ValueBool:   true,
+			ValueString: abc,
+			ValueTime:   ts,
+			ValueInt8:   math.MinInt8,
+			ValueInt16:  -2,
+			ValueInt32:  3,
+			ValueInt64:  math.MaxInt64,
+			ValueUint8:  5,
+			ValueUint16: 6,
+			ValueUint32: 7,
+			ValueUint64: math.MaxUint64,
+			ValueID:     memdb.ID{0x01, 0x02},
+		},
+	}
+
+	for _, tc := range []struct {
+		index    memdb.Index
+		expected any
+	}{
+		{NewFieldIndex(&v, &v.Value3.ValueBool), true},
+		{NewFieldIndex(&v, &v.Value3.ValueString), abc},
+		{NewFieldIndex(&v, &v.Value3.ValueTime), ts},
+		{NewFieldIndex(&v, &v.Value3.ValueInt8), int8(math.MinInt8)},
+		{NewFieldIndex(&v, &v.Value3.ValueInt16), int16(-2)},
+		{NewFieldIndex(&v, &v.Value3.ValueInt32), int32(3)},
+		{NewFieldIndex(&v, &v.Value3.ValueInt64), int64(math.MaxInt64)},
+		{NewFieldIndex(&v, &v.Value3.ValueUint8), uint8(5)},
+		{NewFieldIndex(&v, &v.Value3.ValueUint16), uint16(6)},
+		{NewFieldIndex(&v, &v.Value3.ValueUint32), uint32(7)},
+		{NewFieldIndex(&v, &v.Value3.ValueUint64), uint64(math.MaxUint64)},
+		{NewFieldIndex(&v, &v.Value3.ValueID), memdb.ID{0x01, 0x02}},
+	} {
+		key := indexKey(tc.index, &v)
+		requireT.Equal([]any{tc.expected}, decode(tc.index, key), tc.index.Name())
+
+		// Trailing bytes are ignored.
+		requireT.Equal([]any{tc.expected}, decode(tc.index, append(key, 0x01, 0x02)), tc.index.Name())
+
+		// Truncated key is malformed.
+		requireT.Nil(decode(tc.index, key[:len(key)-1]), tc.index.Name())
+	}
+}
+
+func TestDecodeMultiIndex(t *testing.T) {
+	t.Parallel()
+
+	requireT := require.New(t)
+
+	v := o{
+		Value1: 1,
+		Value3: subO2{
+			ValueString: abc,
+			ValueInt16:  -2,
+		},
+	}
+
+	index := NewMultiIndex(
+		NewReverseIndex(NewFieldIndex(&v, &v.Value3.ValueString)),
+		NewFieldIndex(&v, &v.Value1),
+		NewReverseIndex(NewFieldIndex(&v, &v.Value3.ValueInt16)),
+	)
+	requireT.Equal([]any{abc, uint64(1), int16(-2)}, decode(index, indexKey(index, &v)))
+
+	ifIndex := NewIfIndex(index, func(o *o) bool { return true })
+	requireT.Equal([]any{abc, uint64(1), int16(-2)}, decode(ifIndex, indexKey(ifIndex, &v)))
+}
+
+func TestDecodeFuncIndex(t *testing.T) {
+	t.Parallel()
+
+	requireT := require.New(t)
+
+	v := o{Value4: def}
+
+	index := NewFuncIndex(
+		Key(func(e *o) *string { return &e.Value4 }),
+		Desc(Key(func(e *o) *uint16 { return lo.ToPtr[uint16](0x0102) })),
+	)
+	requireT.Equal([]any{def, uint16(0x0102)}, decode(index, indexKey(index, &v)))
+
+	multiIndex := NewFuncIndex(
+		Desc(Keys(func(e *o) []string { return []string{abc, def} })),
+		Key(func(e *o) *int8 { return lo.ToPtr[int8](-1) }),
+	)
+	indexer := multiIndex.Schema().Indexer.(memdb.MultiKeyIndexer)
+	keys := indexer.KeysFromObject(unsafe.Pointer(&v))
+	requireT.Len(keys, 2)
+	requireT.Equal([]any{def, int8(-1)}, decode(multiIndex, keys[1]))
+
+	// Key can't be decoded if any component doesn't support decoding.
+	customIndex := NewMultiIndex(NewFieldIndex(&v, &v.Value4), NewCustomIndex[o]("custom", plainIndexer{}, false))
+	requireT.Nil(decode(customIndex, indexKey(customIndex, &v)))
+}
+
+type plainIndexer struct{}
+
+func (i plainIndexer) Args() []memdb.ArgSerializer {
+	return nil
+}
+
+func (i plainIndexer) SizeFromObject(o unsafe.Pointer) uint64 {
+	return 1
+}
+
+func (i plainIndexer) FromObject(b []byte, o unsafe.Pointer) uint64 {
+	b[0] = 0x01
+	return 1
+}
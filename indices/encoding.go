@@ -0,0 +1,186 @@
+package indices
+
+import (
+	"encoding/binary"
+	"reflect"
+	"time"
+	"unsafe"
+
+	"github.com/outofforest/memdb"
+)
+
+// Functions and types defined in this file are used by indexers generated by memdbgen.
+
+// PutBool encodes bool key component and returns its size.
+func PutBool[F ~bool](b []byte, v F) uint64 {
+	if v {
+		b[0] = 0x01
+	} else {
+		b[0] = 0x00
+	}
+	return 1
+}
+
+// PutString encodes string key component and returns its size.
+func PutString[F ~string](b []byte, v F) uint64 {
+	n := copy(b, v)
+	b[n] = 0x00
+	return uint64(n) + 1
+}
+
+// PutTime encodes time key component and returns its size.
+func PutTime(b []byte, v time.Time) uint64 {
+	binary.BigEndian.PutUint64(b, uint64(v.Unix()-secondsOffset)^0x8000000000000000)
+	binary.BigEndian.PutUint32(b[8:], uint32(v.Nanosecond()))
+	return 12
+}
+
+// PutInt encodes signed integer key component and returns its size.
+func PutInt[F ~int8 | ~int16 | ~int32 | ~int64](b []byte, v F) uint64 {
+	switch unsafe.Sizeof(v) {
+	case 1:
+		b[0] = uint8(v) ^ 0x80
+		return 1
+	case 2:
+		binary.BigEndian.PutUint16(b, uint16(v)^0x8000)
+		return 2
+	case 4:
+		binary.BigEndian.PutUint32(b, uint32(v)^0x80000000)
+		return 4
+	default:
+		binary.BigEndian.PutUint64(b, uint64(v)^0x8000000000000000)
+		return 8
+	}
+}
+
+// PutUint encodes unsigned integer key component and returns its size.
+func PutUint[F ~uint8 | ~uint16 | ~uint32 | ~uint64](b []byte, v F) uint64 {
+	switch unsafe.Sizeof(v) {
+	case 1:
+		b[0] = uint8(v)
+		return 1
+	case 2:
+		binary.BigEndian.PutUint16(b, uint16(v))
+		return 2
+	case 4:
+		binary.BigEndian.PutUint32(b, uint32(v))
+		return 4
+	default:
+		binary.BigEndian.PutUint64(b, uint64(v))
+		return 8
+	}
+}
+
+// PutID encodes ID key component and returns its size.
+func PutID[F ~[memdb.IDLength]byte](b []byte, v F) uint64 {
+	copy(b, v[:])
+	return memdb.IDLength
+}
+
+// Negate reverses the order of encoded key component.
+func Negate(b []byte) {
+	negate(b)
+}
+
+// DescArg reverses the order of serialized argument.
+func DescArg(arg memdb.ArgSerializer) memdb.ArgSerializer {
+	return &reverseArgSerializer{subArg: arg}
+}
+
+// argValue converts argument to the type expected by index.
+func argValue[F any](arg any) F {
+	if v, ok := arg.(F); ok {
+		return v
+	}
+	return reflect.ValueOf(arg).Convert(reflect.TypeFor[F]()).Interface().(F)
+}
+
+var _ memdb.ArgSerializer = BoolArg[bool]{}
+
+// BoolArg serializes bool index argument.
+type BoolArg[F ~bool] struct{}
+
+// SizeFromArg returns byte size of the argument.
+func (a BoolArg[F]) SizeFromArg(arg any) uint64 {
+	return 1
+}
+
+// FromArg serializes the argument.
+func (a BoolArg[F]) FromArg(b []byte, arg any) uint64 {
+	return PutBool(b, argValue[F](arg))
+}
+
+var _ memdb.ArgSerializer = StringArg[string]{}
+
+// StringArg serializes string index argument.
+type StringArg[F ~string] struct{}
+
+// SizeFromArg returns byte size of the argument.
+func (a StringArg[F]) SizeFromArg(arg any) uint64 {
+	return uint64(len(argValue[F](arg))) + 1
+}
+
+// FromArg serializes the argument.
+func (a StringArg[F]) FromArg(b []byte, arg any) uint64 {
+	return PutString(b, argValue[F](arg))
+}
+
+var _ memdb.ArgSerializer = TimeArg{}
+
+// TimeArg serializes time index argument.
+type TimeArg struct{}
+
+// SizeFromArg returns byte size of the argument.
+func (a TimeArg) SizeFromArg(arg any) uint64 {
+	return 12
+}
+
+// FromArg serializes the argument.
+func (a TimeArg) FromArg(b []byte, arg any) uint64 {
+	return PutTime(b, argValue[time.Time](arg))
+}
+
+var _ memdb.ArgSerializer = IntArg[int64]{}
+
+// IntArg serializes signed integer index argument.
+type IntArg[F ~int8 | ~int16 | ~int32 | ~int64] struct{}
+
+// SizeFromArg returns byte size of the argument.
+func (a IntArg[F]) SizeFromArg(arg any) uint64 {
+	return uint64(unsafe.Sizeof(F(0)))
+}
+
+// FromArg serializes the argument.
+func (a IntArg[F]) FromArg(b []byte, arg any) uint64 {
+	return PutInt(b, argValue[F](arg))
+}
+
+var _ memdb.ArgSerializer = UintArg[uint64]{}
+
+// UintArg serializes unsigned integer index argument.
+type UintArg[F ~uint8 | ~uint16 | ~uint32 | ~uint64] struct{}
+
+// SizeFromArg returns byte size of the argument.
+func (a UintArg[F]) SizeFromArg(arg any) uint64 {
+	return uint64(unsafe.Sizeof(F(0)))
+}
+
+// FromArg serializes the argument.
+func (a UintArg[F]) FromArg(b []byte, arg any) uint64 {
+	return PutUint(b, argValue[F](arg))
+}
+
+var _ memdb.ArgSerializer = IDArg[memdb.ID]{}
+
+// IDArg serializes ID index argument.
+type IDArg[F ~[memdb.IDLength]byte] struct{}
+
+// SizeFromArg returns byte size of the argument.
+func (a IDArg[F]) SizeFromArg(arg any) uint64 {
+	return memdb.IDLength
+}
+
+// FromArg serializes the argument.
+func (a IDArg[F]) FromArg(b []byte, arg any) uint64 {
+	return PutID(b, argValue[F](arg))
+}
@@ -0,0 +1,56 @@
+package indices
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/outofforest/memdb"
+)
+
+type namedString string
+
+func TestTypedArgs(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		Arg      memdb.ArgSerializer
+		Value    any
+		Expected []byte
+	}{
+		{Arg: BoolArg[bool]{}, Value: true, Expected: []byte{0x01}},
+		{Arg: BoolArg[bool]{}, Value: false, Expected: []byte{0x00}},
+		{Arg: StringArg[string]{}, Value: "AB", Expected: []byte{0x41, 0x42, 0x00}},
+		{Arg: StringArg[namedString]{}, Value: "AB", Expected: []byte{0x41, 0x42, 0x00}},
+		{Arg: StringArg[namedString]{}, Value: namedString("AB"), Expected: []byte{0x41, 0x42, 0x00}},
+		{Arg: IntArg[int8]{}, Value: int8(-1), Expected: []byte{0x7f}},
+		{Arg: IntArg[int16]{}, Value: int16(1), Expected: []byte{0x80, 0x01}},
+		{Arg: IntArg[int32]{}, Value: 1, Expected: []byte{0x80, 0x00, 0x00, 0x01}},
+		{Arg: IntArg[int64]{}, Value: int64(-1), Expected: []byte{0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{Arg: UintArg[uint8]{}, Value: uint8(1), Expected: []byte{0x01}},
+		{Arg: UintArg[uint16]{}, Value: 1, Expected: []byte{0x00, 0x01}},
+		{Arg: UintArg[uint32]{}, Value: uint32(1), Expected: []byte{0x00, 0x00, 0x00, 0x01}},
+		{Arg: UintArg[uint64]{}, Value: uint64(1), Expected: []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01}},
+		{Arg: IDArg[memdb.ID]{}, Value: memdb.ID{0x01}, Expected: append([]byte{0x01}, make([]byte, 15)...)},
+		{Arg: DescArg(UintArg[uint16]{}), Value: uint16(1), Expected: []byte{0xff, 0xfe}},
+		{
+			Arg:      TimeArg{},
+			Value:    time.Time{}.Add(time.Second + time.Nanosecond),
+			Expected: []byte{0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01},
+		},
+	} {
+		t.Run(reflect.TypeOf(tc.Arg).String(), func(t *testing.T) {
+			requireT := require.New(t)
+
+			requireT.EqualValues(len(tc.Expected), tc.Arg.SizeFromArg(tc.Value))
+			b := make([]byte, len(tc.Expected))
+			for i := range b {
+				b[i] = 0xaa
+			}
+			requireT.EqualValues(len(tc.Expected), tc.Arg.FromArg(b, tc.Value))
+			requireT.Equal(tc.Expected, b)
+		})
+	}
+}
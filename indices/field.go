@@ -1,7 +1,6 @@
 package indices
 
 import (
-	"encoding/binary"
 	"reflect"
 	"time"
 	"unsafe"
@@ -14,37 +13,25 @@ import (
 // FieldIndex defines index indexing entities by struct field.
 type FieldIndex[T any] struct {
 	id      uint64
+	name    string
+	offset  uintptr
+	size    uintptr
 	indexer memdb.Indexer
 }
 
 // NewFieldIndex defines new field index.
 func NewFieldIndex[T any, F fieldConstraint](ePtr *T, fieldPtr *F) *FieldIndex[T] {
 	var _ Index[T] = (*FieldIndex[T])(nil)
+	var _ memdb.Nullifier = (*FieldIndex[T])(nil)
 
-	eType := reflect.TypeFor[T]()
-	if eType.Kind() != reflect.Struct {
-		panic(errors.New("*ePtr is not a struct"))
-	}
-
-	fieldType := reflect.TypeFor[F]()
-
-	eStart := reflect.ValueOf(ePtr).Pointer()
-	eSize := eType.Size()
-	fieldStart := reflect.ValueOf(fieldPtr).Pointer()
-	if fieldStart < eStart || fieldStart >= eStart+eSize {
-		panic(errors.Errorf("field does not belong to entity"))
-	}
-
-	offset := fieldStart - eStart
-	foundFieldType := findField(eType, offset)
-	if foundFieldType != fieldType {
-		panic(errors.Errorf("unexpected field type %s, expected %s", foundFieldType, fieldType))
-	}
-
+	offset, name := fieldOffset(ePtr, fieldPtr)
 	index := &FieldIndex[T]{
-		indexer: indexerForType(fieldType, offset),
+		name:    name,
+		offset:  offset,
+		size:    reflect.TypeFor[F]().Size(),
+		indexer: indexerForType(reflect.TypeFor[F](), offset),
 	}
-	index.id = uint64(uintptr(unsafe.Pointer(index)))
+	index.id = memdb.IndexID(index.name)
 	return index
 }
 
@@ -53,6 +40,11 @@ func (i *FieldIndex[T]) ID() uint64 {
 	return i.id
 }
 
+// Name returns name of the index.
+func (i *FieldIndex[T]) Name() string {
+	return i.name
+}
+
 // Schema returns memdb index schema.
 func (i *FieldIndex[T]) Schema() *memdb.IndexSchema {
 	return &memdb.IndexSchema{
@@ -60,6 +52,11 @@ func (i *FieldIndex[T]) Schema() *memdb.IndexSchema {
 	}
 }
 
+// SetNull sets zero value of the field.
+func (i *FieldIndex[T]) SetNull(o unsafe.Pointer) {
+	clear(unsafe.Slice((*byte)(unsafe.Add(o, i.offset)), i.size))
+}
+
 // Type returns type of entity index is created for.
 func (i *FieldIndex[T]) Type() reflect.Type {
 	return reflect.TypeFor[T]()
@@ -69,8 +66,33 @@ func (i *FieldIndex[T]) dummyTDefiner(t T) {
 	panic("it should never be called")
 }
 
-func findField(t reflect.Type, offset uintptr) reflect.Type {
+// fieldOffset returns the offset and the path of the field within the entity.
+func fieldOffset[T, F any](ePtr *T, fieldPtr *F) (uintptr, string) {
+	eType := reflect.TypeFor[T]()
+	if eType.Kind() != reflect.Struct {
+		panic(errors.New("*ePtr is not a struct"))
+	}
+
+	fieldType := reflect.TypeFor[F]()
+
+	eStart := reflect.ValueOf(ePtr).Pointer()
+	eSize := eType.Size()
+	fieldStart := reflect.ValueOf(fieldPtr).Pointer()
+	if fieldStart < eStart || fieldStart >= eStart+eSize {
+		panic(errors.Errorf("field does not belong to entity"))
+	}
+
+	offset := fieldStart - eStart
+	foundFieldType, name := findField(eType, offset)
+	if foundFieldType != fieldType {
+		panic(errors.Errorf("unexpected field type %s, expected %s", foundFieldType, fieldType))
+	}
+	return offset, name
+}
+
+func findField(t reflect.Type, offset uintptr) (reflect.Type, string) {
 	var field reflect.StructField
+	var path string
 	for {
 		for i := range t.NumField() {
 			f := t.Field(i)
@@ -80,25 +102,29 @@ func findField(t reflect.Type, offset uintptr) reflect.Type {
 			field = f
 		}
 
+		if path != "" {
+			path += "."
+		}
+		path += field.Name
+
 		if field.Type.Kind() != reflect.Struct || field.Type.ConvertibleTo(timeType) {
-			return field.Type
+			return field.Type, path
 		}
 		offset -= field.Offset
 		t = field.Type
 	}
 }
 
-func valueByOffset[T any](o unsafe.Pointer, offset uintptr) T {
-	return *(*T)(unsafe.Pointer(uintptr(o) + offset))
+func indexFields[T any](offset uintptr) []memdb.IndexField {
+	return []memdb.IndexField{{Offset: offset, Type: reflect.TypeFor[T]()}}
 }
 
-func boolToBytes(v bool, b []byte) {
-	if v {
-		b[0] = 0x01
-	}
+func valueByOffset[T any](o unsafe.Pointer, offset uintptr) T {
+	return *(*T)(unsafe.Pointer(uintptr(o) + offset))
 }
 
-var _ memdb.Indexer = &boolIndexer{}
+var _ memdb.FieldIndexer = &boolIndexer{}
+var _ memdb.DecodingIndexer = &boolIndexer{}
 var _ memdb.ArgSerializer = &boolIndexer{}
 
 type boolIndexer struct {
@@ -110,6 +136,10 @@ func (i *boolIndexer) Args() []memdb.ArgSerializer {
 	return i.args
 }
 
+func (i *boolIndexer) Fields() []memdb.IndexField {
+	return indexFields[bool](i.offset)
+}
+
 func (i *boolIndexer) SizeFromObject(o unsafe.Pointer) uint64 {
 	return 1
 }
@@ -119,20 +149,26 @@ func (i *boolIndexer) SizeFromArg(arg any) uint64 {
 }
 
 func (i *boolIndexer) FromArg(b []byte, arg any) uint64 {
-	boolToBytes(reflect.ValueOf(arg).Bool(), b)
-	return 1
+	if v, ok := arg.(bool); ok {
+		return PutBool(b, v)
+	}
+	return PutBool(b, reflect.ValueOf(arg).Bool())
 }
 
 func (i *boolIndexer) FromObject(b []byte, o unsafe.Pointer) uint64 {
-	boolToBytes(valueByOffset[bool](o, i.offset), b)
-	return 1
+	return PutBool(b, valueByOffset[bool](o, i.offset))
 }
 
-func stringToBytes(s string, b []byte) uint64 {
-	return uint64(copy(b, s)) + 1
+func (i *boolIndexer) Decode(key []byte) []any {
+	return decodeKey(i, key)
 }
 
-var _ memdb.Indexer = &stringIndexer{}
+func (i *boolIndexer) decode(key []byte) ([]any, uint64, bool) {
+	return decodeFixed(key, 1, getBool)
+}
+
+var _ memdb.FieldIndexer = &stringIndexer{}
+var _ memdb.DecodingIndexer = &stringIndexer{}
 var _ memdb.ArgSerializer = &stringIndexer{}
 
 type stringIndexer struct {
@@ -144,20 +180,38 @@ func (i *stringIndexer) Args() []memdb.ArgSerializer {
 	return i.args
 }
 
+func (i *stringIndexer) Fields() []memdb.IndexField {
+	return indexFields[string](i.offset)
+}
+
 func (i *stringIndexer) SizeFromObject(o unsafe.Pointer) uint64 {
 	return uint64(len(valueByOffset[string](o, i.offset))) + 1
 }
 
 func (i *stringIndexer) SizeFromArg(arg any) uint64 {
+	if v, ok := arg.(string); ok {
+		return uint64(len(v)) + 1
+	}
 	return uint64(len(reflect.ValueOf(arg).String())) + 1
 }
 
 func (i *stringIndexer) FromArg(b []byte, arg any) uint64 {
-	return stringToBytes(reflect.ValueOf(arg).String(), b)
+	if v, ok := arg.(string); ok {
+		return PutString(b, v)
+	}
+	return PutString(b, reflect.ValueOf(arg).String())
 }
 
 func (i *stringIndexer) FromObject(b []byte, o unsafe.Pointer) uint64 {
-	return stringToBytes(valueByOffset[string](o, i.offset), b)
+	return PutString(b, valueByOffset[string](o, i.offset))
+}
+
+func (i *stringIndexer) Decode(key []byte) []any {
+	return decodeKey(i, key)
+}
+
+func (i *stringIndexer) decode(key []byte) ([]any, uint64, bool) {
+	return decodeString(key)
 }
 
 var (
@@ -165,12 +219,8 @@ var (
 	timeType      = reflect.TypeFor[time.Time]()
 )
 
-func timeToBytes(t time.Time, b []byte) {
-	binary.BigEndian.PutUint64(b, uint64(t.Unix()-secondsOffset)^0x8000000000000000)
-	binary.BigEndian.PutUint32(b[8:], uint32(t.Nanosecond()))
-}
-
-var _ memdb.Indexer = &timeIndexer{}
+var _ memdb.FieldIndexer = &timeIndexer{}
+var _ memdb.DecodingIndexer = &timeIndexer{}
 var _ memdb.ArgSerializer = &timeIndexer{}
 
 type timeIndexer struct {
@@ -182,6 +232,10 @@ func (i *timeIndexer) Args() []memdb.ArgSerializer {
 	return i.args
 }
 
+func (i *timeIndexer) Fields() []memdb.IndexField {
+	return indexFields[time.Time](i.offset)
+}
+
 func (i *timeIndexer) SizeFromObject(o unsafe.Pointer) uint64 {
 	return 12
 }
@@ -191,20 +245,26 @@ func (i *timeIndexer) SizeFromArg(arg any) uint64 {
 }
 
 func (i *timeIndexer) FromArg(b []byte, arg any) uint64 {
-	timeToBytes(reflect.ValueOf(arg).Convert(timeType).Interface().(time.Time), b)
-	return 12
+	if v, ok := arg.(time.Time); ok {
+		return PutTime(b, v)
+	}
+	return PutTime(b, reflect.ValueOf(arg).Convert(timeType).Interface().(time.Time))
 }
 
 func (i *timeIndexer) FromObject(b []byte, o unsafe.Pointer) uint64 {
-	timeToBytes(valueByOffset[time.Time](o, i.offset), b)
-	return 12
+	return PutTime(b, valueByOffset[time.Time](o, i.offset))
 }
 
-func int8ToBytes(i int8, b []byte) {
-	b[0] = uint8(i) ^ 0x80
+func (i *timeIndexer) Decode(key []byte) []any {
+	return decodeKey(i, key)
 }
 
-var _ memdb.Indexer = &int8Indexer{}
+func (i *timeIndexer) decode(key []byte) ([]any, uint64, bool) {
+	return decodeFixed(key, 12, getTime)
+}
+
+var _ memdb.FieldIndexer = &int8Indexer{}
+var _ memdb.DecodingIndexer = &int8Indexer{}
 var _ memdb.ArgSerializer = &int8Indexer{}
 
 type int8Indexer struct {
@@ -216,6 +276,10 @@ func (i *int8Indexer) Args() []memdb.ArgSerializer {
 	return i.args
 }
 
+func (i *int8Indexer) Fields() []memdb.IndexField {
+	return indexFields[int8](i.offset)
+}
+
 func (i *int8Indexer) SizeFromObject(o unsafe.Pointer) uint64 {
 	return 1
 }
@@ -225,20 +289,26 @@ func (i *int8Indexer) SizeFromArg(arg any) uint64 {
 }
 
 func (i *int8Indexer) FromArg(b []byte, arg any) uint64 {
-	int8ToBytes(int8(reflect.ValueOf(arg).Int()), b)
-	return 1
+	if v, ok := arg.(int8); ok {
+		return PutInt(b, v)
+	}
+	return PutInt(b, int8(reflect.ValueOf(arg).Int()))
 }
 
 func (i *int8Indexer) FromObject(b []byte, o unsafe.Pointer) uint64 {
-	int8ToBytes(valueByOffset[int8](o, i.offset), b)
-	return 1
+	return PutInt(b, valueByOffset[int8](o, i.offset))
 }
 
-func int16ToBytes(i int16, b []byte) {
-	binary.BigEndian.PutUint16(b, uint16(i)^0x8000)
+func (i *int8Indexer) Decode(key []byte) []any {
+	return decodeKey(i, key)
 }
 
-var _ memdb.Indexer = &int16Indexer{}
+func (i *int8Indexer) decode(key []byte) ([]any, uint64, bool) {
+	return decodeFixed(key, 1, getInt[int8])
+}
+
+var _ memdb.FieldIndexer = &int16Indexer{}
+var _ memdb.DecodingIndexer = &int16Indexer{}
 var _ memdb.Indexer = &int16Indexer{}
 
 type int16Indexer struct {
@@ -250,6 +320,10 @@ func (i *int16Indexer) Args() []memdb.ArgSerializer {
 	return i.args
 }
 
+func (i *int16Indexer) Fields() []memdb.IndexField {
+	return indexFields[int16](i.offset)
+}
+
 func (i *int16Indexer) SizeFromObject(o unsafe.Pointer) uint64 {
 	return 2
 }
@@ -259,20 +333,26 @@ func (i *int16Indexer) SizeFromArg(arg any) uint64 {
 }
 
 func (i *int16Indexer) FromArg(b []byte, arg any) uint64 {
-	int16ToBytes(int16(reflect.ValueOf(arg).Int()), b)
-	return 2
+	if v, ok := arg.(int16); ok {
+		return PutInt(b, v)
+	}
+	return PutInt(b, int16(reflect.ValueOf(arg).Int()))
 }
 
 func (i *int16Indexer) FromObject(b []byte, o unsafe.Pointer) uint64 {
-	int16ToBytes(valueByOffset[int16](o, i.offset), b)
-	return 2
+	return PutInt(b, valueByOffset[int16](o, i.offset))
 }
 
-func int32ToBytes(i int32, b []byte) {
-	binary.BigEndian.PutUint32(b, uint32(i)^0x80000000)
+func (i *int16Indexer) Decode(key []byte) []any {
+	return decodeKey(i, key)
 }
 
-var _ memdb.Indexer = &int32Indexer{}
+func (i *int16Indexer) decode(key []byte) ([]any, uint64, bool) {
+	return decodeFixed(key, 2, getInt[int16])
+}
+
+var _ memdb.FieldIndexer = &int32Indexer{}
+var _ memdb.DecodingIndexer = &int32Indexer{}
 var _ memdb.ArgSerializer = &int32Indexer{}
 
 type int32Indexer struct {
@@ -284,6 +364,10 @@ func (i *int32Indexer) Args() []memdb.ArgSerializer {
 	return i.args
 }
 
+func (i *int32Indexer) Fields() []memdb.IndexField {
+	return indexFields[int32](i.offset)
+}
+
 func (i *int32Indexer) SizeFromObject(o unsafe.Pointer) uint64 {
 	return 4
 }
@@ -293,20 +377,26 @@ func (i *int32Indexer) SizeFromArg(arg any) uint64 {
 }
 
 func (i *int32Indexer) FromArg(b []byte, arg any) uint64 {
-	int32ToBytes(int32(reflect.ValueOf(arg).Int()), b)
-	return 4
+	if v, ok := arg.(int32); ok {
+		return PutInt(b, v)
+	}
+	return PutInt(b, int32(reflect.ValueOf(arg).Int()))
 }
 
 func (i *int32Indexer) FromObject(b []byte, o unsafe.Pointer) uint64 {
-	int32ToBytes(valueByOffset[int32](o, i.offset), b)
-	return 4
+	return PutInt(b, valueByOffset[int32](o, i.offset))
+}
+
+func (i *int32Indexer) Decode(key []byte) []any {
+	return decodeKey(i, key)
 }
 
-func int64ToBytes(i int64, b []byte) {
-	binary.BigEndian.PutUint64(b, uint64(i)^0x8000000000000000)
+func (i *int32Indexer) decode(key []byte) ([]any, uint64, bool) {
+	return decodeFixed(key, 4, getInt[int32])
 }
 
-var _ memdb.Indexer = &int64Indexer{}
+var _ memdb.FieldIndexer = &int64Indexer{}
+var _ memdb.DecodingIndexer = &int64Indexer{}
 var _ memdb.ArgSerializer = &int64Indexer{}
 
 type int64Indexer struct {
@@ -318,6 +408,10 @@ func (i *int64Indexer) Args() []memdb.ArgSerializer {
 	return i.args
 }
 
+func (i *int64Indexer) Fields() []memdb.IndexField {
+	return indexFields[int64](i.offset)
+}
+
 func (i *int64Indexer) SizeFromObject(o unsafe.Pointer) uint64 {
 	return 8
 }
@@ -327,20 +421,26 @@ func (i *int64Indexer) SizeFromArg(arg any) uint64 {
 }
 
 func (i *int64Indexer) FromArg(b []byte, arg any) uint64 {
-	int64ToBytes(reflect.ValueOf(arg).Int(), b)
-	return 8
+	if v, ok := arg.(int64); ok {
+		return PutInt(b, v)
+	}
+	return PutInt(b, reflect.ValueOf(arg).Int())
 }
 
 func (i *int64Indexer) FromObject(b []byte, o unsafe.Pointer) uint64 {
-	int64ToBytes(valueByOffset[int64](o, i.offset), b)
-	return 8
+	return PutInt(b, valueByOffset[int64](o, i.offset))
+}
+
+func (i *int64Indexer) Decode(key []byte) []any {
+	return decodeKey(i, key)
 }
 
-func uint8ToBytes(i uint8, b []byte) {
-	b[0] = i
+func (i *int64Indexer) decode(key []byte) ([]any, uint64, bool) {
+	return decodeFixed(key, 8, getInt[int64])
 }
 
-var _ memdb.Indexer = &uint8Indexer{}
+var _ memdb.FieldIndexer = &uint8Indexer{}
+var _ memdb.DecodingIndexer = &uint8Indexer{}
 var _ memdb.ArgSerializer = &uint8Indexer{}
 
 type uint8Indexer struct {
@@ -352,6 +452,10 @@ func (i *uint8Indexer) Args() []memdb.ArgSerializer {
 	return i.args
 }
 
+func (i *uint8Indexer) Fields() []memdb.IndexField {
+	return indexFields[uint8](i.offset)
+}
+
 func (i *uint8Indexer) SizeFromObject(o unsafe.Pointer) uint64 {
 	return 1
 }
@@ -361,20 +465,26 @@ func (i *uint8Indexer) SizeFromArg(arg any) uint64 {
 }
 
 func (i *uint8Indexer) FromArg(b []byte, arg any) uint64 {
-	uint8ToBytes(uint8(reflect.ValueOf(arg).Uint()), b)
-	return 1
+	if v, ok := arg.(uint8); ok {
+		return PutUint(b, v)
+	}
+	return PutUint(b, uint8(reflect.ValueOf(arg).Uint()))
 }
 
 func (i *uint8Indexer) FromObject(b []byte, o unsafe.Pointer) uint64 {
-	uint8ToBytes(valueByOffset[uint8](o, i.offset), b)
-	return 1
+	return PutUint(b, valueByOffset[uint8](o, i.offset))
+}
+
+func (i *uint8Indexer) Decode(key []byte) []any {
+	return decodeKey(i, key)
 }
 
-func uint16ToBytes(i uint16, b []byte) {
-	binary.BigEndian.PutUint16(b, i)
+func (i *uint8Indexer) decode(key []byte) ([]any, uint64, bool) {
+	return decodeFixed(key, 1, getUint[uint8])
 }
 
-var _ memdb.Indexer = &uint16Indexer{}
+var _ memdb.FieldIndexer = &uint16Indexer{}
+var _ memdb.DecodingIndexer = &uint16Indexer{}
 var _ memdb.ArgSerializer = &uint16Indexer{}
 
 type uint16Indexer struct {
@@ -386,6 +496,10 @@ func (i *uint16Indexer) Args() []memdb.ArgSerializer {
 	return i.args
 }
 
+func (i *uint16Indexer) Fields() []memdb.IndexField {
+	return indexFields[uint16](i.offset)
+}
+
 func (i *uint16Indexer) SizeFromObject(o unsafe.Pointer) uint64 {
 	return 2
 }
@@ -395,20 +509,26 @@ func (i *uint16Indexer) SizeFromArg(arg any) uint64 {
 }
 
 func (i *uint16Indexer) FromArg(b []byte, arg any) uint64 {
-	uint16ToBytes(uint16(reflect.ValueOf(arg).Uint()), b)
-	return 2
+	if v, ok := arg.(uint16); ok {
+		return PutUint(b, v)
+	}
+	return PutUint(b, uint16(reflect.ValueOf(arg).Uint()))
 }
 
 func (i *uint16Indexer) FromObject(b []byte, o unsafe.Pointer) uint64 {
-	uint16ToBytes(valueByOffset[uint16](o, i.offset), b)
-	return 2
+	return PutUint(b, valueByOffset[uint16](o, i.offset))
+}
+
+func (i *uint16Indexer) Decode(key []byte) []any {
+	return decodeKey(i, key)
 }
 
-func uint32ToBytes(i uint32, b []byte) {
-	binary.BigEndian.PutUint32(b, i)
+func (i *uint16Indexer) decode(key []byte) ([]any, uint64, bool) {
+	return decodeFixed(key, 2, getUint[uint16])
 }
 
-var _ memdb.Indexer = &uint32Indexer{}
+var _ memdb.FieldIndexer = &uint32Indexer{}
+var _ memdb.DecodingIndexer = &uint32Indexer{}
 var _ memdb.ArgSerializer = &uint32Indexer{}
 
 type uint32Indexer struct {
@@ -420,6 +540,10 @@ func (i *uint32Indexer) Args() []memdb.ArgSerializer {
 	return i.args
 }
 
+func (i *uint32Indexer) Fields() []memdb.IndexField {
+	return indexFields[uint32](i.offset)
+}
+
 func (i *uint32Indexer) SizeFromObject(o unsafe.Pointer) uint64 {
 	return 4
 }
@@ -429,20 +553,26 @@ func (i *uint32Indexer) SizeFromArg(arg any) uint64 {
 }
 
 func (i *uint32Indexer) FromArg(b []byte, arg any) uint64 {
-	uint32ToBytes(uint32(reflect.ValueOf(arg).Uint()), b)
-	return 4
+	if v, ok := arg.(uint32); ok {
+		return PutUint(b, v)
+	}
+	return PutUint(b, uint32(reflect.ValueOf(arg).Uint()))
 }
 
 func (i *uint32Indexer) FromObject(b []byte, o unsafe.Pointer) uint64 {
-	uint32ToBytes(valueByOffset[uint32](o, i.offset), b)
-	return 4
+	return PutUint(b, valueByOffset[uint32](o, i.offset))
 }
 
-func uint64ToBytes(i uint64, b []byte) {
-	binary.BigEndian.PutUint64(b, i)
+func (i *uint32Indexer) Decode(key []byte) []any {
+	return decodeKey(i, key)
 }
 
-var _ memdb.Indexer = &uint64Indexer{}
+func (i *uint32Indexer) decode(key []byte) ([]any, uint64, bool) {
+	return decodeFixed(key, 4, getUint[uint32])
+}
+
+var _ memdb.FieldIndexer = &uint64Indexer{}
+var _ memdb.DecodingIndexer = &uint64Indexer{}
 var _ memdb.ArgSerializer = &uint64Indexer{}
 
 type uint64Indexer struct {
@@ -454,6 +584,10 @@ func (i *uint64Indexer) Args() []memdb.ArgSerializer {
 	return i.args
 }
 
+func (i *uint64Indexer) Fields() []memdb.IndexField {
+	return indexFields[uint64](i.offset)
+}
+
 func (i *uint64Indexer) SizeFromObject(o unsafe.Pointer) uint64 {
 	return 8
 }
@@ -463,23 +597,29 @@ func (i *uint64Indexer) SizeFromArg(arg any) uint64 {
 }
 
 func (i *uint64Indexer) FromArg(b []byte, arg any) uint64 {
-	uint64ToBytes(reflect.ValueOf(arg).Uint(), b)
-	return 8
+	if v, ok := arg.(uint64); ok {
+		return PutUint(b, v)
+	}
+	return PutUint(b, reflect.ValueOf(arg).Uint())
 }
 
 func (i *uint64Indexer) FromObject(b []byte, o unsafe.Pointer) uint64 {
-	uint64ToBytes(valueByOffset[uint64](o, i.offset), b)
-	return 8
+	return PutUint(b, valueByOffset[uint64](o, i.offset))
+}
+
+func (i *uint64Indexer) Decode(key []byte) []any {
+	return decodeKey(i, key)
+}
+
+func (i *uint64Indexer) decode(key []byte) ([]any, uint64, bool) {
+	return decodeFixed(key, 8, getUint[uint64])
 }
 
 var idType = reflect.TypeFor[memdb.ID]()
 
+var _ memdb.FieldIndexer = &idIndexer{}
+var _ memdb.DecodingIndexer = &idIndexer{}
 var _ memdb.Indexer = &idIndexer{}
-var _ memdb.Indexer = &idIndexer{}
-
-func idToBytes(id memdb.ID, b []byte) {
-	copy(b, id[:])
-}
 
 type idIndexer struct {
 	offset uintptr
@@ -490,6 +630,10 @@ func (i *idIndexer) Args() []memdb.ArgSerializer {
 	return i.args
 }
 
+func (i *idIndexer) Fields() []memdb.IndexField {
+	return indexFields[memdb.ID](i.offset)
+}
+
 func (i *idIndexer) SizeFromObject(o unsafe.Pointer) uint64 {
 	return memdb.IDLength
 }
@@ -499,13 +643,22 @@ func (i *idIndexer) SizeFromArg(arg any) uint64 {
 }
 
 func (i *idIndexer) FromArg(b []byte, arg any) uint64 {
-	idToBytes(reflect.ValueOf(arg).Convert(idType).Interface().(memdb.ID), b)
-	return memdb.IDLength
+	if v, ok := arg.(memdb.ID); ok {
+		return PutID(b, v)
+	}
+	return PutID(b, reflect.ValueOf(arg).Convert(idType).Interface().(memdb.ID))
 }
 
 func (i *idIndexer) FromObject(b []byte, o unsafe.Pointer) uint64 {
-	idToBytes(valueByOffset[memdb.ID](o, i.offset), b)
-	return memdb.IDLength
+	return PutID(b, valueByOffset[memdb.ID](o, i.offset))
+}
+
+func (i *idIndexer) Decode(key []byte) []any {
+	return decodeKey(i, key)
+}
+
+func (i *idIndexer) decode(key []byte) ([]any, uint64, bool) {
+	return decodeFixed(key, memdb.IDLength, getID)
 }
 
 func indexerForType(t reflect.Type, offset uintptr) memdb.Indexer {
@@ -18,6 +18,8 @@ const (
 	def = "DEF"
 )
 
+var oTableID = memdb.TableID(reflect.TypeFor[o]())
+
 type o struct {
 	ID     memdb.ID
 	Value1 uint64
@@ -359,18 +361,18 @@ func TestEntityUpdateWithFieldIndex(t *testing.T) {
 		Value1: 1,
 	}
 
-	old, err := txn.Insert(0, unsafe.Pointer(e))
+	old, err := txn.Insert(oTableID, unsafe.Pointer(e))
 	requireT.NoError(err)
 	requireT.Zero(old)
-	txn.Commit()
+	requireT.NoError(txn.Commit())
 
 	txn = db.Txn(true)
-	e2, err := txn.First(0, memdb.IDIndexID, eID)
+	e2, err := txn.First(oTableID, memdb.IDIndexID, eID)
 	requireT.NoError(err)
 	requireT.NotZero(e2)
 	requireT.Equal(e, (*o)(e2))
 
-	e3, err := txn.First(0, index.ID(), uint64(1))
+	e3, err := txn.First(oTableID, index.ID(), uint64(1))
 	requireT.NoError(err)
 	requireT.NotZero(e3)
 	requireT.Equal(e2, e3)
@@ -380,24 +382,24 @@ func TestEntityUpdateWithFieldIndex(t *testing.T) {
 		Value1: 2,
 	}
 
-	old, err = txn.Insert(0, unsafe.Pointer(e4))
+	old, err = txn.Insert(oTableID, unsafe.Pointer(e4))
 	requireT.NoError(err)
 	requireT.NotZero(old)
 	requireT.Equal(e, (*o)(old))
-	txn.Commit()
+	requireT.NoError(txn.Commit())
 
 	txn = db.Txn(false)
-	e2, err = txn.First(0, memdb.IDIndexID, eID)
+	e2, err = txn.First(oTableID, memdb.IDIndexID, eID)
 	requireT.NoError(err)
 	requireT.NotZero(e2)
 	requireT.Equal(e4, (*o)(e2))
 
-	e3, err = txn.First(0, index.ID(), uint64(2))
+	e3, err = txn.First(oTableID, index.ID(), uint64(2))
 	requireT.NoError(err)
 	requireT.NotZero(e3)
 	requireT.Equal(e2, e3)
 
-	e3, err = txn.First(0, index.ID(), uint64(1))
+	e3, err = txn.First(oTableID, index.ID(), uint64(1))
 	requireT.NoError(err)
 	requireT.Zero(e3)
 }
@@ -423,34 +425,34 @@ func TestEntityDeleteWithFieldIndex(t *testing.T) {
 		Value1: 1,
 	}
 
-	old, err := txn.Insert(0, unsafe.Pointer(e))
+	old, err := txn.Insert(oTableID, unsafe.Pointer(e))
 	requireT.NoError(err)
 	requireT.Zero(old)
-	txn.Commit()
+	requireT.NoError(txn.Commit())
 
 	txn = db.Txn(true)
-	e2, err := txn.First(0, memdb.IDIndexID, eID)
+	e2, err := txn.First(oTableID, memdb.IDIndexID, eID)
 	requireT.NoError(err)
 	requireT.NotZero(e2)
 	requireT.Equal(e, (*o)(e2))
 
-	e3, err := txn.First(0, index.ID(), uint64(1))
+	e3, err := txn.First(oTableID, index.ID(), uint64(1))
 	requireT.NoError(err)
 	requireT.NotZero(e3)
 	requireT.Equal(e2, e3)
 
-	old, err = txn.Delete(0, unsafe.Pointer(e))
+	old, err = txn.Delete(oTableID, unsafe.Pointer(e))
 	requireT.NoError(err)
 	requireT.NotZero(old)
 	requireT.Equal(e, (*o)(old))
-	txn.Commit()
+	requireT.NoError(txn.Commit())
 
 	txn = db.Txn(false)
-	e2, err = txn.First(0, memdb.IDIndexID, eID)
+	e2, err = txn.First(oTableID, memdb.IDIndexID, eID)
 	requireT.NoError(err)
 	requireT.Zero(e2)
 
-	e3, err = txn.First(0, index.ID(), uint64(1))
+	e3, err = txn.First(oTableID, index.ID(), uint64(1))
 	requireT.NoError(err)
 	requireT.Zero(e3)
 }
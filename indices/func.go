@@ -2,157 +2,215 @@ package indices
 
 import (
 	"reflect"
+	"runtime"
+	"strings"
 	"unsafe"
 
+	"github.com/pkg/errors"
+
 	"github.com/outofforest/memdb"
 )
 
 // FuncIndex is an index based on values returned from function.
 type FuncIndex[T any] struct {
 	id      uint64
+	name    string
 	indexer memdb.Indexer
 }
 
+// FuncKey is a component of the function index key.
+type FuncKey[T any] interface {
+	name() string
+	args() []memdb.ArgSerializer
+	multiValued() bool
+	sizeFromObject(e *T) uint64
+	fromObject(b []byte, e *T) uint64
+	values(e *T) keyValues
+	decode(key []byte) ([]any, uint64, bool)
+}
+
+// keyValues are the values of key component computed for the object.
+type keyValues interface {
+	len() uint64
+	sizeFromValue(n uint64) uint64
+	fromValue(b []byte, n uint64) uint64
+}
+
+// Key defines key component computed by function f.
+func Key[T any, V fieldConstraint](f func(ePtr *T) *V) FuncKey[T] {
+	return &funcKey[T, V]{
+		f:       f,
+		indexer: indexerForType(reflect.TypeFor[V](), 0),
+	}
+}
+
+// Keys defines multi-valued key component computed by function f.
+// Entity is stored in the index once for each value returned by f.
+func Keys[T any, V fieldConstraint](f func(ePtr *T) []V) FuncKey[T] {
+	return &funcMultiKey[T, V]{
+		f:       f,
+		indexer: indexerForType(reflect.TypeFor[V](), 0),
+	}
+}
+
+// Desc reverses the order of key component.
+func Desc[T any](key FuncKey[T]) FuncKey[T] {
+	args := make([]memdb.ArgSerializer, 0, len(key.args()))
+	for _, a := range key.args() {
+		args = append(args, &reverseArgSerializer{subArg: a})
+	}
+	return &descKey[T]{
+		key:     key,
+		argsDef: args,
+	}
+}
+
+// NewFuncIndex creates index from key components.
+// Index name is derived from the names of functions computing the key components. Names of anonymous functions
+// are not stable, so index using them must be named explicitly by NewNamedIndex, otherwise it is rejected by memdb.
+func NewFuncIndex[T any](keys ...FuncKey[T]) *FuncIndex[T] {
+	names := make([]string, 0, len(keys))
+	for _, k := range keys {
+		names = append(names, k.name())
+	}
+	return newFuncIndex("func("+strings.Join(names, ",")+")", keys...)
+}
+
+func newFuncIndex[T any](name string, keys ...FuncKey[T]) *FuncIndex[T] {
+	var _ memdb.DecodingIndexer = &funcIndexer[T]{}
+	var _ memdb.MultiKeyIndexer = &funcMultiIndexer[T]{}
+
+	if len(keys) == 0 {
+		panic(errors.Errorf("no keys has been provided"))
+	}
+
+	indexer := funcIndexer[T]{
+		keys: keys,
+	}
+	var multiValued bool
+	for _, k := range keys {
+		indexer.args = append(indexer.args, k.args()...)
+		multiValued = multiValued || k.multiValued()
+	}
+
+	if multiValued {
+		return newIndex[T](name, &funcMultiIndexer[T]{funcIndexer: indexer})
+	}
+	return newIndex[T](name, &indexer)
+}
+
+func newIndex[T any](name string, indexer memdb.Indexer) *FuncIndex[T] {
+	var _ Index[T] = (*FuncIndex[T])(nil)
+
+	return &FuncIndex[T]{
+		id:      memdb.IndexID(name),
+		name:    name,
+		indexer: indexer,
+	}
+}
+
 // NewFuncIndex1 creates index from 1 result.
+//
+// Deprecated: Use NewFuncIndex.
 func NewFuncIndex1[T any, V1 fieldConstraint](
 	f func(ePtr *T) *V1,
 ) *FuncIndex[T] {
-	var _ Index[T] = (*FuncIndex[T])(nil)
-	var _ memdb.Indexer = &funcIndexer1[T, V1]{}
-
-	sis, args := funcIndexArgs([]reflect.Type{
-		reflect.TypeFor[V1](),
-	})
-	index := &FuncIndex[T]{
-		indexer: &funcIndexer1[T, V1]{
-			sis:  sis,
-			args: args,
-			f:    f,
-		},
-	}
-	index.id = uint64(uintptr(unsafe.Pointer(index)))
-	return index
+	return newFuncIndex("func("+funcName(f)+")", Key(f))
 }
 
 // NewFuncIndex2 creates index from 2 results.
+//
+// Deprecated: Use NewFuncIndex.
 func NewFuncIndex2[T any, V1, V2 fieldConstraint](
 	f func(ePtr *T) (*V1, *V2),
 ) *FuncIndex[T] {
-	var _ Index[T] = (*FuncIndex[T])(nil)
-	var _ memdb.Indexer = &funcIndexer2[T, V1, V2]{}
-
-	sis, args := funcIndexArgs([]reflect.Type{
-		reflect.TypeFor[V1](),
-		reflect.TypeFor[V2](),
+	var _ memdb.DecodingIndexer = &funcIndexer2[T, V1, V2]{}
+
+	return newIndex[T]("func("+funcName(f)+")", &funcIndexer2[T, V1, V2]{
+		tupleIndexer: newTupleIndexer(
+			reflect.TypeFor[V1](),
+			reflect.TypeFor[V2](),
+		),
+		f: f,
 	})
-	index := &FuncIndex[T]{
-		indexer: &funcIndexer2[T, V1, V2]{
-			sis:  sis,
-			args: args,
-			f:    f,
-		},
-	}
-
-	index.id = uint64(uintptr(unsafe.Pointer(index)))
-	return index
 }
 
 // NewFuncIndex3 creates index from 3 results.
+//
+// Deprecated: Use NewFuncIndex.
 func NewFuncIndex3[T any, V1, V2, V3 fieldConstraint](
 	f func(ePtr *T) (*V1, *V2, *V3),
 ) *FuncIndex[T] {
-	var _ Index[T] = (*FuncIndex[T])(nil)
-	var _ memdb.Indexer = &funcIndexer3[T, V1, V2, V3]{}
-
-	sis, args := funcIndexArgs([]reflect.Type{
-		reflect.TypeFor[V1](),
-		reflect.TypeFor[V2](),
-		reflect.TypeFor[V3](),
+	var _ memdb.DecodingIndexer = &funcIndexer3[T, V1, V2, V3]{}
+
+	return newIndex[T]("func("+funcName(f)+")", &funcIndexer3[T, V1, V2, V3]{
+		tupleIndexer: newTupleIndexer(
+			reflect.TypeFor[V1](),
+			reflect.TypeFor[V2](),
+			reflect.TypeFor[V3](),
+		),
+		f: f,
 	})
-	index := &FuncIndex[T]{
-		indexer: &funcIndexer3[T, V1, V2, V3]{
-			sis:  sis,
-			args: args,
-			f:    f,
-		},
-	}
-	index.id = uint64(uintptr(unsafe.Pointer(index)))
-	return index
 }
 
 // NewFuncIndex4 creates index from 4 results.
+//
+// Deprecated: Use NewFuncIndex.
 func NewFuncIndex4[T any, V1, V2, V3, V4 fieldConstraint](
 	f func(ePtr *T) (*V1, *V2, *V3, *V4),
 ) *FuncIndex[T] {
-	var _ Index[T] = (*FuncIndex[T])(nil)
-	var _ memdb.Indexer = &funcIndexer4[T, V1, V2, V3, V4]{}
-
-	sis, args := funcIndexArgs([]reflect.Type{
-		reflect.TypeFor[V1](),
-		reflect.TypeFor[V2](),
-		reflect.TypeFor[V3](),
-		reflect.TypeFor[V4](),
+	var _ memdb.DecodingIndexer = &funcIndexer4[T, V1, V2, V3, V4]{}
+
+	return newIndex[T]("func("+funcName(f)+")", &funcIndexer4[T, V1, V2, V3, V4]{
+		tupleIndexer: newTupleIndexer(
+			reflect.TypeFor[V1](),
+			reflect.TypeFor[V2](),
+			reflect.TypeFor[V3](),
+			reflect.TypeFor[V4](),
+		),
+		f: f,
 	})
-	index := &FuncIndex[T]{
-		indexer: &funcIndexer4[T, V1, V2, V3, V4]{
-			sis:  sis,
-			args: args,
-			f:    f,
-		},
-	}
-	index.id = uint64(uintptr(unsafe.Pointer(index)))
-	return index
 }
 
 // NewFuncIndex5 creates index from 5 results.
+//
+// Deprecated: Use NewFuncIndex.
 func NewFuncIndex5[T any, V1, V2, V3, V4, V5 fieldConstraint](
 	f func(ePtr *T) (*V1, *V2, *V3, *V4, *V5),
 ) *FuncIndex[T] {
-	var _ Index[T] = (*FuncIndex[T])(nil)
-	var _ memdb.Indexer = &funcIndexer5[T, V1, V2, V3, V4, V5]{}
-
-	sis, args := funcIndexArgs([]reflect.Type{
-		reflect.TypeFor[V1](),
-		reflect.TypeFor[V2](),
-		reflect.TypeFor[V3](),
-		reflect.TypeFor[V4](),
-		reflect.TypeFor[V5](),
+	var _ memdb.DecodingIndexer = &funcIndexer5[T, V1, V2, V3, V4, V5]{}
+
+	return newIndex[T]("func("+funcName(f)+")", &funcIndexer5[T, V1, V2, V3, V4, V5]{
+		tupleIndexer: newTupleIndexer(
+			reflect.TypeFor[V1](),
+			reflect.TypeFor[V2](),
+			reflect.TypeFor[V3](),
+			reflect.TypeFor[V4](),
+			reflect.TypeFor[V5](),
+		),
+		f: f,
 	})
-	index := &FuncIndex[T]{
-		indexer: &funcIndexer5[T, V1, V2, V3, V4, V5]{
-			sis:  sis,
-			args: args,
-			f:    f,
-		},
-	}
-	index.id = uint64(uintptr(unsafe.Pointer(index)))
-	return index
 }
 
 // NewFuncIndex6 creates index from 6 results.
+//
+// Deprecated: Use NewFuncIndex.
 func NewFuncIndex6[T any, V1, V2, V3, V4, V5, V6 fieldConstraint](
 	f func(ePtr *T) (*V1, *V2, *V3, *V4, *V5, *V6),
 ) *FuncIndex[T] {
-	var _ Index[T] = (*FuncIndex[T])(nil)
-	var _ memdb.Indexer = &funcIndexer6[T, V1, V2, V3, V4, V5, V6]{}
-
-	sis, args := funcIndexArgs([]reflect.Type{
-		reflect.TypeFor[V1](),
-		reflect.TypeFor[V2](),
-		reflect.TypeFor[V3](),
-		reflect.TypeFor[V4](),
-		reflect.TypeFor[V5](),
-		reflect.TypeFor[V6](),
+	var _ memdb.DecodingIndexer = &funcIndexer6[T, V1, V2, V3, V4, V5, V6]{}
+
+	return newIndex[T]("func("+funcName(f)+")", &funcIndexer6[T, V1, V2, V3, V4, V5, V6]{
+		tupleIndexer: newTupleIndexer(
+			reflect.TypeFor[V1](),
+			reflect.TypeFor[V2](),
+			reflect.TypeFor[V3](),
+			reflect.TypeFor[V4](),
+			reflect.TypeFor[V5](),
+			reflect.TypeFor[V6](),
+		),
+		f: f,
 	})
-	index := &FuncIndex[T]{
-		indexer: &funcIndexer6[T, V1, V2, V3, V4, V5, V6]{
-			sis:  sis,
-			args: args,
-			f:    f,
-		},
-	}
-	index.id = uint64(uintptr(unsafe.Pointer(index)))
-	return index
 }
 
 // ID returns ID of the index.
@@ -160,6 +218,11 @@ func (i *FuncIndex[T]) ID() uint64 {
 	return i.id
 }
 
+// Name returns name of the index.
+func (i *FuncIndex[T]) Name() string {
+	return i.name
+}
+
 // Schema returns memdb index schema.
 func (i *FuncIndex[T]) Schema() *memdb.IndexSchema {
 	return &memdb.IndexSchema{
@@ -176,34 +239,320 @@ func (i *FuncIndex[T]) dummyTDefiner(t T) {
 	panic("it should never be called")
 }
 
-type funcIndexer1[T any, V1 fieldConstraint] struct {
-	sis  []memdb.Indexer
+type funcKey[T any, V fieldConstraint] struct {
+	f       func(ePtr *T) *V
+	indexer memdb.Indexer
+}
+
+func (k *funcKey[T, V]) name() string {
+	return funcName(k.f)
+}
+
+func (k *funcKey[T, V]) args() []memdb.ArgSerializer {
+	return k.indexer.Args()
+}
+
+func (k *funcKey[T, V]) multiValued() bool {
+	return false
+}
+
+func (k *funcKey[T, V]) sizeFromObject(e *T) uint64 {
+	return k.indexer.SizeFromObject(unsafe.Pointer(k.f(e)))
+}
+
+func (k *funcKey[T, V]) fromObject(b []byte, e *T) uint64 {
+	return k.indexer.FromObject(b, unsafe.Pointer(k.f(e)))
+}
+
+func (k *funcKey[T, V]) values(e *T) keyValues {
+	return &singleValue{
+		v:       unsafe.Pointer(k.f(e)),
+		indexer: k.indexer,
+	}
+}
+
+func (k *funcKey[T, V]) decode(key []byte) ([]any, uint64, bool) {
+	return k.indexer.(keyDecoder).decode(key)
+}
+
+type funcMultiKey[T any, V fieldConstraint] struct {
+	f       func(ePtr *T) []V
+	indexer memdb.Indexer
+}
+
+func (k *funcMultiKey[T, V]) name() string {
+	return funcName(k.f)
+}
+
+func (k *funcMultiKey[T, V]) args() []memdb.ArgSerializer {
+	return k.indexer.Args()
+}
+
+func (k *funcMultiKey[T, V]) multiValued() bool {
+	return true
+}
+
+// sizeFromObject returns the size of the first value. Keys of multi-valued components are built from values
+// computed once, so it is not used by the indexers.
+func (k *funcMultiKey[T, V]) sizeFromObject(e *T) uint64 {
+	return k.values(e).sizeFromValue(0)
+}
+
+// fromObject encodes the first value.
+func (k *funcMultiKey[T, V]) fromObject(b []byte, e *T) uint64 {
+	return k.values(e).fromValue(b, 0)
+}
+
+func (k *funcMultiKey[T, V]) values(e *T) keyValues {
+	return &multiValues[V]{
+		vs:      k.f(e),
+		indexer: k.indexer,
+	}
+}
+
+func (k *funcMultiKey[T, V]) decode(key []byte) ([]any, uint64, bool) {
+	return k.indexer.(keyDecoder).decode(key)
+}
+
+type descKey[T any] struct {
+	key     FuncKey[T]
+	argsDef []memdb.ArgSerializer
+}
+
+func (k *descKey[T]) name() string {
+	return "desc(" + k.key.name() + ")"
+}
+
+func (k *descKey[T]) args() []memdb.ArgSerializer {
+	return k.argsDef
+}
+
+func (k *descKey[T]) multiValued() bool {
+	return k.key.multiValued()
+}
+
+func (k *descKey[T]) sizeFromObject(e *T) uint64 {
+	return k.key.sizeFromObject(e)
+}
+
+func (k *descKey[T]) fromObject(b []byte, e *T) uint64 {
+	n := k.key.fromObject(b, e)
+	negate(b[:n])
+	return n
+}
+
+func (k *descKey[T]) values(e *T) keyValues {
+	return &descValues{values: k.key.values(e)}
+}
+
+func (k *descKey[T]) decode(key []byte) ([]any, uint64, bool) {
+	return decodeNegated(k.key, key)
+}
+
+type singleValue struct {
+	v       unsafe.Pointer
+	indexer memdb.Indexer
+}
+
+func (v *singleValue) len() uint64 {
+	return 1
+}
+
+func (v *singleValue) sizeFromValue(n uint64) uint64 {
+	return v.indexer.SizeFromObject(v.v)
+}
+
+func (v *singleValue) fromValue(b []byte, n uint64) uint64 {
+	return v.indexer.FromObject(b, v.v)
+}
+
+type multiValues[V fieldConstraint] struct {
+	vs      []V
+	indexer memdb.Indexer
+}
+
+func (v *multiValues[V]) len() uint64 {
+	return uint64(len(v.vs))
+}
+
+func (v *multiValues[V]) sizeFromValue(n uint64) uint64 {
+	if n >= uint64(len(v.vs)) {
+		return 0
+	}
+	return v.indexer.SizeFromObject(unsafe.Pointer(&v.vs[n]))
+}
+
+func (v *multiValues[V]) fromValue(b []byte, n uint64) uint64 {
+	if n >= uint64(len(v.vs)) {
+		return 0
+	}
+	return v.indexer.FromObject(b, unsafe.Pointer(&v.vs[n]))
+}
+
+type descValues struct {
+	values keyValues
+}
+
+func (v *descValues) len() uint64 {
+	return v.values.len()
+}
+
+func (v *descValues) sizeFromValue(n uint64) uint64 {
+	return v.values.sizeFromValue(n)
+}
+
+func (v *descValues) fromValue(b []byte, n uint64) uint64 {
+	n = v.values.fromValue(b, n)
+	negate(b[:n])
+	return n
+}
+
+type funcIndexer[T any] struct {
+	keys []FuncKey[T]
 	args []memdb.ArgSerializer
-	f    func(ePtr *T) *V1
 }
 
-func (i *funcIndexer1[T, V1]) Args() []memdb.ArgSerializer {
+func (i *funcIndexer[T]) Args() []memdb.ArgSerializer {
 	return i.args
 }
 
-func (i *funcIndexer1[T, V1]) SizeFromObject(o unsafe.Pointer) uint64 {
-	return i.sis[0].SizeFromObject(unsafe.Pointer(i.f((*T)(o))))
+func (i *funcIndexer[T]) SizeFromObject(o unsafe.Pointer) uint64 {
+	var size uint64
+	for _, k := range i.keys {
+		size += k.sizeFromObject((*T)(o))
+	}
+	return size
+}
+
+func (i *funcIndexer[T]) FromObject(b []byte, o unsafe.Pointer) uint64 {
+	var n uint64
+	for _, k := range i.keys {
+		n += k.fromObject(b[n:], (*T)(o))
+	}
+	return n
 }
 
-func (i *funcIndexer1[T, V1]) FromObject(b []byte, o unsafe.Pointer) uint64 {
-	return i.sis[0].FromObject(b, unsafe.Pointer(i.f((*T)(o))))
+func (i *funcIndexer[T]) Decode(key []byte) []any {
+	return decodeKey(i, key)
 }
 
-type funcIndexer2[T any, V1, V2 fieldConstraint] struct {
+func (i *funcIndexer[T]) decode(key []byte) ([]any, uint64, bool) {
+	values := make([]any, 0, len(i.args))
+	var n uint64
+	for _, k := range i.keys {
+		v, m, ok := k.decode(key[n:])
+		if !ok {
+			return nil, 0, false
+		}
+		values = append(values, v...)
+		n += m
+	}
+	return values, n, true
+}
+
+// funcMultiIndexer produces the cartesian product of values returned by multi-valued components.
+type funcMultiIndexer[T any] struct {
+	funcIndexer[T]
+}
+
+func (i *funcMultiIndexer[T]) SizeFromObject(o unsafe.Pointer) uint64 {
+	keys := i.KeysFromObject(o)
+	if len(keys) == 0 {
+		return 0
+	}
+	return uint64(len(keys[0]))
+}
+
+func (i *funcMultiIndexer[T]) FromObject(b []byte, o unsafe.Pointer) uint64 {
+	keys := i.KeysFromObject(o)
+	if len(keys) == 0 {
+		return 0
+	}
+	return uint64(copy(b, keys[0]))
+}
+
+// KeysFromObject calls functions of the components once and builds all the keys from the computed values.
+func (i *funcMultiIndexer[T]) KeysFromObject(o unsafe.Pointer) [][]byte {
+	values := make([]keyValues, 0, len(i.keys))
+	numOfKeys := uint64(1)
+	for _, k := range i.keys {
+		v := k.values((*T)(o))
+		numOfKeys *= v.len()
+		values = append(values, v)
+	}
+	if numOfKeys == 0 {
+		return nil
+	}
+
+	// The n-th key is built from values selected by digits of n in the mixed radix defined by the numbers
+	// of values.
+	var size uint64
+	for n := range numOfKeys {
+		m := n
+		for _, v := range values {
+			size += v.sizeFromValue(m % v.len())
+			m /= v.len()
+		}
+	}
+
+	b := make([]byte, size)
+	keys := make([][]byte, 0, numOfKeys)
+	for n := range numOfKeys {
+		m := n
+		var keySize uint64
+		for _, v := range values {
+			keySize += v.fromValue(b[keySize:], m%v.len())
+			m /= v.len()
+		}
+		keys = append(keys, b[:keySize:keySize])
+		b = b[keySize:]
+	}
+	return keys
+}
+
+// tupleIndexer is the base of indexers computing all the key components by single call of the function.
+type tupleIndexer struct {
 	sis  []memdb.Indexer
 	args []memdb.ArgSerializer
-	f    func(ePtr *T) (*V1, *V2)
 }
 
-func (i *funcIndexer2[T, V1, V2]) Args() []memdb.ArgSerializer {
+func newTupleIndexer(types ...reflect.Type) tupleIndexer {
+	var i tupleIndexer
+	for _, t := range types {
+		si := indexerForType(t, 0)
+		i.sis = append(i.sis, si)
+		i.args = append(i.args, si.Args()...)
+	}
+	return i
+}
+
+func (i *tupleIndexer) Args() []memdb.ArgSerializer {
 	return i.args
 }
 
+func (i *tupleIndexer) Decode(key []byte) []any {
+	return decodeKey(i, key)
+}
+
+func (i *tupleIndexer) decode(key []byte) ([]any, uint64, bool) {
+	values := make([]any, 0, len(i.args))
+	var n uint64
+	for _, si := range i.sis {
+		v, m, ok := si.(keyDecoder).decode(key[n:])
+		if !ok {
+			return nil, 0, false
+		}
+		values = append(values, v...)
+		n += m
+	}
+	return values, n, true
+}
+
+type funcIndexer2[T any, V1, V2 fieldConstraint] struct {
+	tupleIndexer
+	f func(ePtr *T) (*V1, *V2)
+}
+
 func (i *funcIndexer2[T, V1, V2]) SizeFromObject(o unsafe.Pointer) uint64 {
 	v1, v2 := i.f((*T)(o))
 	return i.sis[0].SizeFromObject(unsafe.Pointer(v1)) +
@@ -219,13 +568,8 @@ func (i *funcIndexer2[T, V1, V2]) FromObject(b []byte, o unsafe.Pointer) uint64
 }
 
 type funcIndexer3[T any, V1, V2, V3 fieldConstraint] struct {
-	sis  []memdb.Indexer
-	args []memdb.ArgSerializer
-	f    func(ePtr *T) (*V1, *V2, *V3)
-}
-
-func (i *funcIndexer3[T, V1, V2, V3]) Args() []memdb.ArgSerializer {
-	return i.args
+	tupleIndexer
+	f func(ePtr *T) (*V1, *V2, *V3)
 }
 
 func (i *funcIndexer3[T, V1, V2, V3]) SizeFromObject(o unsafe.Pointer) uint64 {
@@ -245,13 +589,8 @@ func (i *funcIndexer3[T, V1, V2, V3]) FromObject(b []byte, o unsafe.Pointer) uin
 }
 
 type funcIndexer4[T any, V1, V2, V3, V4 fieldConstraint] struct {
-	sis  []memdb.Indexer
-	args []memdb.ArgSerializer
-	f    func(ePtr *T) (*V1, *V2, *V3, *V4)
-}
-
-func (i *funcIndexer4[T, V1, V2, V3, V4]) Args() []memdb.ArgSerializer {
-	return i.args
+	tupleIndexer
+	f func(ePtr *T) (*V1, *V2, *V3, *V4)
 }
 
 func (i *funcIndexer4[T, V1, V2, V3, V4]) SizeFromObject(o unsafe.Pointer) uint64 {
@@ -273,13 +612,8 @@ func (i *funcIndexer4[T, V1, V2, V3, V4]) FromObject(b []byte, o unsafe.Pointer)
 }
 
 type funcIndexer5[T any, V1, V2, V3, V4, V5 fieldConstraint] struct {
-	sis  []memdb.Indexer
-	args []memdb.ArgSerializer
-	f    func(ePtr *T) (*V1, *V2, *V3, *V4, *V5)
-}
-
-func (i *funcIndexer5[T, V1, V2, V3, V4, V5]) Args() []memdb.ArgSerializer {
-	return i.args
+	tupleIndexer
+	f func(ePtr *T) (*V1, *V2, *V3, *V4, *V5)
 }
 
 func (i *funcIndexer5[T, V1, V2, V3, V4, V5]) SizeFromObject(o unsafe.Pointer) uint64 {
@@ -303,13 +637,8 @@ func (i *funcIndexer5[T, V1, V2, V3, V4, V5]) FromObject(b []byte, o unsafe.Poin
 }
 
 type funcIndexer6[T any, V1, V2, V3, V4, V5, V6 fieldConstraint] struct {
-	sis  []memdb.Indexer
-	args []memdb.ArgSerializer
-	f    func(ePtr *T) (*V1, *V2, *V3, *V4, *V5, *V6)
-}
-
-func (i *funcIndexer6[T, V1, V2, V3, V4, V5, V6]) Args() []memdb.ArgSerializer {
-	return i.args
+	tupleIndexer
+	f func(ePtr *T) (*V1, *V2, *V3, *V4, *V5, *V6)
 }
 
 func (i *funcIndexer6[T, V1, V2, V3, V4, V5, V6]) SizeFromObject(o unsafe.Pointer) uint64 {
@@ -334,15 +663,8 @@ func (i *funcIndexer6[T, V1, V2, V3, V4, V5, V6]) FromObject(b []byte, o unsafe.
 	return n
 }
 
-func funcIndexArgs(types []reflect.Type) ([]memdb.Indexer, []memdb.ArgSerializer) {
-	sis := make([]memdb.Indexer, 0, len(types))
-	args := make([]memdb.ArgSerializer, 0, len(types))
-
-	for _, t := range types {
-		subIndexer := indexerForType(t, 0)
-		sis = append(sis, subIndexer)
-		args = append(args, subIndexer.Args()...)
-	}
-
-	return sis, args
+// funcName returns the name of the function. Name of the named function is stable as long as the function
+// is not renamed or moved, while the name of anonymous function depends on its position in the enclosing one.
+func funcName(f any) string {
+	return runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
 }
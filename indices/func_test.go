@@ -7,6 +7,8 @@ import (
 
 	"github.com/samber/lo"
 	"github.com/stretchr/testify/require"
+
+	"github.com/outofforest/memdb"
 )
 
 func TestFuncIndexType(t *testing.T) {
@@ -325,3 +327,247 @@ func TestFuncIndex6Strings(t *testing.T) {
 	requireT.EqualValues(6, args[4].SizeFromArg("EEEEE"))
 	requireT.EqualValues(7, args[5].SizeFromArg("FFFFFF"))
 }
+
+func TestFuncIndex7(t *testing.T) {
+	t.Parallel()
+
+	requireT := require.New(t)
+
+	i := NewFuncIndex(
+		Key(func(e *o) *uint8 { return lo.ToPtr[uint8](0xff) }),
+		Key(func(e *o) *uint16 { return lo.ToPtr[uint16](0xeeee) }),
+		Key(func(e *o) *uint32 { return lo.ToPtr[uint32](0xdddddddd) }),
+		Key(func(e *o) *uint64 { return lo.ToPtr[uint64](0xcccccccccccccccc) }),
+		Key(func(e *o) *uint8 { return lo.ToPtr[uint8](0xbb) }),
+		Key(func(e *o) *uint16 { return lo.ToPtr[uint16](0xaaaa) }),
+		Key(func(e *o) *string { return lo.ToPtr("A") }),
+	)
+
+	b := make([]byte, 21)
+	requireT.EqualValues(20, i.Schema().Indexer.SizeFromObject(unsafe.Pointer(&o{})))
+	size := i.Schema().Indexer.FromObject(b, unsafe.Pointer(&o{}))
+	requireT.EqualValues(20, size)
+	requireT.Equal([]byte{0xff, 0xee, 0xee, 0xdd, 0xdd, 0xdd, 0xdd, 0xcc, 0xcc, 0xcc, 0xcc, 0xcc, 0xcc, 0xcc, 0xcc,
+		0xbb, 0xaa, 0xaa, 0x41, 0x00, 0x0}, b)
+
+	args := i.Schema().Indexer.Args()
+	requireT.Len(args, 7)
+	requireT.EqualValues(1, args[0].SizeFromArg(uint8(0)))
+	requireT.EqualValues(2, args[1].SizeFromArg(uint16(0)))
+	requireT.EqualValues(4, args[2].SizeFromArg(uint32(0)))
+	requireT.EqualValues(8, args[3].SizeFromArg(uint64(0)))
+	requireT.EqualValues(1, args[4].SizeFromArg(uint8(0)))
+	requireT.EqualValues(2, args[5].SizeFromArg(uint16(0)))
+	requireT.EqualValues(2, args[6].SizeFromArg("A"))
+}
+
+func TestFuncIndexDesc(t *testing.T) {
+	t.Parallel()
+
+	requireT := require.New(t)
+
+	i := NewFuncIndex(
+		Key(func(e *o) *uint8 { return lo.ToPtr[uint8](0x0f) }),
+		Desc(Key(func(e *o) *uint16 { return lo.ToPtr[uint16](0x0102) })),
+	)
+
+	b := make([]byte, 3)
+	requireT.EqualValues(3, i.Schema().Indexer.SizeFromObject(unsafe.Pointer(&o{})))
+	size := i.Schema().Indexer.FromObject(b, unsafe.Pointer(&o{}))
+	requireT.EqualValues(3, size)
+	requireT.Equal([]byte{0x0f, 0xfe, 0xfd}, b)
+
+	args := i.Schema().Indexer.Args()
+	requireT.Len(args, 2)
+	b = make([]byte, 2)
+	requireT.EqualValues(2, args[1].SizeFromArg(uint16(0x0102)))
+	requireT.EqualValues(2, args[1].FromArg(b, uint16(0x0102)))
+	requireT.Equal([]byte{0xfe, 0xfd}, b)
+}
+
+func TestFuncIndexMultiValued(t *testing.T) {
+	t.Parallel()
+
+	requireT := require.New(t)
+
+	i := NewFuncIndex(
+		Keys(func(e *o) []uint8 { return []uint8{0x01, 0x02} }),
+		Key(func(e *o) *uint8 { return lo.ToPtr[uint8](0xff) }),
+		Desc(Keys(func(e *o) []uint8 { return []uint8{0x01, 0x02, 0x03} })),
+	)
+
+	indexer, ok := i.Schema().Indexer.(memdb.MultiKeyIndexer)
+	requireT.True(ok)
+
+	keys := indexer.KeysFromObject(unsafe.Pointer(&o{}))
+	requireT.ElementsMatch([][]byte{
+		{0x01, 0xff, 0xfe},
+		{0x01, 0xff, 0xfd},
+		{0x01, 0xff, 0xfc},
+		{0x02, 0xff, 0xfe},
+		{0x02, 0xff, 0xfd},
+		{0x02, 0xff, 0xfc},
+	}, keys)
+
+	i = NewFuncIndex(
+		Key(func(e *o) *uint8 { return lo.ToPtr[uint8](0xff) }),
+		Keys(func(e *o) []uint8 { return nil }),
+	)
+	requireT.Empty(i.Schema().Indexer.(memdb.MultiKeyIndexer).KeysFromObject(unsafe.Pointer(&o{})))
+	requireT.Zero(i.Schema().Indexer.SizeFromObject(unsafe.Pointer(&o{})))
+}
+
+func TestFuncIndexCallsFunc(t *testing.T) {
+	t.Parallel()
+
+	requireT := require.New(t)
+
+	var calls int
+	i := NewFuncIndex6(func(e *o) (*uint8, *uint8, *uint8, *uint8, *uint8, *uint8) {
+		calls++
+		v := lo.ToPtr[uint8](0xff)
+		return v, v, v, v, v, v
+	})
+
+	b := make([]byte, i.Schema().Indexer.SizeFromObject(unsafe.Pointer(&o{})))
+	requireT.EqualValues(6, i.Schema().Indexer.FromObject(b, unsafe.Pointer(&o{})))
+	requireT.Equal(2, calls)
+
+	var multiCalls int
+	multiIndex := NewFuncIndex(
+		Keys(func(e *o) []uint8 {
+			multiCalls++
+			return []uint8{0x01, 0x02, 0x03}
+		}),
+		Desc(Keys(func(e *o) []uint8 {
+			multiCalls++
+			return []uint8{0x01, 0x02, 0x03}
+		})),
+	)
+	requireT.Len(multiIndex.Schema().Indexer.(memdb.MultiKeyIndexer).KeysFromObject(unsafe.Pointer(&o{})), 9)
+	requireT.Equal(2, multiCalls)
+}
+
+func BenchmarkFuncIndex(b *testing.B) {
+	tuple := func(e *o) (*uint64, *uint64, *string, *uint64, *bool, *string) {
+		return &e.Value1, &e.Value2.Value1, &e.Value2.Value3, &e.Value3.ValueUint64, &e.Value3.ValueBool,
+			&e.Value4
+	}
+	benchmarks := []struct {
+		name  string
+		index *FuncIndex[o]
+	}{
+		{
+			name:  "NewFuncIndex6",
+			index: NewFuncIndex6(tuple),
+		},
+		{
+			name: "NewFuncIndex",
+			index: NewFuncIndex(
+				Key(func(e *o) *uint64 { return &e.Value1 }),
+				Key(func(e *o) *uint64 { return &e.Value2.Value1 }),
+				Key(func(e *o) *string { return &e.Value2.Value3 }),
+				Key(func(e *o) *uint64 { return &e.Value3.ValueUint64 }),
+				Key(func(e *o) *bool { return &e.Value3.ValueBool }),
+				Key(func(e *o) *string { return &e.Value4 }),
+			),
+		},
+	}
+
+	v := &o{
+		Value1: 1,
+		Value2: subO1{Value1: 2, Value3: abc},
+		Value3: subO2{ValueUint64: 3, ValueBool: true},
+		Value4: def,
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			indexer := bm.index.Schema().Indexer
+			buf := make([]byte, indexer.SizeFromObject(unsafe.Pointer(v)))
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for range b.N {
+				buf = buf[:indexer.SizeFromObject(unsafe.Pointer(v))]
+				indexer.FromObject(buf, unsafe.Pointer(v))
+			}
+		})
+	}
+
+	b.Run("Keys", func(b *testing.B) {
+		values := []uint64{1, 2, 3, 4, 5, 6, 7, 8}
+		indexer := NewFuncIndex(
+			Keys(func(e *o) []uint64 { return values }),
+			Key(func(e *o) *string { return &e.Value4 }),
+		).Schema().Indexer.(memdb.MultiKeyIndexer)
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for range b.N {
+			indexer.KeysFromObject(unsafe.Pointer(v))
+		}
+	})
+}
+
+type taggedO struct {
+	ID   memdb.ID
+	Tags []string
+}
+
+func TestEntityWithMultiValuedFuncIndex(t *testing.T) {
+	requireT := require.New(t)
+
+	index := NewNamedIndex("tags", NewFuncIndex(Keys(func(e *taggedO) []string {
+		return e.Tags
+	})))
+
+	c := memdb.Config{
+		Entities: []reflect.Type{reflect.TypeFor[taggedO]()},
+		Indices:  []memdb.Index{index},
+	}
+
+	db, err := memdb.NewMemDB(c)
+	requireT.NoError(err)
+	tableID := memdb.TableID(reflect.TypeFor[taggedO]())
+	txn := db.Txn(true)
+
+	e1 := &taggedO{ID: memdb.ID{1}, Tags: []string{"a", "b"}}
+	e2 := &taggedO{ID: memdb.ID{2}, Tags: []string{"b", "c"}}
+
+	_, err = txn.Insert(tableID, unsafe.Pointer(e1))
+	requireT.NoError(err)
+	_, err = txn.Insert(tableID, unsafe.Pointer(e2))
+	requireT.NoError(err)
+	requireT.NoError(txn.Commit())
+
+	collect := func(txn *memdb.Txn, tag string) []*taggedO {
+		it, err := txn.Iterator(tableID, index.ID(), tag)
+		requireT.NoError(err)
+		var result []*taggedO
+		for e := it.Next(); e != nil; e = it.Next() {
+			result = append(result, (*taggedO)(e))
+		}
+		return result
+	}
+
+	txn = db.Txn(true)
+	requireT.Equal([]*taggedO{e1}, collect(txn, "a"))
+	requireT.Equal([]*taggedO{e1, e2}, collect(txn, "b"))
+	requireT.Equal([]*taggedO{e2}, collect(txn, "c"))
+
+	e3 := &taggedO{ID: memdb.ID{1}, Tags: []string{"c"}}
+	_, err = txn.Insert(tableID, unsafe.Pointer(e3))
+	requireT.NoError(err)
+	requireT.Empty(collect(txn, "a"))
+	requireT.Equal([]*taggedO{e2}, collect(txn, "b"))
+	requireT.Equal([]*taggedO{e3, e2}, collect(txn, "c"))
+
+	_, err = txn.Delete(tableID, unsafe.Pointer(e2))
+	requireT.NoError(err)
+	requireT.NoError(txn.Commit())
+
+	txn = db.Txn(false)
+	requireT.Empty(collect(txn, "a"))
+	requireT.Empty(collect(txn, "b"))
+	requireT.Equal([]*taggedO{e3}, collect(txn, "c"))
+}
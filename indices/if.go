@@ -10,17 +10,19 @@ import (
 // IfIndex indexes those elements from another index for which f returns true.
 type IfIndex[T any] struct {
 	id       uint64
+	name     string
 	subIndex Index[T]
 	indexer  *ifIndexer[T]
 	unique   bool
 }
 
-// NewIfIndex creates new conditional index.
+// NewIfIndex creates new conditional index. If f is anonymous function, index must be named by NewNamedIndex.
 func NewIfIndex[T any](subIndex Index[T], f func(o *T) bool) *IfIndex[T] {
 	var _ Index[T] = (*IfIndex[T])(nil)
 
 	schema := subIndex.Schema()
 	index := &IfIndex[T]{
+		name:     "if(" + subIndex.Name() + "," + funcName(f) + ")",
 		subIndex: subIndex,
 		indexer: &ifIndexer[T]{
 			subIndexer: schema.Indexer,
@@ -29,7 +31,7 @@ func NewIfIndex[T any](subIndex Index[T], f func(o *T) bool) *IfIndex[T] {
 		},
 		unique: schema.Unique,
 	}
-	index.id = uint64(uintptr(unsafe.Pointer(index)))
+	index.id = memdb.IndexID(index.name)
 	return index
 }
 
@@ -38,6 +40,11 @@ func (i *IfIndex[T]) ID() uint64 {
 	return i.id
 }
 
+// Name returns name of the index.
+func (i *IfIndex[T]) Name() string {
+	return i.name
+}
+
 // Schema returns memdb index schema.
 func (i *IfIndex[T]) Schema() *memdb.IndexSchema {
 	return &memdb.IndexSchema{
@@ -55,7 +62,7 @@ func (i *IfIndex[T]) dummyTDefiner(t T) {
 	panic("it should never be called")
 }
 
-var _ memdb.Indexer = &ifIndexer[int]{}
+var _ memdb.DecodingIndexer = &ifIndexer[int]{}
 
 type ifIndexer[T any] struct {
 	subIndexer memdb.Indexer
@@ -80,3 +87,15 @@ func (i *ifIndexer[T]) FromObject(b []byte, o unsafe.Pointer) uint64 {
 	}
 	return i.subIndexer.FromObject(b, o)
 }
+
+func (i *ifIndexer[T]) Decode(key []byte) []any {
+	return decodeKey(i, key)
+}
+
+func (i *ifIndexer[T]) decode(key []byte) ([]any, uint64, bool) {
+	subIndexer, ok := i.subIndexer.(keyDecoder)
+	if !ok {
+		return nil, 0, false
+	}
+	return subIndexer.decode(key)
+}
@@ -127,9 +127,9 @@ func TestEntityUpdateWithIfIndex(t *testing.T) {
 	requireT := require.New(t)
 
 	var v o
-	index := NewIfIndex(NewFieldIndex(&v, &v.Value1), func(v *o) bool {
+	index := NewNamedIndex("value1IfOne", NewIfIndex(NewFieldIndex(&v, &v.Value1), func(v *o) bool {
 		return v.Value1 == 1
-	})
+	}))
 
 	c := memdb.Config{
 		Entities: []reflect.Type{reflect.TypeFor[o]()},
@@ -146,18 +146,18 @@ func TestEntityUpdateWithIfIndex(t *testing.T) {
 		Value1: 1,
 	}
 
-	old, err := txn.Insert(0, unsafe.Pointer(e))
+	old, err := txn.Insert(oTableID, unsafe.Pointer(e))
 	requireT.NoError(err)
 	requireT.Zero(old)
-	txn.Commit()
+	requireT.NoError(txn.Commit())
 
 	txn = db.Txn(true)
-	e2, err := txn.First(0, memdb.IDIndexID, eID)
+	e2, err := txn.First(oTableID, memdb.IDIndexID, eID)
 	requireT.NoError(err)
 	requireT.NotZero(e2)
 	requireT.Equal(e, (*o)(e2))
 
-	e3, err := txn.First(0, index.ID(), uint64(1))
+	e3, err := txn.First(oTableID, index.ID(), uint64(1))
 	requireT.NoError(err)
 	requireT.NotZero(e3)
 	requireT.Equal(e2, e3)
@@ -167,23 +167,23 @@ func TestEntityUpdateWithIfIndex(t *testing.T) {
 		Value1: 2,
 	}
 
-	old, err = txn.Insert(0, unsafe.Pointer(e4))
+	old, err = txn.Insert(oTableID, unsafe.Pointer(e4))
 	requireT.NoError(err)
 	requireT.NotZero(old)
 	requireT.Equal(e, (*o)(old))
-	txn.Commit()
+	requireT.NoError(txn.Commit())
 
 	txn = db.Txn(false)
-	e2, err = txn.First(0, memdb.IDIndexID, eID)
+	e2, err = txn.First(oTableID, memdb.IDIndexID, eID)
 	requireT.NoError(err)
 	requireT.NotZero(e2)
 	requireT.Equal(e4, (*o)(e2))
 
-	e3, err = txn.First(0, index.ID(), uint64(2))
+	e3, err = txn.First(oTableID, index.ID(), uint64(2))
 	requireT.NoError(err)
 	requireT.Zero(e3)
 
-	e3, err = txn.First(0, index.ID(), uint64(1))
+	e3, err = txn.First(oTableID, index.ID(), uint64(1))
 	requireT.NoError(err)
 	requireT.Zero(e3)
 }
@@ -2,6 +2,7 @@ package indices
 
 import (
 	"reflect"
+	"strings"
 	"unsafe"
 
 	"github.com/pkg/errors"
@@ -12,14 +13,16 @@ import (
 // MultiIndex compiles many indices into a single one.
 type MultiIndex[T any] struct {
 	id      uint64
-	indexer *multiIndexer[T]
+	name    string
+	indexer *multiIndexer
 	unique  bool
 }
 
 // NewMultiIndex creates new multiindex.
 func NewMultiIndex[T any](subIndices ...Index[T]) *MultiIndex[T] {
 	var _ Index[T] = (*MultiIndex[T])(nil)
-	var _ memdb.Indexer = (*multiIndexer[T])(nil)
+	var _ memdb.FieldIndexer = (*multiIndexer)(nil)
+	var _ memdb.DecodingIndexer = (*multiIndexer)(nil)
 
 	if len(subIndices) == 0 {
 		panic(errors.Errorf("no subindices has been provided"))
@@ -28,7 +31,9 @@ func NewMultiIndex[T any](subIndices ...Index[T]) *MultiIndex[T] {
 	var unique bool
 	var args []memdb.ArgSerializer
 	subIndexers := make([]memdb.Indexer, 0, len(subIndices))
+	names := make([]string, 0, len(subIndices))
 	for _, si := range subIndices {
+		names = append(names, si.Name())
 		schema := si.Schema()
 		subIndexers = append(subIndexers, schema.Indexer)
 		unique = unique || schema.Unique
@@ -36,13 +41,14 @@ func NewMultiIndex[T any](subIndices ...Index[T]) *MultiIndex[T] {
 	}
 
 	index := &MultiIndex[T]{
-		indexer: &multiIndexer[T]{
+		name: "multi(" + strings.Join(names, ",") + ")",
+		indexer: &multiIndexer{
 			subIndexers: subIndexers,
 			args:        args,
 		},
 		unique: unique,
 	}
-	index.id = uint64(uintptr(unsafe.Pointer(index)))
+	index.id = memdb.IndexID(index.name)
 	return index
 }
 
@@ -51,6 +57,11 @@ func (i *MultiIndex[T]) ID() uint64 {
 	return i.id
 }
 
+// Name returns name of the index.
+func (i *MultiIndex[T]) Name() string {
+	return i.name
+}
+
 // Schema returns memdb index schema.
 func (i *MultiIndex[T]) Schema() *memdb.IndexSchema {
 	return &memdb.IndexSchema{
@@ -68,16 +79,33 @@ func (i *MultiIndex[T]) dummyTDefiner(t T) {
 	panic("it should never be called")
 }
 
-type multiIndexer[T any] struct {
+type multiIndexer struct {
 	subIndexers []memdb.Indexer
 	args        []memdb.ArgSerializer
 }
 
-func (mi *multiIndexer[T]) Args() []memdb.ArgSerializer {
+func (mi *multiIndexer) Args() []memdb.ArgSerializer {
 	return mi.args
 }
 
-func (mi *multiIndexer[T]) SizeFromObject(o unsafe.Pointer) uint64 {
+func (mi *multiIndexer) Fields() []memdb.IndexField {
+	// Other subindexers might skip the entity, so fields are returned only if all the components are fields.
+	var fields []memdb.IndexField
+	for _, si := range mi.subIndexers {
+		fieldIndexer, ok := si.(memdb.FieldIndexer)
+		if !ok {
+			return nil
+		}
+		subFields := fieldIndexer.Fields()
+		if len(subFields) != len(si.Args()) {
+			return nil
+		}
+		fields = append(fields, subFields...)
+	}
+	return fields
+}
+
+func (mi *multiIndexer) SizeFromObject(o unsafe.Pointer) uint64 {
 	var size uint64
 	for _, si := range mi.subIndexers {
 		s := si.SizeFromObject(o)
@@ -89,10 +117,32 @@ func (mi *multiIndexer[T]) SizeFromObject(o unsafe.Pointer) uint64 {
 	return size
 }
 
-func (mi *multiIndexer[T]) FromObject(b []byte, o unsafe.Pointer) uint64 {
+func (mi *multiIndexer) FromObject(b []byte, o unsafe.Pointer) uint64 {
 	var n uint64
 	for _, si := range mi.subIndexers {
 		n += si.FromObject(b[n:], o)
 	}
 	return n
 }
+
+func (mi *multiIndexer) Decode(key []byte) []any {
+	return decodeKey(mi, key)
+}
+
+func (mi *multiIndexer) decode(key []byte) ([]any, uint64, bool) {
+	values := make([]any, 0, len(mi.args))
+	var n uint64
+	for _, si := range mi.subIndexers {
+		subIndexer, ok := si.(keyDecoder)
+		if !ok {
+			return nil, 0, false
+		}
+		v, m, ok := subIndexer.decode(key[n:])
+		if !ok {
+			return nil, 0, false
+		}
+		values = append(values, v...)
+		n += m
+	}
+	return values, n, true
+}
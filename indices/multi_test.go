@@ -3,8 +3,11 @@ package indices
 import (
 	"reflect"
 	"testing"
+	"unsafe"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/outofforest/memdb"
 )
 
 const (
@@ -39,7 +42,7 @@ func TestMultiIndexer(t *testing.T) {
 	requireT.NotZero(index.ID())
 	requireT.False(index.Schema().Unique)
 
-	indexer := index.Schema().Indexer.(*multiIndexer[o])
+	indexer := index.Schema().Indexer.(*multiIndexer)
 	requireT.Len(indexer.Args(), 2)
 
 	v.Value1 = 5
@@ -58,7 +61,7 @@ func TestMultiIndexerNotAllArguments(t *testing.T) {
 
 	index := NewMultiIndex(index1, index2)
 
-	indexer := index.Schema().Indexer.(*multiIndexer[o])
+	indexer := index.Schema().Indexer.(*multiIndexer)
 
 	v.Value1 = 5
 	v.Value4 = xyz
@@ -79,7 +82,7 @@ func TestMultiIndexerWithMultiSubIndexer3Arguments(t *testing.T) {
 	index := NewMultiIndex(index3, index4)
 	requireT.NotZero(index.ID())
 
-	indexer := index.Schema().Indexer.(*multiIndexer[o])
+	indexer := index.Schema().Indexer.(*multiIndexer)
 	requireT.Len(indexer.Args(), 3)
 
 	v.Value1 = 5
@@ -101,7 +104,7 @@ func TestMultiIndexerWithMultiSubIndexer2Arguments(t *testing.T) {
 	index4 := NewMultiIndex(index1, index2)
 
 	index := NewMultiIndex(index3, index4)
-	indexer := index.Schema().Indexer.(*multiIndexer[o])
+	indexer := index.Schema().Indexer.(*multiIndexer)
 
 	v.Value1 = 5
 	v.Value4 = xyz
@@ -123,7 +126,7 @@ func TestMultiIndexerWithMultiSubIndexer1Argument(t *testing.T) {
 
 	index := NewMultiIndex(index3, index4)
 
-	indexer := index.Schema().Indexer.(*multiIndexer[o])
+	indexer := index.Schema().Indexer.(*multiIndexer)
 
 	v.Value1 = 5
 	v.Value4 = xyz
@@ -144,7 +147,7 @@ func TestMultiIndexerWithIfSubindex(t *testing.T) {
 	index := NewMultiIndex(index1, index3)
 	requireT.NotZero(index.ID())
 
-	indexer := index.Schema().Indexer.(*multiIndexer[o])
+	indexer := index.Schema().Indexer.(*multiIndexer)
 	requireT.Len(indexer.Args(), 2)
 
 	v.Value1 = 1
@@ -175,7 +178,7 @@ func TestMultiIndexerWithUniqueSubindex(t *testing.T) {
 	requireT.NotZero(index.ID())
 	requireT.True(index.Schema().Unique)
 
-	indexer := index.Schema().Indexer.(*multiIndexer[o])
+	indexer := index.Schema().Indexer.(*multiIndexer)
 	requireT.Len(indexer.Args(), 2)
 
 	v.Value1 = 1
@@ -196,3 +199,69 @@ func TestMultiErrorIfNoSubIndices(t *testing.T) {
 		NewMultiIndex[o]()
 	})
 }
+
+type compositeO struct {
+	Tenant uint64
+	Name   string
+	Value  uint64
+}
+
+func TestEntityWithCompositePrimaryKey(t *testing.T) {
+	requireT := require.New(t)
+
+	var v compositeO
+	pk := NewMultiIndex(NewFieldIndex(&v, &v.Tenant), NewFieldIndex(&v, &v.Name))
+	index := NewFieldIndex(&v, &v.Value)
+
+	db, err := memdb.NewMemDB(memdb.Config{
+		Entities:    []reflect.Type{reflect.TypeFor[compositeO]()},
+		Indices:     []memdb.Index{index},
+		PrimaryKeys: []memdb.Index{pk},
+	})
+	requireT.NoError(err)
+	tableID := memdb.TableID(reflect.TypeFor[compositeO]())
+
+	e1 := &compositeO{Tenant: 1, Name: abc, Value: 1}
+	e2 := &compositeO{Tenant: 1, Name: def, Value: 1}
+	e3 := &compositeO{Tenant: 2, Name: abc, Value: 2}
+
+	txn := db.Txn(true)
+	for _, e := range []*compositeO{e1, e2, e3} {
+		old, err := txn.Insert(tableID, unsafe.Pointer(e))
+		requireT.NoError(err)
+		requireT.Nil(old)
+	}
+
+	e4 := &compositeO{Tenant: 1, Name: def, Value: 2}
+	old, err := txn.Insert(tableID, unsafe.Pointer(e4))
+	requireT.NoError(err)
+	requireT.Equal(e2, (*compositeO)(old))
+	requireT.NoError(txn.Commit())
+
+	collect := func(txn *memdb.Txn, index uint64, args ...any) []*compositeO {
+		it, err := txn.Iterator(tableID, index, args...)
+		requireT.NoError(err)
+		var result []*compositeO
+		for e := it.Next(); e != nil; e = it.Next() {
+			result = append(result, (*compositeO)(e))
+		}
+		return result
+	}
+
+	txn = db.Txn(true)
+	e, err := txn.First(tableID, memdb.IDIndexID, uint64(1), def)
+	requireT.NoError(err)
+	requireT.Equal(e4, (*compositeO)(e))
+	requireT.Equal([]*compositeO{e1, e4}, collect(txn, memdb.IDIndexID, uint64(1)))
+	requireT.Equal([]*compositeO{e1}, collect(txn, index.ID(), uint64(1)))
+	requireT.Equal([]*compositeO{e4, e3}, collect(txn, index.ID(), uint64(2)))
+
+	old, err = txn.Delete(tableID, unsafe.Pointer(&compositeO{Tenant: 2, Name: abc}))
+	requireT.NoError(err)
+	requireT.Equal(e3, (*compositeO)(old))
+	requireT.NoError(txn.Commit())
+
+	txn = db.Txn(false)
+	requireT.Equal([]*compositeO{e1, e4}, collect(txn, memdb.IDIndexID))
+	requireT.Equal([]*compositeO{e4}, collect(txn, index.ID(), uint64(2)))
+}
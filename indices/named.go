@@ -0,0 +1,49 @@
+package indices
+
+import (
+	"reflect"
+
+	"github.com/outofforest/memdb"
+)
+
+// NamedIndex overrides the name, and by that the ID, of the subindex.
+type NamedIndex[T any] struct {
+	id       uint64
+	name     string
+	subIndex Index[T]
+}
+
+// NewNamedIndex creates new index with the given name.
+func NewNamedIndex[T any](name string, subIndex Index[T]) *NamedIndex[T] {
+	var _ Index[T] = (*NamedIndex[T])(nil)
+
+	return &NamedIndex[T]{
+		id:       memdb.IndexID(name),
+		name:     name,
+		subIndex: subIndex,
+	}
+}
+
+// ID returns ID of the index.
+func (i *NamedIndex[T]) ID() uint64 {
+	return i.id
+}
+
+// Name returns name of the index.
+func (i *NamedIndex[T]) Name() string {
+	return i.name
+}
+
+// Schema returns memdb index schema.
+func (i *NamedIndex[T]) Schema() *memdb.IndexSchema {
+	return i.subIndex.Schema()
+}
+
+// Type returns type of entity index is created for.
+func (i *NamedIndex[T]) Type() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+func (i *NamedIndex[T]) dummyTDefiner(t T) {
+	panic("it should never be called")
+}
@@ -0,0 +1,144 @@
+package indices
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/outofforest/memdb"
+)
+
+func namedTestPredicate(v *o) bool {
+	return v.Value1 == 1
+}
+
+func namedTestKey(v *o) *uint64 {
+	return &v.Value1
+}
+
+func TestIndexNames(t *testing.T) {
+	t.Parallel()
+
+	requireT := require.New(t)
+	var v o
+
+	index1 := NewFieldIndex(&v, &v.Value1)
+	index2 := NewFieldIndex(&v, &v.Value2.Value2.ValueString)
+
+	const pkg = "github.com/outofforest/memdb/indices."
+
+	testCases := []struct {
+		index memdb.Index
+		name  string
+	}{
+		{index: index1, name: "Value1"},
+		{index: index2, name: "Value2.Value2.ValueString"},
+		{index: NewMultiIndex(index1, index2), name: "multi(Value1,Value2.Value2.ValueString)"},
+		{index: NewUniqueIndex(index1), name: "unique(Value1)"},
+		{index: NewReverseIndex(index1), name: "reverse(Value1)"},
+		{index: NewIfIndex(index1, namedTestPredicate), name: "if(Value1," + pkg + "namedTestPredicate)"},
+		{index: NewFuncIndex(Key(namedTestKey)), name: "func(" + pkg + "namedTestKey)"},
+		{index: NewFuncIndex(Desc(Key(namedTestKey))), name: "func(desc(" + pkg + "namedTestKey))"},
+		{index: NewFuncIndex1(namedTestKey), name: "func(" + pkg + "namedTestKey)"},
+		{index: NewCustomIndex[o]("custom", memdb.IDIndexer{}, false), name: "custom"},
+		{index: NewNamedIndex("named", index1), name: "named"},
+	}
+
+	for _, tc := range testCases {
+		requireT.Equal(tc.name, tc.index.Name())
+		requireT.Equal(memdb.IndexID(tc.name), tc.index.ID())
+	}
+
+	// IDs do not depend on the index object.
+	requireT.Equal(index1.ID(), NewFieldIndex(&v, &v.Value1).ID())
+}
+
+func TestNamedIndex(t *testing.T) {
+	t.Parallel()
+
+	requireT := require.New(t)
+	var v o
+
+	subIndex := NewUniqueIndex(NewFieldIndex(&v, &v.Value1))
+	index := NewNamedIndex("byValue1", subIndex)
+
+	requireT.Equal(reflect.TypeFor[o](), index.Type())
+	requireT.NotEqual(subIndex.ID(), index.ID())
+	requireT.True(index.Schema().Unique)
+	requireT.Equal(subIndex.Schema().Indexer, index.Schema().Indexer)
+}
+
+func TestDuplicatedIndexNames(t *testing.T) {
+	t.Parallel()
+
+	requireT := require.New(t)
+	var v o
+
+	_, err := memdb.NewMemDB(memdb.Config{
+		Entities: []reflect.Type{reflect.TypeFor[o]()},
+		Indices: []memdb.Index{
+			NewFieldIndex(&v, &v.Value1),
+			NewFieldIndex(&v, &v.Value1),
+		},
+	})
+	requireT.ErrorContains(err, `duplicated index "Value1"`)
+
+	_, err = memdb.NewMemDB(memdb.Config{
+		Entities: []reflect.Type{reflect.TypeFor[o]()},
+		Indices: []memdb.Index{
+			NewFieldIndex(&v, &v.Value1),
+			NewNamedIndex("Value1", NewFieldIndex(&v, &v.Value4)),
+		},
+	})
+	requireT.ErrorContains(err, `duplicated index "Value1"`)
+
+	_, err = memdb.NewMemDB(memdb.Config{
+		Entities: []reflect.Type{reflect.TypeFor[o]()},
+		Indices: []memdb.Index{
+			NewFieldIndex(&v, &v.Value1),
+			NewNamedIndex("byValue1", NewFieldIndex(&v, &v.Value1)),
+		},
+	})
+	requireT.NoError(err)
+}
+
+func TestAnonymousFuncIndexNames(t *testing.T) {
+	t.Parallel()
+
+	requireT := require.New(t)
+	var v o
+
+	keyFunc := func(v *o) *uint64 {
+		return &v.Value1
+	}
+	testCases := []Index[o]{
+		NewFuncIndex(Key(keyFunc)),
+		NewFuncIndex1(keyFunc),
+		NewUniqueIndex(NewFuncIndex(Key(namedTestKey), Key(keyFunc))),
+		NewIfIndex(NewFieldIndex(&v, &v.Value1), func(v *o) bool { return true }),
+	}
+
+	for _, index := range testCases {
+		_, err := memdb.NewMemDB(memdb.Config{
+			Entities: []reflect.Type{reflect.TypeFor[o]()},
+			Indices:  []memdb.Index{index},
+		})
+		requireT.ErrorContains(err, "is named after anonymous function")
+	}
+
+	// Index is accepted once it is named explicitly.
+	for _, index := range testCases {
+		_, err := memdb.NewMemDB(memdb.Config{
+			Entities: []reflect.Type{reflect.TypeFor[o]()},
+			Indices:  []memdb.Index{NewNamedIndex("named", index)},
+		})
+		requireT.NoError(err)
+	}
+
+	_, err := memdb.NewMemDB(memdb.Config{
+		Entities: []reflect.Type{reflect.TypeFor[o]()},
+		Indices:  []memdb.Index{NewFuncIndex(Key(namedTestKey))},
+	})
+	requireT.NoError(err)
+}
@@ -10,6 +10,7 @@ import (
 // ReverseIndex reverses the order of elements in the index by reversing all the bits of the index key.
 type ReverseIndex[T any] struct {
 	id       uint64
+	name     string
 	subIndex Index[T]
 	indexer  *reverseIndexer
 	unique   bool
@@ -25,11 +26,12 @@ func NewReverseIndex[T any](subIndex Index[T]) *ReverseIndex[T] {
 	}
 	indexer.args = []memdb.ArgSerializer{indexer}
 	index := &ReverseIndex[T]{
+		name:     "reverse(" + subIndex.Name() + ")",
 		subIndex: subIndex,
 		indexer:  indexer,
 		unique:   schema.Unique,
 	}
-	index.id = uint64(uintptr(unsafe.Pointer(index)))
+	index.id = memdb.IndexID(index.name)
 	return index
 }
 
@@ -38,6 +40,11 @@ func (i *ReverseIndex[T]) ID() uint64 {
 	return i.id
 }
 
+// Name returns name of the index.
+func (i *ReverseIndex[T]) Name() string {
+	return i.name
+}
+
 // Schema returns memdb index schema.
 func (i *ReverseIndex[T]) Schema() *memdb.IndexSchema {
 	return &memdb.IndexSchema{
@@ -55,8 +62,9 @@ func (i *ReverseIndex[T]) dummyTDefiner(t T) {
 	panic("it should never be called")
 }
 
-var _ memdb.Indexer = &reverseIndexer{}
+var _ memdb.FieldIndexer = &reverseIndexer{}
 var _ memdb.ArgSerializer = &reverseIndexer{}
+var _ memdb.DecodingIndexer = &reverseIndexer{}
 
 type reverseIndexer struct {
 	subIndexer memdb.ArgSerializerIndexer
@@ -67,6 +75,20 @@ func (i *reverseIndexer) Args() []memdb.ArgSerializer {
 	return i.args
 }
 
+func (i *reverseIndexer) Fields() []memdb.IndexField {
+	subIndexer, ok := i.subIndexer.(memdb.FieldIndexer)
+	if !ok {
+		return nil
+	}
+	fields := subIndexer.Fields()
+	result := make([]memdb.IndexField, 0, len(fields))
+	for _, f := range fields {
+		f.Desc = !f.Desc
+		result = append(result, f)
+	}
+	return result
+}
+
 func (i *reverseIndexer) SizeFromObject(o unsafe.Pointer) uint64 {
 	return i.subIndexer.SizeFromObject(o)
 }
@@ -87,6 +109,34 @@ func (i *reverseIndexer) FromObject(b []byte, o unsafe.Pointer) uint64 {
 	return n
 }
 
+func (i *reverseIndexer) Decode(key []byte) []any {
+	return decodeKey(i, key)
+}
+
+func (i *reverseIndexer) decode(key []byte) ([]any, uint64, bool) {
+	subIndexer, ok := i.subIndexer.(keyDecoder)
+	if !ok {
+		return nil, 0, false
+	}
+	return decodeNegated(subIndexer, key)
+}
+
+var _ memdb.ArgSerializer = &reverseArgSerializer{}
+
+type reverseArgSerializer struct {
+	subArg memdb.ArgSerializer
+}
+
+func (a *reverseArgSerializer) SizeFromArg(arg any) uint64 {
+	return a.subArg.SizeFromArg(arg)
+}
+
+func (a *reverseArgSerializer) FromArg(b []byte, arg any) uint64 {
+	n := a.subArg.FromArg(b, arg)
+	negate(b[:n])
+	return n
+}
+
 func negate(b []byte) {
 	if len(b) == 0 {
 		return
@@ -0,0 +1,73 @@
+package indices
+
+import (
+	"reflect"
+	"unsafe"
+
+	"github.com/outofforest/memdb"
+)
+
+// SequenceIndex is the primary key index taking values from the per-table sequence.
+// Entities inserted with zero value in the field get the next value of the sequence assigned.
+type SequenceIndex[T any] struct {
+	id      uint64
+	name    string
+	indexer *sequenceIndexer
+}
+
+// NewSequenceIndex defines new sequence index. It is meant to be used in memdb.Config.PrimaryKeys.
+func NewSequenceIndex[T any, F ~uint64](ePtr *T, fieldPtr *F) *SequenceIndex[T] {
+	var _ Index[T] = (*SequenceIndex[T])(nil)
+	var _ memdb.SequenceIndexer = (*sequenceIndexer)(nil)
+
+	offset, name := fieldOffset(ePtr, fieldPtr)
+	indexer := &sequenceIndexer{
+		uint64Indexer: uint64Indexer{offset: offset},
+	}
+	indexer.args = []memdb.ArgSerializer{indexer}
+	index := &SequenceIndex[T]{
+		name:    "sequence(" + name + ")",
+		indexer: indexer,
+	}
+	index.id = memdb.IndexID(index.name)
+	return index
+}
+
+// ID returns ID of the index.
+func (i *SequenceIndex[T]) ID() uint64 {
+	return i.id
+}
+
+// Name returns name of the index.
+func (i *SequenceIndex[T]) Name() string {
+	return i.name
+}
+
+// Schema returns memdb index schema.
+func (i *SequenceIndex[T]) Schema() *memdb.IndexSchema {
+	return &memdb.IndexSchema{
+		Unique:  true,
+		Indexer: i.indexer,
+	}
+}
+
+// Type returns type of entity index is created for.
+func (i *SequenceIndex[T]) Type() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+func (i *SequenceIndex[T]) dummyTDefiner(t T) {
+	panic("it should never be called")
+}
+
+type sequenceIndexer struct {
+	uint64Indexer
+}
+
+func (i *sequenceIndexer) Sequence(o unsafe.Pointer) uint64 {
+	return valueByOffset[uint64](o, i.offset)
+}
+
+func (i *sequenceIndexer) SetSequence(o unsafe.Pointer, v uint64) {
+	*(*uint64)(unsafe.Add(o, i.offset)) = v
+}
@@ -0,0 +1,91 @@
+package indices
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/outofforest/memdb"
+)
+
+type sequenceO struct {
+	Name string
+	ID   uint64
+}
+
+func TestSequenceIndexer(t *testing.T) {
+	t.Parallel()
+
+	requireT := require.New(t)
+	v := &sequenceO{}
+
+	index := NewSequenceIndex(v, &v.ID)
+	requireT.Equal(reflect.TypeFor[sequenceO](), index.Type())
+	requireT.True(index.Schema().Unique)
+
+	indexer := index.Schema().Indexer.(memdb.SequenceIndexer)
+	requireT.Len(indexer.Args(), 1)
+
+	v.ID = 2
+	verifyObject(requireT, indexer, []byte{0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x2}, v)
+	requireT.EqualValues(2, indexer.Sequence(unsafe.Pointer(v)))
+
+	indexer.SetSequence(unsafe.Pointer(v), 5)
+	requireT.EqualValues(5, v.ID)
+}
+
+func TestEntityWithSequence(t *testing.T) {
+	requireT := require.New(t)
+
+	var v sequenceO
+	db, err := memdb.NewMemDB(memdb.Config{
+		Entities:    []reflect.Type{reflect.TypeFor[sequenceO]()},
+		PrimaryKeys: []memdb.Index{NewSequenceIndex(&v, &v.ID)},
+	})
+	requireT.NoError(err)
+	tableID := memdb.TableID(reflect.TypeFor[sequenceO]())
+
+	insert := func(txn *memdb.Txn, e *sequenceO) uint64 {
+		_, err := txn.Insert(tableID, unsafe.Pointer(e))
+		requireT.NoError(err)
+		return e.ID
+	}
+
+	txn := db.Txn(true)
+	requireT.EqualValues(1, insert(txn, &sequenceO{Name: abc}))
+	requireT.EqualValues(2, insert(txn, &sequenceO{Name: def}))
+	requireT.EqualValues(10, insert(txn, &sequenceO{Name: xyz, ID: 10}))
+	requireT.EqualValues(11, insert(txn, &sequenceO{Name: ijk}))
+	requireT.EqualValues(5, insert(txn, &sequenceO{Name: ijk, ID: 5}))
+	requireT.EqualValues(12, insert(txn, &sequenceO{Name: ijk}))
+	requireT.NoError(txn.Commit())
+
+	// Sequence is rolled back together with uncommitted transaction.
+	txn = db.Txn(true)
+	requireT.EqualValues(13, insert(txn, &sequenceO{Name: abc}))
+	requireT.EqualValues(14, insert(txn, &sequenceO{Name: abc}))
+
+	subTxn := txn.Txn(true)
+	requireT.EqualValues(15, insert(subTxn, &sequenceO{Name: abc}))
+
+	requireT.EqualValues(15, insert(txn, &sequenceO{Name: abc}))
+
+	txn = db.Txn(true)
+	requireT.EqualValues(13, insert(txn, &sequenceO{Name: def}))
+	requireT.NoError(txn.Commit())
+
+	txn = db.Txn(false)
+	it, err := txn.Iterator(tableID, memdb.IDIndexID)
+	requireT.NoError(err)
+	var ids []uint64
+	for e := it.Next(); e != nil; e = it.Next() {
+		ids = append(ids, (*sequenceO)(e).ID)
+	}
+	requireT.Equal([]uint64{1, 2, 5, 10, 11, 12, 13}, ids)
+
+	e, err := txn.First(tableID, memdb.IDIndexID, uint64(13))
+	requireT.NoError(err)
+	requireT.Equal(def, (*sequenceO)(e).Name)
+}
@@ -0,0 +1,97 @@
+package indices
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+
+	"github.com/outofforest/memdb"
+)
+
+func init() {
+	memdb.RegisterTaggedIndexFunc(newTaggedIndex)
+}
+
+type taggedIndex struct {
+	id      uint64
+	name    string
+	eType   reflect.Type
+	indexer memdb.Indexer
+	unique  bool
+}
+
+func newTaggedIndex(eType reflect.Type, name string, unique bool, fields []memdb.TaggedField) (memdb.Index, error) {
+	var _ memdb.Index = (*taggedIndex)(nil)
+
+	subIndexers := make([]memdb.Indexer, 0, len(fields))
+	var args []memdb.ArgSerializer
+	for _, f := range fields {
+		if !isFieldTypeSupported(f.Type) {
+			return nil, errors.Errorf("field %s: unsupported type %s", f.Name, f.Type)
+		}
+
+		indexer := indexerForType(f.Type, f.Offset).(memdb.ArgSerializerIndexer)
+		if f.Desc {
+			ri := &reverseIndexer{
+				subIndexer: indexer,
+			}
+			ri.args = []memdb.ArgSerializer{ri}
+			indexer = ri
+		}
+		subIndexers = append(subIndexers, indexer)
+		args = append(args, indexer.Args()...)
+	}
+
+	index := &taggedIndex{
+		name:   name,
+		eType:  eType,
+		unique: unique,
+	}
+	if len(subIndexers) == 1 {
+		index.indexer = subIndexers[0]
+	} else {
+		index.indexer = &multiIndexer{
+			subIndexers: subIndexers,
+			args:        args,
+		}
+	}
+	index.id = memdb.IndexID(index.name)
+	return index, nil
+}
+
+// ID returns ID of the index.
+func (i *taggedIndex) ID() uint64 {
+	return i.id
+}
+
+// Name returns name of the index.
+func (i *taggedIndex) Name() string {
+	return i.name
+}
+
+// Schema returns memdb index schema.
+func (i *taggedIndex) Schema() *memdb.IndexSchema {
+	return &memdb.IndexSchema{
+		Unique:  i.unique,
+		Indexer: i.indexer,
+	}
+}
+
+// Type returns type of entity index is created for.
+func (i *taggedIndex) Type() reflect.Type {
+	return i.eType
+}
+
+func isFieldTypeSupported(t reflect.Type) bool {
+	if (t.Kind() == reflect.Array && t.ConvertibleTo(idType)) || t.ConvertibleTo(timeType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	default:
+		return false
+	}
+}
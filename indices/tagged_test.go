@@ -0,0 +1,135 @@
+package indices
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/outofforest/memdb"
+)
+
+type tagged struct {
+	ID    memdb.ID
+	Email string `memdb:"unique"`
+	Owner uint64 `memdb:"index:byOwner,order:2"`
+	Kind  uint8  `memdb:"index:byOwner,order:1;index:byKind"`
+	Score int32  `memdb:"desc"`
+	Other uint64
+}
+
+func TestSchemaFromTypes(t *testing.T) {
+	t.Parallel()
+
+	requireT := require.New(t)
+
+	s, err := memdb.SchemaFromTypes(reflect.TypeFor[tagged]())
+	requireT.NoError(err)
+	requireT.Equal([]reflect.Type{reflect.TypeFor[tagged]()}, s.Config.Entities)
+	requireT.Len(s.Config.Indices, 4)
+
+	email := s.Index(reflect.TypeFor[tagged](), "Email")
+	byOwner := s.Index(reflect.TypeFor[tagged](), "byOwner")
+	byKind := s.Index(reflect.TypeFor[tagged](), "byKind")
+	score := s.Index(reflect.TypeFor[tagged](), "Score")
+	requireT.Equal([]memdb.Index{email, byOwner, byKind, score}, s.Config.Indices)
+	requireT.Nil(s.Index(reflect.TypeFor[tagged](), "Other"))
+	requireT.Nil(s.Index(reflect.TypeFor[o](), "Email"))
+
+	for _, index := range s.Config.Indices {
+		requireT.Equal(reflect.TypeFor[tagged](), index.Type())
+	}
+
+	requireT.True(email.Schema().Unique)
+	requireT.False(byOwner.Schema().Unique)
+	requireT.False(byKind.Schema().Unique)
+	requireT.False(score.Schema().Unique)
+
+	v := &tagged{
+		Email: "A",
+		Owner: 2,
+		Kind:  3,
+		Score: 1,
+	}
+
+	verifyObject(requireT, email.Schema().Indexer, []byte{0x41, 0x0}, v)
+	verifyObject(requireT, byOwner.Schema().Indexer,
+		[]byte{0x3, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x2}, v)
+	verifyObject(requireT, byKind.Schema().Indexer, []byte{0x3}, v)
+	verifyObject(requireT, score.Schema().Indexer, []byte{0x7f, 0xff, 0xff, 0xfe}, v)
+
+	requireT.Len(byOwner.Schema().Indexer.Args(), 2)
+}
+
+func TestSchemaFromTypesErrors(t *testing.T) {
+	t.Parallel()
+
+	requireT := require.New(t)
+
+	type unsupported struct {
+		ID    memdb.ID
+		Value int `memdb:"index"`
+	}
+
+	type invalidTag struct {
+		ID    memdb.ID
+		Value uint64 `memdb:"order:x"`
+	}
+
+	_, err := memdb.SchemaFromTypes(reflect.TypeFor[unsupported]())
+	requireT.ErrorContains(err, "field Value: unsupported type int")
+
+	_, err = memdb.SchemaFromTypes(reflect.TypeFor[invalidTag]())
+	requireT.ErrorContains(err, "field Value: invalid order")
+
+	_, err = memdb.SchemaFromTypes(reflect.TypeFor[uint64]())
+	requireT.ErrorContains(err, "is not a struct")
+
+	_, err = memdb.SchemaFromTypes(reflect.TypeFor[tagged](), reflect.TypeFor[tagged]())
+	requireT.ErrorContains(err, "duplicated entity")
+}
+
+func TestEntityWithSchemaFromTypes(t *testing.T) {
+	requireT := require.New(t)
+
+	s, err := memdb.SchemaFromTypes(reflect.TypeFor[tagged]())
+	requireT.NoError(err)
+
+	db, err := memdb.NewMemDB(s.Config)
+	requireT.NoError(err)
+	tableID := memdb.TableID(reflect.TypeFor[tagged]())
+
+	email := s.Index(reflect.TypeFor[tagged](), "Email")
+	byOwner := s.Index(reflect.TypeFor[tagged](), "byOwner")
+	score := s.Index(reflect.TypeFor[tagged](), "Score")
+
+	e1 := &tagged{ID: memdb.ID{1}, Email: "a", Owner: 1, Kind: 1, Score: 1}
+	e2 := &tagged{ID: memdb.ID{2}, Email: "b", Owner: 2, Kind: 1, Score: 2}
+	e3 := &tagged{ID: memdb.ID{3}, Email: "c", Owner: 1, Kind: 2, Score: 3}
+
+	txn := db.Txn(true)
+	for _, e := range []*tagged{e1, e2, e3} {
+		_, err := txn.Insert(tableID, unsafe.Pointer(e))
+		requireT.NoError(err)
+	}
+	requireT.NoError(txn.Commit())
+
+	txn = db.Txn(false)
+
+	e, err := txn.First(tableID, email.ID(), "b")
+	requireT.NoError(err)
+	requireT.Equal(e2, (*tagged)(e))
+
+	e, err = txn.First(tableID, byOwner.ID(), uint8(1), uint64(2))
+	requireT.NoError(err)
+	requireT.Equal(e2, (*tagged)(e))
+
+	it, err := txn.Iterator(tableID, score.ID())
+	requireT.NoError(err)
+	var result []*tagged
+	for e := it.Next(); e != nil; e = it.Next() {
+		result = append(result, (*tagged)(e))
+	}
+	requireT.Equal([]*tagged{e3, e2, e1}, result)
+}
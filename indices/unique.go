@@ -2,7 +2,6 @@ package indices
 
 import (
 	"reflect"
-	"unsafe"
 
 	"github.com/outofforest/memdb"
 )
@@ -10,6 +9,7 @@ import (
 // UniqueIndex marks the subindex definition as unique.
 type UniqueIndex[T any] struct {
 	id       uint64
+	name     string
 	subIndex Index[T]
 	indexer  memdb.Indexer
 }
@@ -19,10 +19,11 @@ func NewUniqueIndex[T any](subIndex Index[T]) *UniqueIndex[T] {
 	var _ Index[T] = (*UniqueIndex[T])(nil)
 
 	index := &UniqueIndex[T]{
+		name:     "unique(" + subIndex.Name() + ")",
 		subIndex: subIndex,
 		indexer:  subIndex.Schema().Indexer,
 	}
-	index.id = uint64(uintptr(unsafe.Pointer(index)))
+	index.id = memdb.IndexID(index.name)
 	return index
 }
 
@@ -31,6 +32,11 @@ func (i *UniqueIndex[T]) ID() uint64 {
 	return i.id
 }
 
+// Name returns name of the index.
+func (i *UniqueIndex[T]) Name() string {
+	return i.name
+}
+
 // Schema returns memdb index schema.
 func (i *UniqueIndex[T]) Schema() *memdb.IndexSchema {
 	return &memdb.IndexSchema{
@@ -0,0 +1,65 @@
+package memdb
+
+import (
+	"time"
+	"unsafe"
+)
+
+// Instrumentation receives events of the database, so metrics and traces might be collected without wrapping
+// each call site. Methods are called synchronously by the goroutine running the transaction, so they must be cheap
+// and safe for concurrent use.
+type Instrumentation interface {
+	// TxnStarted is called when top-level transaction is started.
+	TxnStarted(write bool)
+
+	// TxnCommitted is called when top-level transaction is committed. Duration is the time taken by Commit,
+	// including updating views and deferred checks.
+	TxnCommitted(duration time.Duration)
+
+	// RowsInserted is called when rows are inserted into or updated in the table. Rows are reported when
+	// they are inserted, so the ones inserted by transactions which are never committed are reported too.
+	RowsInserted(table, count uint64)
+
+	// RowsDeleted is called when rows are deleted from the table. Like inserts, deletes are reported
+	// when executed.
+	RowsDeleted(table, count uint64)
+
+	// Seek is called when the index is searched for the key built from query arguments or cursor.
+	Seek(table, index uint64)
+
+	// QueryFinished is called when iteration stops, either because the iterator is exhausted or because it is
+	// closed. Keys is the number of keys scanned by the query. Iterators abandoned without being exhausted
+	// or closed are not reported.
+	QueryFinished(table, index, keys uint64)
+}
+
+// NopInstrumentation ignores all the events. It might be embedded by instrumentations interested in some of them.
+type NopInstrumentation struct{}
+
+var _ Instrumentation = NopInstrumentation{}
+
+// TxnStarted implements Instrumentation.
+func (NopInstrumentation) TxnStarted(write bool) {}
+
+// TxnCommitted implements Instrumentation.
+func (NopInstrumentation) TxnCommitted(duration time.Duration) {}
+
+// RowsInserted implements Instrumentation.
+func (NopInstrumentation) RowsInserted(table, count uint64) {}
+
+// RowsDeleted implements Instrumentation.
+func (NopInstrumentation) RowsDeleted(table, count uint64) {}
+
+// Seek implements Instrumentation.
+func (NopInstrumentation) Seek(table, index uint64) {}
+
+// QueryFinished implements Instrumentation.
+func (NopInstrumentation) QueryFinished(table, index, keys uint64) {}
+
+// numOfKeys returns the number of keys scanned by point lookup.
+func numOfKeys(obj unsafe.Pointer) uint64 {
+	if obj == nil {
+		return 0
+	}
+	return 1
+}
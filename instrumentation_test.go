@@ -0,0 +1,145 @@
+package memdb_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/outofforest/memdb"
+)
+
+type event struct {
+	Name  string
+	Table uint64
+	Index uint64
+	Count uint64
+}
+
+type recordingInstrumentation struct {
+	memdb.NopInstrumentation
+
+	events  []event
+	commits []time.Duration
+}
+
+func (i *recordingInstrumentation) TxnStarted(write bool) {
+	name := "read"
+	if write {
+		name = "write"
+	}
+	i.events = append(i.events, event{Name: name})
+}
+
+func (i *recordingInstrumentation) TxnCommitted(duration time.Duration) {
+	i.events = append(i.events, event{Name: "commit"})
+	i.commits = append(i.commits, duration)
+}
+
+func (i *recordingInstrumentation) RowsInserted(table, count uint64) {
+	i.events = append(i.events, event{Name: "insert", Table: table, Count: count})
+}
+
+func (i *recordingInstrumentation) RowsDeleted(table, count uint64) {
+	i.events = append(i.events, event{Name: "delete", Table: table, Count: count})
+}
+
+func (i *recordingInstrumentation) Seek(table, index uint64) {
+	i.events = append(i.events, event{Name: "seek", Table: table, Index: index})
+}
+
+func (i *recordingInstrumentation) QueryFinished(table, index, keys uint64) {
+	i.events = append(i.events, event{Name: "query", Table: table, Index: index, Count: keys})
+}
+
+func TestInstrumentation(t *testing.T) {
+	requireT := require.New(t)
+
+	instrumentation := &recordingInstrumentation{}
+	db, err := memdb.NewMemDB(memdb.Config{
+		Entities:        []reflect.Type{reflect.TypeFor[TestObject]()},
+		Indices:         []memdb.Index{indexFoo},
+		Instrumentation: instrumentation,
+	})
+	requireT.NoError(err)
+
+	txn := db.Txn(true)
+	requireT.NoError(insert(txn, objectTableID, unsafe.Pointer(&TestObject{ID: memdb.ID{1}, Foo: "a"})))
+	requireT.NoError(insert(txn, objectTableID, unsafe.Pointer(&TestObject{ID: memdb.ID{2}, Foo: "a"})))
+	_, err = txn.InsertBatch(objectTableID, []unsafe.Pointer{
+		unsafe.Pointer(&TestObject{ID: memdb.ID{3}, Foo: "b"}),
+		unsafe.Pointer(&TestObject{ID: memdb.ID{4}, Foo: "b"}),
+	})
+	requireT.NoError(err)
+
+	// Nested transactions are not reported.
+	nested := txn.Txn(true)
+	_, err = nested.Delete(objectTableID, unsafe.Pointer(&TestObject{ID: memdb.ID{4}}))
+	requireT.NoError(err)
+	requireT.NoError(nested.Commit())
+	requireT.NoError(txn.Commit())
+
+	txn = db.Txn(false)
+	_, err = txn.First(objectTableID, indexFoo.ID(), "a")
+	requireT.NoError(err)
+	_, err = txn.First(objectTableID, indexFoo.ID(), "c")
+	requireT.NoError(err)
+
+	it, err := txn.Iterator(objectTableID, indexFoo.ID())
+	requireT.NoError(err)
+	for obj := it.Next(); obj != nil; obj = it.Next() {
+	}
+	// Query is reported once.
+	requireT.Nil(it.Next())
+
+	// Query stopped early is reported when iterator is closed.
+	it, err = txn.Iterator(objectTableID, indexFoo.ID(), "a")
+	requireT.NoError(err)
+	requireT.NotNil(it.Next())
+	it.Close()
+	it.Close()
+
+	// Breaking out of the sequence closes the iterator.
+	seq, err := txn.All(objectTableID, indexFoo.ID())
+	requireT.NoError(err)
+	for range seq {
+		break
+	}
+
+	distinct, err := txn.Distinct(objectTableID, indexFoo.ID())
+	requireT.NoError(err)
+	values, obj := distinct.Next()
+	requireT.Equal([]any{"a"}, values)
+	requireT.NotNil(obj)
+	distinct.Close()
+
+	// Abandoned query is not reported.
+	it, err = txn.Iterator(objectTableID, indexFoo.ID(), "a")
+	requireT.NoError(err)
+	requireT.NotNil(it.Next())
+
+	requireT.Equal([]event{
+		{Name: "write"},
+		{Name: "insert", Table: objectTableID, Count: 1},
+		{Name: "insert", Table: objectTableID, Count: 1},
+		{Name: "insert", Table: objectTableID, Count: 2},
+		{Name: "delete", Table: objectTableID, Count: 1},
+		{Name: "commit"},
+		{Name: "read"},
+		{Name: "seek", Table: objectTableID, Index: indexFoo.ID()},
+		{Name: "query", Table: objectTableID, Index: indexFoo.ID(), Count: 1},
+		{Name: "seek", Table: objectTableID, Index: indexFoo.ID()},
+		{Name: "query", Table: objectTableID, Index: indexFoo.ID(), Count: 0},
+		{Name: "query", Table: objectTableID, Index: indexFoo.ID(), Count: 3},
+		{Name: "seek", Table: objectTableID, Index: indexFoo.ID()},
+		{Name: "query", Table: objectTableID, Index: indexFoo.ID(), Count: 1},
+		{Name: "query", Table: objectTableID, Index: indexFoo.ID(), Count: 1},
+		{Name: "seek", Table: objectTableID, Index: indexFoo.ID()},
+		{Name: "query", Table: objectTableID, Index: indexFoo.ID(), Count: 1},
+		{Name: "seek", Table: objectTableID, Index: indexFoo.ID()},
+	}, instrumentation.events)
+	requireT.Len(instrumentation.commits, 1)
+	requireT.Positive(instrumentation.commits[0])
+}
@@ -32,26 +32,26 @@ func TestTxn_Isolation(t *testing.T) {
 		Foo: "xyz",
 	}
 
-	oldV, err := txn1.Insert(0, unsafe.Pointer(obj))
+	oldV, err := txn1.Insert(objectTableID, unsafe.Pointer(obj))
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
 	require.Zero(t, oldV)
 
-	oldV, err = txn1.Insert(0, unsafe.Pointer(obj2))
+	oldV, err = txn1.Insert(objectTableID, unsafe.Pointer(obj2))
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
 	require.Zero(t, oldV)
 
-	oldV, err = txn1.Insert(0, unsafe.Pointer(obj3))
+	oldV, err = txn1.Insert(objectTableID, unsafe.Pointer(obj3))
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
 	require.Zero(t, oldV)
 
 	// Results should show up in this transaction
-	raw, err := txn1.First(0, memdb.IDIndexID)
+	raw, err := txn1.First(objectTableID, memdb.IDIndexID)
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
@@ -63,7 +63,7 @@ func TestTxn_Isolation(t *testing.T) {
 	txn2 := db.Txn(false)
 
 	// Nothing should show up in this transaction
-	raw, err = txn2.First(0, memdb.IDIndexID)
+	raw, err = txn2.First(objectTableID, memdb.IDIndexID)
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
@@ -72,10 +72,10 @@ func TestTxn_Isolation(t *testing.T) {
 	}
 
 	// Commit txn1, txn2 should still be isolated
-	txn1.Commit()
+	require.NoError(t, txn1.Commit())
 
 	// Nothing should show up in this transaction
-	raw, err = txn2.First(0, memdb.IDIndexID)
+	raw, err = txn2.First(objectTableID, memdb.IDIndexID)
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
@@ -87,7 +87,7 @@ func TestTxn_Isolation(t *testing.T) {
 	txn3 := db.Txn(false)
 
 	// Results should show up in this transaction
-	raw, err = txn3.First(0, memdb.IDIndexID)
+	raw, err = txn3.First(objectTableID, memdb.IDIndexID)
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
@@ -114,15 +114,15 @@ func TestTxn_DontCommit(t *testing.T) {
 		Foo: "xyz",
 	}
 
-	oldV, err := txn1.Insert(0, unsafe.Pointer(obj1))
+	oldV, err := txn1.Insert(objectTableID, unsafe.Pointer(obj1))
 	require.NoError(t, err)
 	require.Zero(t, oldV)
 
-	oldV, err = txn1.Insert(0, unsafe.Pointer(obj2))
+	oldV, err = txn1.Insert(objectTableID, unsafe.Pointer(obj2))
 	require.NoError(t, err)
 	require.Zero(t, oldV)
 
-	oldV, err = txn1.Insert(0, unsafe.Pointer(obj3))
+	oldV, err = txn1.Insert(objectTableID, unsafe.Pointer(obj3))
 	require.NoError(t, err)
 	require.Zero(t, oldV)
 
@@ -132,7 +132,7 @@ func TestTxn_DontCommit(t *testing.T) {
 	txn2 := db.Txn(false)
 
 	// Nothing should show up in this transaction
-	raw, err := txn2.First(0, memdb.IDIndexID)
+	raw, err := txn2.First(objectTableID, memdb.IDIndexID)
 	require.NoError(t, err)
 	require.Zero(t, raw)
 }
@@ -156,11 +156,11 @@ func TestTxn_SubTx(t *testing.T) {
 
 	// Init txn1
 
-	oldV, err := txn1.Insert(0, unsafe.Pointer(obj1))
+	oldV, err := txn1.Insert(objectTableID, unsafe.Pointer(obj1))
 	require.NoError(t, err)
 	require.Zero(t, oldV)
 
-	oldV, err = txn1.Insert(0, unsafe.Pointer(obj2))
+	oldV, err = txn1.Insert(objectTableID, unsafe.Pointer(obj2))
 	require.NoError(t, err)
 	require.Zero(t, oldV)
 
@@ -171,94 +171,94 @@ func TestTxn_SubTx(t *testing.T) {
 	txn3 := db.Txn(false)
 
 	// Remove object from txn2
-	oldV, err = txn2.Delete(0, unsafe.Pointer(obj1))
+	oldV, err = txn2.Delete(objectTableID, unsafe.Pointer(obj1))
 	require.NoError(t, err)
 	require.NotZero(t, oldV)
 
 	// Add object to txn2
-	oldV, err = txn2.Insert(0, unsafe.Pointer(obj3))
+	oldV, err = txn2.Insert(objectTableID, unsafe.Pointer(obj3))
 	require.NoError(t, err)
 	require.Zero(t, oldV)
 
 	// Verify that changes are not visible in txn1.
-	v, err := txn1.First(0, memdb.IDIndexID, obj1.ID)
+	v, err := txn1.First(objectTableID, memdb.IDIndexID, obj1.ID)
 	require.NoError(t, err)
 	require.NotZero(t, v)
 
-	v, err = txn1.First(0, memdb.IDIndexID, obj3.ID)
+	v, err = txn1.First(objectTableID, memdb.IDIndexID, obj3.ID)
 	require.NoError(t, err)
 	require.Zero(t, v)
 
 	// Verify that changes are visible in txn2.
-	v, err = txn2.First(0, memdb.IDIndexID, obj1.ID)
+	v, err = txn2.First(objectTableID, memdb.IDIndexID, obj1.ID)
 	require.NoError(t, err)
 	require.Zero(t, v)
 
-	v, err = txn2.First(0, memdb.IDIndexID, obj3.ID)
+	v, err = txn2.First(objectTableID, memdb.IDIndexID, obj3.ID)
 	require.NoError(t, err)
 	require.NotZero(t, v)
 
 	// Verify that state from txn1 is visible in txn2.
-	v, err = txn2.First(0, memdb.IDIndexID, obj2.ID)
+	v, err = txn2.First(objectTableID, memdb.IDIndexID, obj2.ID)
 	require.NoError(t, err)
 	require.NotZero(t, v)
 
 	// Commit txn2
-	txn2.Commit()
+	require.NoError(t, txn2.Commit())
 
 	// Also create new top transaction.
 	txn4 := db.Txn(false)
 
 	// Verify that changes are visible in txn1.
-	v, err = txn1.First(0, memdb.IDIndexID, obj1.ID)
+	v, err = txn1.First(objectTableID, memdb.IDIndexID, obj1.ID)
 	require.NoError(t, err)
 	require.Zero(t, v)
 
-	v, err = txn1.First(0, memdb.IDIndexID, obj3.ID)
+	v, err = txn1.First(objectTableID, memdb.IDIndexID, obj3.ID)
 	require.NoError(t, err)
 	require.NotZero(t, v)
 
 	// Verify that changes are not visible in the other top transaction.
-	v, err = txn3.First(0, memdb.IDIndexID, obj1.ID)
+	v, err = txn3.First(objectTableID, memdb.IDIndexID, obj1.ID)
 	require.NoError(t, err)
 	require.Zero(t, v)
 
-	v, err = txn3.First(0, memdb.IDIndexID, obj2.ID)
+	v, err = txn3.First(objectTableID, memdb.IDIndexID, obj2.ID)
 	require.NoError(t, err)
 	require.Zero(t, v)
 
-	v, err = txn3.First(0, memdb.IDIndexID, obj3.ID)
+	v, err = txn3.First(objectTableID, memdb.IDIndexID, obj3.ID)
 	require.NoError(t, err)
 	require.Zero(t, v)
 
-	v, err = txn4.First(0, memdb.IDIndexID, obj1.ID)
+	v, err = txn4.First(objectTableID, memdb.IDIndexID, obj1.ID)
 	require.NoError(t, err)
 	require.Zero(t, v)
 
-	v, err = txn4.First(0, memdb.IDIndexID, obj2.ID)
+	v, err = txn4.First(objectTableID, memdb.IDIndexID, obj2.ID)
 	require.NoError(t, err)
 	require.Zero(t, v)
 
-	v, err = txn4.First(0, memdb.IDIndexID, obj3.ID)
+	v, err = txn4.First(objectTableID, memdb.IDIndexID, obj3.ID)
 	require.NoError(t, err)
 	require.Zero(t, v)
 
 	// Commit top transaction.
-	txn1.Commit()
+	require.NoError(t, txn1.Commit())
 
 	// Create new top transaction.
 	txn5 := db.Txn(false)
 
 	// Verify that entities are visible.
-	v, err = txn5.First(0, memdb.IDIndexID, obj1.ID)
+	v, err = txn5.First(objectTableID, memdb.IDIndexID, obj1.ID)
 	require.NoError(t, err)
 	require.Zero(t, v)
 
-	v, err = txn5.First(0, memdb.IDIndexID, obj2.ID)
+	v, err = txn5.First(objectTableID, memdb.IDIndexID, obj2.ID)
 	require.NoError(t, err)
 	require.NotZero(t, v)
 
-	v, err = txn5.First(0, memdb.IDIndexID, obj3.ID)
+	v, err = txn5.First(objectTableID, memdb.IDIndexID, obj3.ID)
 	require.NoError(t, err)
 	require.NotZero(t, v)
 }
@@ -269,7 +269,7 @@ func TestTxn_ReadOnlySubTxFailsOnCommit(t *testing.T) {
 	txn2 := txn1.Txn(false)
 
 	require.Panics(t, func() {
-		txn2.Commit()
+		_ = txn2.Commit()
 	})
 }
 
@@ -279,9 +279,9 @@ func TestTxn_SubTxFailsOnConcurrentCommit(t *testing.T) {
 	txn2 := txn1.Txn(true)
 	txn3 := txn1.Txn(true)
 
-	txn2.Commit()
+	require.NoError(t, txn2.Commit())
 	require.Panics(t, func() {
-		txn3.Commit()
+		_ = txn3.Commit()
 	})
 }
 
@@ -290,9 +290,9 @@ func TestTxn_SubTxMayCommitToReadOnlyTx(t *testing.T) {
 	txn1 := db.Txn(false)
 	txn2 := txn1.Txn(true)
 
-	txn2.Commit()
+	require.NoError(t, txn2.Commit())
 	require.Panics(t, func() {
-		txn1.Commit()
+		_ = txn1.Commit()
 	})
 }
 
@@ -413,7 +413,7 @@ func testPopulateData(t *testing.T, db *memdb.MemDB) {
 	require.Zero(t, oldVisit)
 
 	// Commit
-	txn.Commit()
+	require.NoError(t, txn.Commit())
 }
 
 type TestPerson struct {
@@ -435,10 +435,10 @@ type TestVisit struct {
 	Place  memdb.ID
 }
 
-const (
-	peopleTableID uint64 = iota
-	placesTableID
-	visitsTableID
+var (
+	peopleTableID = memdb.TableID(reflect.TypeFor[TestPerson]())
+	placesTableID = memdb.TableID(reflect.TypeFor[TestPlace]())
+	visitsTableID = memdb.TableID(reflect.TypeFor[TestVisit]())
 )
 
 var (
@@ -0,0 +1,137 @@
+// Package tags parses memdb struct tags.
+//
+// Tag value consists of index specifications separated by semicolons. Each specification is
+// a comma-separated list of options:
+//   - index=NAME - name of the index the field belongs to, field name is used if name is not provided,
+//   - unique - index is unique,
+//   - order=N - position of the field in multi-field index, declaration order is used by default; either all
+//     or none of the fields of the index must specify the order,
+//   - desc - field is sorted in descending order.
+//
+// Option values might be separated by either "=" or ":".
+package tags
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Name is the name of the struct tag.
+const Name = "memdb"
+
+// Field is a struct field with memdb tag.
+type Field struct {
+	Name string
+	Tag  string
+}
+
+// Component is a field being a part of the index.
+type Component struct {
+	Field string
+	Desc  bool
+
+	order    int
+	position int
+}
+
+// Index is the index defined by struct tags.
+type Index struct {
+	Name       string
+	Unique     bool
+	Components []Component
+}
+
+// Indices parses tags of struct fields and groups them into indices.
+func Indices(fields []Field) ([]Index, error) {
+	var result []*Index
+	indices := map[string]*Index{}
+	for position, f := range fields {
+		if f.Tag == "" {
+			continue
+		}
+		for _, spec := range strings.Split(f.Tag, ";") {
+			name, unique, c, err := parseSpec(spec)
+			if err != nil {
+				return nil, errors.Wrapf(err, "field %s", f.Name)
+			}
+			if name == "" {
+				name = f.Name
+			}
+			c.Field = f.Name
+			c.position = position
+
+			index := indices[name]
+			if index == nil {
+				index = &Index{Name: name}
+				indices[name] = index
+				result = append(result, index)
+			}
+			for _, c2 := range index.Components {
+				if c2.Field == c.Field {
+					return nil, errors.Errorf("field %s: field used twice in index %s", f.Name, name)
+				}
+				if c.order != 0 && c2.order == c.order {
+					return nil, errors.Errorf("field %s: order %d used twice in index %s", f.Name, c.order, name)
+				}
+				if (c.order == 0) != (c2.order == 0) {
+					return nil, errors.Errorf("field %s: ordered and unordered fields mixed in index %s", f.Name, name)
+				}
+			}
+			index.Unique = index.Unique || unique
+			index.Components = append(index.Components, c)
+		}
+	}
+
+	indexList := make([]Index, 0, len(result))
+	for _, index := range result {
+		sort.SliceStable(index.Components, func(i, j int) bool {
+			ci, cj := index.Components[i], index.Components[j]
+			if ci.order != cj.order {
+				return ci.order < cj.order
+			}
+			return ci.position < cj.position
+		})
+		indexList = append(indexList, *index)
+	}
+	return indexList, nil
+}
+
+func parseSpec(spec string) (string, bool, Component, error) {
+	var name string
+	var unique bool
+	var c Component
+	for _, option := range strings.Split(spec, ",") {
+		option = strings.TrimSpace(option)
+		key, value, hasValue := strings.Cut(option, "=")
+		if !hasValue {
+			key, value, hasValue = strings.Cut(option, ":")
+		}
+		switch key {
+		case "":
+		case "index":
+			if hasValue && value == "" {
+				return "", false, Component{}, errors.New("index name is empty")
+			}
+			name = value
+		case "unique":
+			unique = true
+		case "desc":
+			c.Desc = true
+		case "order":
+			order, err := strconv.Atoi(value)
+			if err != nil || order <= 0 {
+				return "", false, Component{}, errors.Errorf("invalid order %q", value)
+			}
+			c.order = order
+		default:
+			return "", false, Component{}, errors.Errorf("unknown option %q", option)
+		}
+		if hasValue && key != "index" && key != "order" {
+			return "", false, Component{}, errors.Errorf("option %q does not accept value", key)
+		}
+	}
+	return name, unique, c, nil
+}
@@ -0,0 +1,109 @@
+package tags
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndices(t *testing.T) {
+	t.Parallel()
+
+	requireT := require.New(t)
+
+	indices, err := Indices([]Field{
+		{Name: "ID"},
+		{Name: "Email", Tag: "unique"},
+		{Name: "Created", Tag: "index=byOwner,order=2,desc"},
+		{Name: "Owner", Tag: "index:byOwner,order:1;index=byOwnerName"},
+		{Name: "Name", Tag: "index=byOwnerName"},
+		{Name: "Status", Tag: "index"},
+	})
+	requireT.NoError(err)
+	requireT.Equal([]Index{
+		{
+			Name:   "Email",
+			Unique: true,
+			Components: []Component{
+				{Field: "Email", position: 1},
+			},
+		},
+		{
+			Name: "byOwner",
+			Components: []Component{
+				{Field: "Owner", order: 1, position: 3},
+				{Field: "Created", Desc: true, order: 2, position: 2},
+			},
+		},
+		{
+			Name: "byOwnerName",
+			Components: []Component{
+				{Field: "Owner", position: 3},
+				{Field: "Name", position: 4},
+			},
+		},
+		{
+			Name: "Status",
+			Components: []Component{
+				{Field: "Status", position: 5},
+			},
+		},
+	}, indices)
+}
+
+func TestIndicesErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		Name   string
+		Fields []Field
+		Error  string
+	}{
+		{
+			Name:   "unknown option",
+			Fields: []Field{{Name: "A", Tag: "uniq"}},
+			Error:  `field A: unknown option "uniq"`,
+		},
+		{
+			Name:   "empty index name",
+			Fields: []Field{{Name: "A", Tag: "index="}},
+			Error:  "field A: index name is empty",
+		},
+		{
+			Name:   "invalid order",
+			Fields: []Field{{Name: "A", Tag: "order=x"}},
+			Error:  `field A: invalid order "x"`,
+		},
+		{
+			Name:   "unexpected value",
+			Fields: []Field{{Name: "A", Tag: "desc=true"}},
+			Error:  `field A: option "desc" does not accept value`,
+		},
+		{
+			Name: "duplicated order",
+			Fields: []Field{
+				{Name: "A", Tag: "index=i,order=1"},
+				{Name: "B", Tag: "index=i,order=1"},
+			},
+			Error: "field B: order 1 used twice in index i",
+		},
+		{
+			Name: "mixed order",
+			Fields: []Field{
+				{Name: "A", Tag: "index=i"},
+				{Name: "B", Tag: "index=i,order=1"},
+			},
+			Error: "field B: ordered and unordered fields mixed in index i",
+		},
+		{
+			Name:   "duplicated field",
+			Fields: []Field{{Name: "A", Tag: "index=i;index=i"}},
+			Error:  "field A: field used twice in index i",
+		},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			_, err := Indices(tc.Fields)
+			require.EqualError(t, err, tc.Error)
+		})
+	}
+}
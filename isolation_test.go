@@ -34,14 +34,14 @@ func TestMemDB_Isolation(t *testing.T) {
 
 		// Add two objects (with a gap between their IDs)
 		txn := db.Txn(true)
-		oldV, err := txn.Insert(0, unsafe.Pointer(obj1a))
+		oldV, err := txn.Insert(objectTableID, unsafe.Pointer(obj1a))
 		require.NoError(t, err)
 		require.Zero(t, oldV)
 
-		oldV, err = txn.Insert(0, unsafe.Pointer(obj3))
+		oldV, err = txn.Insert(objectTableID, unsafe.Pointer(obj3))
 		require.NoError(t, err)
 		require.Zero(t, oldV)
-		txn.Commit()
+		require.NoError(t, txn.Commit())
 		return db
 	}
 
@@ -53,7 +53,7 @@ func TestMemDB_Isolation(t *testing.T) {
 		obj1b.ID = id1
 		txn1 := db.Txn(true)
 		obj1b.Baz = "nope"
-		oldV, err := txn1.Insert(0, unsafe.Pointer(obj1b))
+		oldV, err := txn1.Insert(objectTableID, unsafe.Pointer(obj1b))
 		require.NoError(t, err)
 		require.NotZero(t, oldV)
 		require.Equal(t, obj1a, (*TestObject)(oldV))
@@ -61,17 +61,17 @@ func TestMemDB_Isolation(t *testing.T) {
 		// Insert an object
 		obj2 := testObj()
 		obj2.ID = id2
-		oldV, err = txn1.Insert(0, unsafe.Pointer(obj2))
+		oldV, err = txn1.Insert(objectTableID, unsafe.Pointer(obj2))
 		require.NoError(t, err)
 		require.Zero(t, oldV)
 
 		txn2 := db.Txn(false)
-		out, err := txn2.First(0, memdb.IDIndexID, id1)
+		out, err := txn2.First(objectTableID, memdb.IDIndexID, id1)
 		require.NoError(t, err)
 		require.NotZero(t, out)
 		require.Equal(t, "yep", (*TestObject)(out).Baz)
 
-		out, err = txn2.First(0, memdb.IDIndexID, id2)
+		out, err = txn2.First(objectTableID, memdb.IDIndexID, id2)
 		require.NoError(t, err)
 		require.Zero(t, out)
 	})
@@ -84,7 +84,7 @@ func TestMemDB_Isolation(t *testing.T) {
 		obj1b.ID = id1
 		txn1 := db.Txn(true)
 		obj1b.Baz = "nope"
-		oldV, err := txn1.Insert(0, unsafe.Pointer(obj1b))
+		oldV, err := txn1.Insert(objectTableID, unsafe.Pointer(obj1b))
 		require.NoError(t, err)
 		require.NotZero(t, oldV)
 		require.Equal(t, obj1a, (*TestObject)(oldV))
@@ -92,7 +92,7 @@ func TestMemDB_Isolation(t *testing.T) {
 		// Insert an object
 		obj2 := testObj()
 		obj2.ID = id3
-		oldV, err = txn1.Insert(0, unsafe.Pointer(obj2))
+		oldV, err = txn1.Insert(objectTableID, unsafe.Pointer(obj2))
 		require.NoError(t, err)
 		require.NotZero(t, oldV)
 		require.Equal(t, obj3, (*TestObject)(oldV))
@@ -100,14 +100,14 @@ func TestMemDB_Isolation(t *testing.T) {
 		txn2 := db.Txn(false)
 
 		// Commit
-		txn1.Commit()
+		require.NoError(t, txn1.Commit())
 
-		out, err := txn2.First(0, memdb.IDIndexID, id1)
+		out, err := txn2.First(objectTableID, memdb.IDIndexID, id1)
 		require.NoError(t, err)
 		require.NotZero(t, out)
 		require.Equal(t, "yep", (*TestObject)(out).Baz)
 
-		out, err = txn2.First(0, memdb.IDIndexID, id2)
+		out, err = txn2.First(objectTableID, memdb.IDIndexID, id2)
 		require.NoError(t, err)
 		require.Zero(t, out)
 	})
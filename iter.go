@@ -0,0 +1,97 @@
+package memdb
+
+import (
+	"iter"
+	"reflect"
+	"unsafe"
+
+	"github.com/pkg/errors"
+)
+
+// All returns the sequence of rows matching the constraints of the index. Arguments are the same
+// as for Iterator. They are validated when All is called, while the query is executed each time the sequence
+// is iterated, so it reflects the state of the transaction at that time. Iteration might be stopped at any time.
+// If table or index is dropped before the sequence is iterated, iteration panics.
+func (txn *Txn) All(table, index uint64, args ...any) (iter.Seq[unsafe.Pointer], error) {
+	if err := txn.checkQuery(table, index, args...); err != nil {
+		return nil, err
+	}
+	return func(yield func(unsafe.Pointer) bool) {
+		it := txn.seqIterator(table, index, args...)
+		defer it.Close()
+		for o := it.Next(); o != nil; o = it.Next() {
+			if !yield(o) {
+				return
+			}
+		}
+	}, nil
+}
+
+// All returns the sequence of entities of type T matching the constraints of the index. Aggregate indices
+// are rejected, because they store aggregates instead of entities. See Txn.All for details.
+func All[T any](txn *Txn, index uint64, args ...any) (iter.Seq[*T], error) {
+	table, err := checkEntityQuery[T](txn, index, args...)
+	if err != nil {
+		return nil, err
+	}
+	return func(yield func(*T) bool) {
+		it := txn.seqIterator(table, index, args...)
+		defer it.Close()
+		for o := it.Next(); o != nil; o = it.Next() {
+			if !yield((*T)(o)) {
+				return
+			}
+		}
+	}, nil
+}
+
+// AllWithCursors returns the sequence of entities of type T matching the constraints of the index,
+// together with the cursor of each entity. Iteration started with the cursor continues right after the entity.
+// See All for details.
+func AllWithCursors[T any](txn *Txn, index uint64, args ...any) (iter.Seq2[Cursor, *T], error) {
+	table, err := checkEntityQuery[T](txn, index, args...)
+	if err != nil {
+		return nil, err
+	}
+	return func(yield func(Cursor, *T) bool) {
+		it := txn.seqIterator(table, index, args...)
+		defer it.Close()
+		for o := it.Next(); o != nil; o = it.Next() {
+			if !yield(it.Cursor(), (*T)(o)) {
+				return
+			}
+		}
+	}, nil
+}
+
+// checkEntityQuery validates the query returning entities of type T and returns the ID of their table.
+func checkEntityQuery[T any](txn *Txn, index uint64, args ...any) (uint64, error) {
+	table := TableID(reflect.TypeFor[T]())
+	if err := txn.checkQuery(table, index, args...); err != nil {
+		return 0, err
+	}
+	if _, ok := txn.getState().schema[table].indices[index].Indexer.(AggregateIndexer); ok {
+		return 0, errors.Errorf("index '%d' stores aggregates, not entities", index)
+	}
+	return table, nil
+}
+
+// checkQuery validates table, index and arguments of the query. Query is not reported to the instrumentation,
+// because the index is not iterated.
+func (txn *Txn) checkQuery(table, index uint64, args ...any) error {
+	sc := getScratch()
+	defer putScratch(sc)
+
+	_, _, err := txn.getIndexIterator(sc, NopInstrumentation{}, false, table, index, args...)
+	return err
+}
+
+// seqIterator returns the iterator of the query validated when the sequence was created. The query fails only if
+// table or index has been dropped since then, which is the programming error, so it panics.
+func (txn *Txn) seqIterator(table, index uint64, args ...any) ResultIterator {
+	it, err := txn.Iterator(table, index, args...)
+	if err != nil {
+		panic(errors.Wrap(err, "query is no longer valid"))
+	}
+	return it
+}
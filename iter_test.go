@@ -0,0 +1,148 @@
+package memdb_test
+
+import (
+	"reflect"
+	"slices"
+	"strconv"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/outofforest/memdb"
+	"github.com/outofforest/memdb/indices"
+)
+
+func TestTxn_All(t *testing.T) {
+	requireT := require.New(t)
+	db := testCursorDB(t)
+	txn := db.Txn(false)
+
+	seq, err := txn.All(objectTableID, indexFoo.ID(), "a")
+	requireT.NoError(err)
+
+	var ids []byte
+	for o := range seq {
+		ids = append(ids, (*TestObject)(o).ID[0])
+	}
+	requireT.Equal([]byte{1, 3, 4}, ids)
+
+	_, err = txn.All(objectTableID, indexFoo.ID(), "a", "b")
+	requireT.Error(err)
+
+	_, err = txn.All(objectTableID, 1)
+	requireT.ErrorContains(err, "invalid index")
+}
+
+func TestTxn_AllDroppedIndex(t *testing.T) {
+	requireT := require.New(t)
+	db := testCursorDB(t)
+	txn := db.Txn(true)
+	defer txn.Abort()
+
+	seq, err := txn.All(objectTableID, indexFoo.ID(), "a")
+	requireT.NoError(err)
+	seq2, err := memdb.All[TestObject](txn, indexFoo.ID(), "a")
+	requireT.NoError(err)
+	requireT.NoError(txn.DropIndex(objectTableID, indexFoo.ID()))
+
+	// Query is no longer valid, so it is not reported as empty result.
+	requireT.PanicsWithError("query is no longer valid: invalid index '"+strconv.FormatUint(indexFoo.ID(), 10)+"'",
+		func() {
+			for range seq {
+			}
+		})
+	requireT.Panics(func() {
+		for range seq2 {
+		}
+	})
+}
+
+func TestAll(t *testing.T) {
+	requireT := require.New(t)
+	db := testCursorDB(t)
+	txn := db.Txn(true)
+
+	seq, err := memdb.All[TestObject](txn, indexFoo.ID(), "b")
+	requireT.NoError(err)
+
+	objs := slices.Collect(seq)
+	requireT.Len(objs, 2)
+	requireT.Equal(memdb.ID{2}, objs[0].ID)
+	requireT.Equal(memdb.ID{5}, objs[1].ID)
+
+	// Sequence might be iterated many times, each time reflecting the current state of the transaction.
+	requireT.NoError(insert(txn, objectTableID, unsafe.Pointer(&TestObject{ID: memdb.ID{6}, Foo: "b"})))
+
+	objs = slices.Collect(seq)
+	requireT.Len(objs, 3)
+	requireT.Equal(memdb.ID{2}, objs[0].ID)
+	requireT.Equal(memdb.ID{5}, objs[1].ID)
+	requireT.Equal(memdb.ID{6}, objs[2].ID)
+
+	// Iteration might be stopped early.
+	seq, err = memdb.All[TestObject](txn, memdb.IDIndexID)
+	requireT.NoError(err)
+	var ids []byte
+	for o := range seq {
+		ids = append(ids, o.ID[0])
+		if len(ids) == 2 {
+			break
+		}
+	}
+	requireT.Equal([]byte{1, 2}, ids)
+}
+
+func TestAllWithCursors(t *testing.T) {
+	requireT := require.New(t)
+	db := testCursorDB(t)
+	txn := db.Txn(false)
+
+	seq, err := memdb.AllWithCursors[TestObject](txn, indexFoo.ID())
+	requireT.NoError(err)
+
+	var cursor memdb.Cursor
+	for c, o := range seq {
+		if o.ID == (memdb.ID{4}) {
+			cursor = c
+			break
+		}
+	}
+	requireT.NotNil(cursor)
+
+	seq2, err := memdb.All[TestObject](txn, indexFoo.ID(), cursor)
+	requireT.NoError(err)
+
+	var ids []byte
+	for o := range seq2 {
+		ids = append(ids, o.ID[0])
+	}
+	requireT.Equal([]byte{2, 5}, ids)
+}
+
+func TestAll_Aggregate(t *testing.T) {
+	requireT := require.New(t)
+
+	db, err := memdb.NewMemDB(memdb.Config{
+		Entities: []reflect.Type{reflect.TypeFor[TestObject]()},
+		Indices:  []memdb.Index{indexFoo, indexFooCount},
+	})
+	requireT.NoError(err)
+
+	txn := db.Txn(true)
+	requireT.NoError(insert(txn, objectTableID, unsafe.Pointer(&TestObject{ID: memdb.ID{1}, Foo: "a"})))
+
+	_, err = memdb.All[TestObject](txn, indexFooCount.ID())
+	requireT.Error(err)
+	_, err = memdb.AllWithCursors[TestObject](txn, indexFooCount.ID())
+	requireT.Error(err)
+
+	// Aggregates are still available through Txn.All.
+	seq, err := txn.All(objectTableID, indexFooCount.ID())
+	requireT.NoError(err)
+	var counts []uint64
+	for o := range seq {
+		counts = append(counts, (*indices.Aggregate[uint64])(o).Count)
+	}
+	requireT.Equal([]uint64{1}, counts)
+}
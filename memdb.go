@@ -8,6 +8,8 @@ package memdb
 import (
 	"fmt"
 	"reflect"
+	"regexp"
+	"slices"
 	"sync/atomic"
 	"unsafe"
 
@@ -19,6 +21,40 @@ import (
 type Config struct {
 	Entities []reflect.Type
 	Indices  []Index
+
+	// References defines reference constraints between entities.
+	References []Reference
+
+	// Validators defines validators of entities.
+	Validators []Validator
+
+	// Triggers defines triggers executed on inserts and deletes of entities.
+	Triggers []Trigger
+
+	// Views defines views deriving entities from other entities.
+	Views []View
+
+	// PrimaryKeys defines indices used as primary keys of entities. If primary key is not defined for the entity,
+	// its ID field is used. Primary key index must produce single key for each entity.
+	PrimaryKeys []Index
+
+	// CopyPolicies defines tables storing or returning copies of objects.
+	CopyPolicies []CopyPolicy
+
+	// Instrumentation receives events used to collect metrics and traces. If nil, events are ignored.
+	Instrumentation Instrumentation
+
+	// DetectMutations enables the debug mode detecting objects modified in place after they were inserted.
+	// Digests of keys are recorded when objects are inserted and verified when they are read and by MemDB.Verify.
+	// It is expensive, so it is meant for tests and debugging only.
+	DetectMutations bool
+
+	// OnMutation is called when mutation of the object is detected by a read. If nil, read panics.
+	OnMutation func(err error)
+
+	// TrackSnapshots enables reporting old snapshots held by transactions in MemDB.Stats. Each top-level
+	// transaction is registered when it is started, so it adds the cost of taking the lock to every transaction.
+	TrackSnapshots bool
 }
 
 // MemDB is an in-memory database providing Atomicity, Consistency, and
@@ -33,10 +69,115 @@ type Config struct {
 // that objects are not modified in-place after they are inserted since they
 // are stored directly in MemDB. It remains unsafe to modify inserted objects
 // even after they've been deleted from MemDB since there may still be older
-// snapshots of the DB being read from other goroutines.
+// snapshots of the DB being read from other goroutines. Config.DetectMutations
+// might be used to detect such modifications during development.
 type MemDB struct {
-	schema dbSchema
-	root   unsafe.Pointer // *tree.Tree underneath
+	root unsafe.Pointer // *state underneath
+
+	// snapshots tracks states held by transactions. It is nil if tracking is disabled.
+	snapshots *snapshots
+
+	instrumentation Instrumentation
+	mutations       *mutationDetector
+}
+
+// state is the schema and the data of the database. Both are published atomically when transaction is committed.
+type state struct {
+	schema   dbSchema
+	indexID  uint64
+	revision uint64
+	tree     *tree.Tree[*iradix.Txn[unsafe.Pointer]]
+
+	pending     []pendingCheck
+	validations []pendingValidation
+	changes     []viewChange
+}
+
+// next creates the state used by the next transaction.
+func (s *state) next() *state {
+	return &state{
+		schema:      s.schema,
+		indexID:     s.indexID,
+		revision:    s.revision,
+		tree:        s.tree.Next(),
+		pending:     slices.Clip(s.pending),
+		validations: slices.Clip(s.validations),
+		changes:     slices.Clip(s.changes),
+	}
+}
+
+// anonymousFuncName matches names the compiler assigns to anonymous functions. They depend on the position
+// of the function within the enclosing one, so they change when unrelated code is modified.
+var anonymousFuncName = regexp.MustCompile(`\.func\d+`)
+
+// newIndexSchema validates the index against the table schema and allocates the tree for it.
+func (s *state) newIndexSchema(eType reflect.Type, t *tableSchema, index Index) (*IndexSchema, error) {
+	name := index.Name()
+	if name == "" {
+		return nil, fmt.Errorf("index of entity %s has empty name", eType)
+	}
+	if anonymousFuncName.MatchString(name) {
+		return nil, fmt.Errorf("index %q of entity %s is named after anonymous function, its name must be set explicitly",
+			name, eType)
+	}
+	if index.ID() != IndexID(name) {
+		return nil, fmt.Errorf("ID of index %q of entity %s does not match its name", name, eType)
+	}
+	if index.ID() == IDIndexID {
+		return nil, fmt.Errorf("index %q of entity %s has reserved ID", name, eType)
+	}
+	if existing, exists := t.indices[index.ID()]; exists {
+		if existing.name == name {
+			return nil, fmt.Errorf("duplicated index %q of entity %s", name, eType)
+		}
+		return nil, fmt.Errorf("indices %q and %q of entity %s have the same ID", existing.name, name, eType)
+	}
+
+	indexSchema := index.Schema()
+	if err := indexSchema.Validate(); err != nil {
+		return nil, fmt.Errorf("index %q of entity %s: %w", name, eType, err)
+	}
+	indexSchema.name = name
+	indexSchema.id = s.newTree()
+	return indexSchema, nil
+}
+
+// newTableSchema validates the table and allocates trees for its indices.
+func (s *state) newTableSchema(eType reflect.Type, pk Index, indices []Index) (*tableSchema, error) {
+	idSchema, err := primaryKeySchema(eType, pk)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &tableSchema{
+		eType:   eType,
+		indices: map[uint64]*IndexSchema{},
+	}
+	idSchema.id = s.newTree()
+	t.indices[IDIndexID] = idSchema
+
+	if _, ok := idSchema.Indexer.(SequenceIndexer); ok {
+		idSchema.sequenceID = s.newTree()
+	}
+
+	for _, index := range indices {
+		if index.Type() != eType {
+			return nil, fmt.Errorf("index %q is defined for entity %s, not %s", index.Name(), index.Type(), eType)
+		}
+		indexSchema, err := s.newIndexSchema(eType, t, index)
+		if err != nil {
+			return nil, err
+		}
+		t.indices[index.ID()] = indexSchema
+	}
+	return t, nil
+}
+
+// newTree allocates new empty tree.
+func (s *state) newTree() uint64 {
+	s.indexID++
+	s.tree.Set(s.indexID, iradix.NewTxn(iradix.New[unsafe.Pointer]()))
+	return s.indexID
 }
 
 // NewMemDB creates a new MemDB with the given schema.
@@ -57,56 +198,213 @@ func NewMemDB(config Config) (*MemDB, error) {
 		indicesByEntity[t] = append(indicesByEntity[t], i)
 	}
 
-	root := tree.New[*iradix.Txn[unsafe.Pointer]]()
-	db := &MemDB{
-		schema: make(dbSchema, 0, len(indicesByEntity)),
-		root:   unsafe.Pointer(root),
+	primaryKeys := map[reflect.Type]Index{}
+	for _, pk := range config.PrimaryKeys {
+		t := pk.Type()
+		if _, exists := indicesByEntity[t]; !exists {
+			return nil, fmt.Errorf("primary key for undefined entity %s", t)
+		}
+		if _, exists := primaryKeys[t]; exists {
+			return nil, fmt.Errorf("duplicated primary key for entity %s", t)
+		}
+		primaryKeys[t] = pk
 	}
 
-	var indexID uint64
+	s := &state{
+		schema: make(dbSchema, len(indicesByEntity)),
+		tree:   tree.New[*iradix.Txn[unsafe.Pointer]](),
+	}
+
+	tableTypes := map[uint64]reflect.Type{}
 	for _, eT := range config.Entities {
-		t := tableSchema{}
-		db.schema = append(db.schema, t)
+		tableID := TableID(eT)
+		if eT2, exists := tableTypes[tableID]; exists {
+			return nil, fmt.Errorf("entities %s and %s have the same table ID %d", eT2, eT, tableID)
+		}
+		tableTypes[tableID] = eT
 
-		indexID++
-		t[IDIndexID] = &IndexSchema{
-			Unique:  true,
-			Indexer: IDIndexer{},
-			id:      indexID,
+		t, err := s.newTableSchema(eT, primaryKeys[eT], indicesByEntity[eT])
+		if err != nil {
+			return nil, err
 		}
-		root.Set(indexID, iradix.NewTxn(iradix.New[unsafe.Pointer]()))
+		s.schema[tableID] = t
+	}
 
-		for _, index := range indicesByEntity[eT] {
-			indexID++
-			indexSchema := index.Schema()
-			indexSchema.id = indexID
-			t[index.ID()] = indexSchema
-			root.Set(indexID, iradix.NewTxn(iradix.New[unsafe.Pointer]()))
+	for _, r := range config.References {
+		if err := s.addReference(r); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, v := range config.Validators {
+		t, ok := s.schema[TableID(v.Type())]
+		if !ok {
+			return nil, fmt.Errorf("validator for undefined entity %s", v.Type())
+		}
+		t.validators = append(t.validators, v)
+	}
+
+	for _, tr := range config.Triggers {
+		t, ok := s.schema[TableID(tr.Type())]
+		if !ok {
+			return nil, fmt.Errorf("trigger for undefined entity %s", tr.Type())
+		}
+		t.triggers = append(t.triggers, tr)
+	}
+
+	for _, p := range config.CopyPolicies {
+		t, ok := s.schema[TableID(p.Type())]
+		if !ok {
+			return nil, fmt.Errorf("copy policy for undefined entity %s", p.Type())
+		}
+		if t.copyPolicy != nil {
+			return nil, fmt.Errorf("duplicated copy policy for entity %s", p.Type())
+		}
+		t.copyPolicy = p
+	}
+
+	for _, v := range config.Views {
+		if err := s.addView(v); err != nil {
+			return nil, err
 		}
 	}
 
 	// Validate the schema
-	if err := db.schema.Validate(); err != nil {
+	if err := s.schema.Validate(); err != nil {
 		return nil, err
 	}
 
+	instrumentation := config.Instrumentation
+	if instrumentation == nil {
+		instrumentation = NopInstrumentation{}
+	}
+
+	db := &MemDB{
+		root:            unsafe.Pointer(s),
+		instrumentation: instrumentation,
+	}
+	if config.DetectMutations {
+		db.mutations = newMutationDetector(config.OnMutation)
+	}
+	if config.TrackSnapshots {
+		db.snapshots = &snapshots{}
+	}
 	return db, nil
 }
 
-// Txn is used to start a new transaction in either read or write mode.
+// primaryKeySchema returns schema of the ID index of the entity.
+func primaryKeySchema(eType reflect.Type, pk Index) (*IndexSchema, error) {
+	if eType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("entity %s is not a struct", eType)
+	}
+	if pk != nil {
+		if pk.Type() != eType {
+			return nil, fmt.Errorf("primary key is defined for entity %s, not %s", pk.Type(), eType)
+		}
+		if _, ok := pk.Schema().Indexer.(MultiKeyIndexer); ok {
+			return nil, fmt.Errorf("primary key of entity %s produces many keys", eType)
+		}
+		if _, ok := pk.Schema().Indexer.(AggregateIndexer); ok {
+			return nil, fmt.Errorf("primary key of entity %s is aggregate", eType)
+		}
+		return &IndexSchema{
+			Unique:  true,
+			Indexer: pk.Schema().Indexer,
+			name:    pk.Name(),
+		}, nil
+	}
+
+	f, ok := eType.FieldByName("ID")
+	if !ok || f.Type.Kind() != reflect.Array || !f.Type.ConvertibleTo(idType) {
+		return nil, fmt.Errorf("entity %s has no ID field convertible to memdb.ID", eType)
+	}
+
+	// Field might be promoted from embedded struct, so offsets of all the containing fields must be added.
+	var offset uintptr
+	t := eType
+	for _, i := range f.Index {
+		if t.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("ID field of entity %s is promoted through a pointer", eType)
+		}
+		field := t.Field(i)
+		offset += field.Offset
+		t = field.Type
+	}
+
+	return &IndexSchema{
+		Unique:  true,
+		Indexer: IDIndexer{Offset: offset},
+		name:    "id",
+	}, nil
+}
+
+// Txn is used to start a new transaction in either read or write mode. Transaction must be ended by calling
+// Commit or Abort, read transactions by calling Abort.
 func (db *MemDB) Txn(write bool) *Txn {
 	root, rootPointer := db.getRoot()
-	return &Txn{
-		schema:        db.schema,
+	db.instrumentation.TxnStarted(write)
+	txn := &Txn{
 		write:         write,
-		root:          unsafe.Pointer(root.Next()),
+		root:          unsafe.Pointer(root.next()),
 		parentRoot:    &db.root,
 		oldParentRoot: rootPointer,
+
+		instrumentation: db.instrumentation,
+		mutations:       db.mutations,
+	}
+	if db.snapshots != nil {
+		txn.trackSnapshot(db.snapshots, root)
+	}
+	return txn
+}
+
+// AddIndex adds index to the database. Index is filled with existing entities and published atomically
+// in a write transaction, so no other write transaction may be in progress.
+func (db *MemDB) AddIndex(index Index) error {
+	txn := db.Txn(true)
+	if err := txn.AddIndex(index); err != nil {
+		txn.Abort()
+		return err
+	}
+	return txn.Commit()
+}
+
+// DropIndex removes index from the database. It is done in a write transaction, so no other write transaction
+// may be in progress.
+func (db *MemDB) DropIndex(table, index uint64) error {
+	txn := db.Txn(true)
+	if err := txn.DropIndex(table, index); err != nil {
+		txn.Abort()
+		return err
+	}
+	return txn.Commit()
+}
+
+// AddTable adds table storing entities of the given type together with its primary key and indices. If pk is nil,
+// ID field of the entity is used as the primary key. It is done in a write transaction, so no other write transaction
+// may be in progress.
+func (db *MemDB) AddTable(eType reflect.Type, pk Index, indices ...Index) error {
+	txn := db.Txn(true)
+	if err := txn.AddTable(eType, pk, indices...); err != nil {
+		txn.Abort()
+		return err
+	}
+	return txn.Commit()
+}
+
+// DropTable removes table from the database. It is done in a write transaction, so no other write transaction
+// may be in progress.
+func (db *MemDB) DropTable(table uint64) error {
+	txn := db.Txn(true)
+	if err := txn.DropTable(table); err != nil {
+		txn.Abort()
+		return err
 	}
+	return txn.Commit()
 }
 
 // getRoot is used to do an atomic load of the root pointer.
-func (db *MemDB) getRoot() (*tree.Tree[*iradix.Txn[unsafe.Pointer]], unsafe.Pointer) {
+func (db *MemDB) getRoot() (*state, unsafe.Pointer) {
 	pointer := atomic.LoadPointer(&db.root)
-	return (*tree.Tree[*iradix.Txn[unsafe.Pointer]])(pointer), pointer
+	return (*state)(pointer), pointer
 }
@@ -4,6 +4,7 @@
 package memdb_test
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -20,9 +21,9 @@ func TestMemDB_PanicOnParallelCommit(t *testing.T) {
 	tx1 := db.Txn(true)
 	tx2 := db.Txn(true)
 
-	tx1.Commit()
+	require.NoError(t, tx1.Commit())
 	require.Panics(t, func() {
-		tx2.Commit()
+		_ = tx2.Commit()
 	})
 }
 
@@ -34,7 +35,7 @@ func TestMemDB_PanicOnReadOnlyCommit(t *testing.T) {
 
 	tx := db.Txn(false)
 	require.Panics(t, func() {
-		tx.Commit()
+		_ = tx.Commit()
 	})
 }
 
@@ -45,8 +46,74 @@ func TestMemDB_PanicOnDoubleCommit(t *testing.T) {
 	}
 
 	tx := db.Txn(true)
-	tx.Commit()
+	require.NoError(t, tx.Commit())
 	require.Panics(t, func() {
-		tx.Commit()
+		_ = tx.Commit()
 	})
 }
+
+type testIDNotFirst struct {
+	Foo string
+	ID  memdb.ID
+}
+
+type testIDEmbedded struct {
+	Foo string
+	testIDNotFirst
+}
+
+type testNoID struct {
+	Foo string
+}
+
+type testInvalidID struct {
+	ID string
+}
+
+func TestMemDB_EntityLayout(t *testing.T) {
+	for _, eType := range []reflect.Type{
+		reflect.TypeFor[testIDNotFirst](),
+		reflect.TypeFor[testIDEmbedded](),
+	} {
+		db, err := memdb.NewMemDB(memdb.Config{Entities: []reflect.Type{eType}})
+		require.NoError(t, err)
+
+		e := reflect.New(eType)
+		e.Elem().FieldByName("ID").Set(reflect.ValueOf(memdb.ID{1}))
+
+		txn := db.Txn(true)
+		_, err = txn.Insert(memdb.TableID(eType), e.UnsafePointer())
+		require.NoError(t, err)
+
+		e2, err := txn.First(memdb.TableID(eType), memdb.IDIndexID, memdb.ID{1})
+		require.NoError(t, err)
+		require.Equal(t, e.UnsafePointer(), e2)
+
+		e2, err = txn.First(memdb.TableID(eType), memdb.IDIndexID, memdb.ID{})
+		require.NoError(t, err)
+		require.Nil(t, e2)
+	}
+
+	for _, eType := range []reflect.Type{
+		reflect.TypeFor[testNoID](),
+		reflect.TypeFor[testInvalidID](),
+		reflect.TypeFor[memdb.ID](),
+	} {
+		_, err := memdb.NewMemDB(memdb.Config{Entities: []reflect.Type{eType}})
+		require.Error(t, err)
+	}
+}
+
+func TestMemDB_PrimaryKeyValidation(t *testing.T) {
+	_, err := memdb.NewMemDB(memdb.Config{
+		Entities:    []reflect.Type{reflect.TypeFor[TestPerson]()},
+		PrimaryKeys: []memdb.Index{indexFoo},
+	})
+	require.ErrorContains(t, err, "primary key for undefined entity")
+
+	_, err = memdb.NewMemDB(memdb.Config{
+		Entities:    []reflect.Type{reflect.TypeFor[TestObject]()},
+		PrimaryKeys: []memdb.Index{indexFoo, indexFoo},
+	})
+	require.ErrorContains(t, err, "duplicated primary key")
+}
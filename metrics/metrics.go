@@ -0,0 +1,317 @@
+// Package metrics collects memdb events and exposes them in Prometheus text format.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"iter"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/outofforest/memdb"
+)
+
+var (
+	commitBuckets = []uint64{
+		uint64(10 * time.Microsecond),
+		uint64(50 * time.Microsecond),
+		uint64(100 * time.Microsecond),
+		uint64(500 * time.Microsecond),
+		uint64(time.Millisecond),
+		uint64(5 * time.Millisecond),
+		uint64(10 * time.Millisecond),
+		uint64(50 * time.Millisecond),
+		uint64(100 * time.Millisecond),
+		uint64(500 * time.Millisecond),
+		uint64(time.Second),
+	}
+	scannedBuckets = []uint64{0, 1, 10, 100, 1000, 10000, 100000, 1000000}
+)
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// Metrics is the instrumentation collecting metrics of the database. It is the http.Handler serving them
+// in Prometheus text format.
+type Metrics struct {
+	tableNames map[uint64]string
+	indexNames map[labels]string
+
+	txnsRead       atomic.Uint64
+	txnsWrite      atomic.Uint64
+	commitDuration *histogram
+	inserted       *series[atomic.Uint64]
+	deleted        *series[atomic.Uint64]
+	seeks          *series[atomic.Uint64]
+	scanned        *series[histogram]
+}
+
+var (
+	_ memdb.Instrumentation = &Metrics{}
+	_ http.Handler          = &Metrics{}
+)
+
+// New creates metrics for the database created from the config. Config is used to name tables and indices,
+// the ones added later are labeled with their IDs. Returned metrics should be set as the instrumentation of the config
+// before database is created.
+func New(config memdb.Config) *Metrics {
+	m := &Metrics{
+		tableNames: map[uint64]string{},
+		indexNames: map[labels]string{},
+	}
+
+	for _, eType := range config.Entities {
+		table := memdb.TableID(eType)
+		m.tableNames[table] = eType.String()
+		m.indexNames[labels{table: table, index: memdb.IDIndexID}] = "id"
+	}
+	for _, pk := range config.PrimaryKeys {
+		m.indexNames[labels{table: memdb.TableID(pk.Type()), index: memdb.IDIndexID}] = pk.Name()
+	}
+	for _, index := range config.Indices {
+		m.indexNames[labels{table: memdb.TableID(index.Type()), index: index.ID()}] = index.Name()
+	}
+
+	tables := make([]labels, 0, len(m.tableNames))
+	for table := range m.tableNames {
+		tables = append(tables, labels{table: table})
+	}
+	indices := slices.Collect(maps.Keys(m.indexNames))
+
+	newCounter := func() *atomic.Uint64 {
+		return &atomic.Uint64{}
+	}
+	m.commitDuration = newHistogram(commitBuckets)
+	m.inserted = newSeries(tables, newCounter)
+	m.deleted = newSeries(tables, newCounter)
+	m.seeks = newSeries(indices, newCounter)
+	m.scanned = newSeries(indices, func() *histogram {
+		return newHistogram(scannedBuckets)
+	})
+	return m
+}
+
+// TxnStarted implements memdb.Instrumentation.
+func (m *Metrics) TxnStarted(write bool) {
+	if write {
+		m.txnsWrite.Add(1)
+	} else {
+		m.txnsRead.Add(1)
+	}
+}
+
+// TxnCommitted implements memdb.Instrumentation.
+func (m *Metrics) TxnCommitted(duration time.Duration) {
+	m.commitDuration.observe(uint64(duration))
+}
+
+// RowsInserted implements memdb.Instrumentation.
+func (m *Metrics) RowsInserted(table, count uint64) {
+	m.inserted.get(labels{table: table}).Add(count)
+}
+
+// RowsDeleted implements memdb.Instrumentation.
+func (m *Metrics) RowsDeleted(table, count uint64) {
+	m.deleted.get(labels{table: table}).Add(count)
+}
+
+// Seek implements memdb.Instrumentation.
+func (m *Metrics) Seek(table, index uint64) {
+	m.seeks.get(labels{table: table, index: index}).Add(1)
+}
+
+// QueryFinished implements memdb.Instrumentation.
+func (m *Metrics) QueryFinished(table, index, keys uint64) {
+	m.scanned.get(labels{table: table, index: index}).observe(keys)
+}
+
+// ServeHTTP serves metrics in Prometheus text format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = m.Write(w)
+}
+
+// Write writes metrics in Prometheus text format.
+func (m *Metrics) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	header(bw, "memdb_transactions_started_total", "counter", "Top-level transactions started.")
+	fmt.Fprintf(bw, "memdb_transactions_started_total{mode=\"read\"} %d\n", m.txnsRead.Load())
+	fmt.Fprintf(bw, "memdb_transactions_started_total{mode=\"write\"} %d\n", m.txnsWrite.Load())
+
+	header(bw, "memdb_transactions_committed_total", "counter", "Top-level transactions committed.")
+	fmt.Fprintf(bw, "memdb_transactions_committed_total %d\n", m.commitDuration.count())
+
+	header(bw, "memdb_commit_duration_seconds", "histogram", "Time taken by commits of top-level transactions.")
+	m.commitDuration.write(bw, "memdb_commit_duration_seconds", "", float64(time.Second))
+
+	header(bw, "memdb_rows_inserted_total", "counter", "Rows inserted or updated.")
+	for l, c := range m.inserted.sorted(m.tableLabels) {
+		fmt.Fprintf(bw, "memdb_rows_inserted_total{%s} %d\n", l, c.Load())
+	}
+
+	header(bw, "memdb_rows_deleted_total", "counter", "Rows deleted.")
+	for l, c := range m.deleted.sorted(m.tableLabels) {
+		fmt.Fprintf(bw, "memdb_rows_deleted_total{%s} %d\n", l, c.Load())
+	}
+
+	header(bw, "memdb_index_seeks_total", "counter", "Index searches for keys built from query arguments.")
+	for l, c := range m.seeks.sorted(m.indexLabels) {
+		fmt.Fprintf(bw, "memdb_index_seeks_total{%s} %d\n", l, c.Load())
+	}
+
+	header(bw, "memdb_query_keys_scanned", "histogram", "Keys scanned by queries, reported when iteration stops.")
+	for l, h := range m.scanned.sorted(m.indexLabels) {
+		h.write(bw, "memdb_query_keys_scanned", l, 1)
+	}
+
+	return bw.Flush()
+}
+
+// tableLabels returns labels of the table series formatted in Prometheus text format.
+func (m *Metrics) tableLabels(l labels) string {
+	table, ok := m.tableNames[l.table]
+	if !ok {
+		table = strconv.FormatUint(l.table, 10)
+	}
+	return `table="` + labelEscaper.Replace(table) + `"`
+}
+
+// indexLabels returns labels of the index series formatted in Prometheus text format.
+func (m *Metrics) indexLabels(l labels) string {
+	index, ok := m.indexNames[l]
+	if !ok {
+		index = strconv.FormatUint(l.index, 10)
+	}
+	return m.tableLabels(l) + `,index="` + labelEscaper.Replace(index) + `"`
+}
+
+func header(w io.Writer, name, metricType, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+// labels identifies the series. Index is not used by series of tables.
+type labels struct {
+	table uint64
+	index uint64
+}
+
+// series stores values by labels. Values of tables and indices known upfront are looked up without locking.
+type series[T any] struct {
+	static map[labels]*T
+	newFn  func() *T
+
+	mu      sync.Mutex
+	dynamic map[labels]*T
+}
+
+func newSeries[T any](known []labels, newFn func() *T) *series[T] {
+	s := &series[T]{
+		static:  make(map[labels]*T, len(known)),
+		newFn:   newFn,
+		dynamic: map[labels]*T{},
+	}
+	for _, l := range known {
+		s.static[l] = newFn()
+	}
+	return s
+}
+
+func (s *series[T]) get(l labels) *T {
+	if v, ok := s.static[l]; ok {
+		return v
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v, ok := s.dynamic[l]
+	if !ok {
+		v = s.newFn()
+		s.dynamic[l] = v
+	}
+	return v
+}
+
+// sorted returns values ordered by their formatted labels.
+func (s *series[T]) sorted(format func(labels) string) iter.Seq2[string, *T] {
+	s.mu.Lock()
+	values := make(map[string]*T, len(s.static)+len(s.dynamic))
+	for l, v := range s.dynamic {
+		values[format(l)] = v
+	}
+	s.mu.Unlock()
+
+	for l, v := range s.static {
+		values[format(l)] = v
+	}
+
+	return func(yield func(string, *T) bool) {
+		for _, l := range slices.Sorted(maps.Keys(values)) {
+			if !yield(l, values[l]) {
+				return
+			}
+		}
+	}
+}
+
+// histogram counts observed values in buckets defined by their upper bounds.
+type histogram struct {
+	bounds []uint64
+	counts []atomic.Uint64
+	sum    atomic.Uint64
+}
+
+func newHistogram(bounds []uint64) *histogram {
+	return &histogram{
+		bounds: bounds,
+		// The last bucket counts values greater than all the bounds.
+		counts: make([]atomic.Uint64, len(bounds)+1),
+	}
+}
+
+func (h *histogram) observe(v uint64) {
+	i, _ := slices.BinarySearch(h.bounds, v)
+	h.counts[i].Add(1)
+	h.sum.Add(v)
+}
+
+func (h *histogram) count() uint64 {
+	var count uint64
+	for i := range h.counts {
+		count += h.counts[i].Load()
+	}
+	return count
+}
+
+// write writes the histogram. Bounds and sum are divided by unit.
+func (h *histogram) write(w io.Writer, name, labels string, unit float64) {
+	prefix := labels
+	if prefix != "" {
+		prefix += ","
+	}
+
+	var count uint64
+	for i, bound := range h.bounds {
+		count += h.counts[i].Load()
+		fmt.Fprintf(w, "%s_bucket{%sle=\"%s\"} %d\n", name, prefix, formatFloat(float64(bound)/unit), count)
+	}
+	count += h.counts[len(h.bounds)].Load()
+	fmt.Fprintf(w, "%s_bucket{%sle=\"+Inf\"} %d\n", name, prefix, count)
+
+	if labels != "" {
+		labels = "{" + labels + "}"
+	}
+	fmt.Fprintf(w, "%s_sum%s %s\n", name, labels, formatFloat(float64(h.sum.Load())/unit))
+	fmt.Fprintf(w, "%s_count%s %d\n", name, labels, count)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
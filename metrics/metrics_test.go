@@ -0,0 +1,99 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/outofforest/memdb"
+	"github.com/outofforest/memdb/indices"
+)
+
+type entity struct {
+	ID   memdb.ID
+	Name string
+}
+
+var (
+	e         entity
+	nameIndex = indices.NewFieldIndex(&e, &e.Name)
+)
+
+func TestMetrics(t *testing.T) {
+	requireT := require.New(t)
+
+	config := memdb.Config{
+		Entities: []reflect.Type{reflect.TypeFor[entity]()},
+		Indices:  []memdb.Index{nameIndex},
+	}
+	m := New(config)
+	config.Instrumentation = m
+	db, err := memdb.NewMemDB(config)
+	requireT.NoError(err)
+
+	table := memdb.TableID(reflect.TypeFor[entity]())
+	txn := db.Txn(true)
+	for i := range 3 {
+		_, err := txn.Insert(table, unsafe.Pointer(&entity{ID: memdb.ID{byte(i + 1)}, Name: "a"}))
+		requireT.NoError(err)
+	}
+	_, err = txn.Delete(table, unsafe.Pointer(&entity{ID: memdb.ID{1}}))
+	requireT.NoError(err)
+	requireT.NoError(txn.Commit())
+
+	txn = db.Txn(false)
+	it, err := txn.Iterator(table, nameIndex.ID(), "a")
+	requireT.NoError(err)
+	for obj := it.Next(); obj != nil; obj = it.Next() {
+	}
+
+	// Events of unknown tables are labeled with IDs.
+	m.RowsInserted(1, 5)
+
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	requireT.Equal("text/plain; version=0.0.4; charset=utf-8", w.Header().Get("Content-Type"))
+
+	body := w.Body.String()
+	for _, line := range []string{
+		"# TYPE memdb_transactions_started_total counter",
+		`memdb_transactions_started_total{mode="read"} 1`,
+		`memdb_transactions_started_total{mode="write"} 1`,
+		"memdb_transactions_committed_total 1",
+		"# TYPE memdb_commit_duration_seconds histogram",
+		`memdb_commit_duration_seconds_bucket{le="+Inf"} 1`,
+		"memdb_commit_duration_seconds_count 1",
+		`memdb_rows_inserted_total{table="1"} 5`,
+		`memdb_rows_inserted_total{table="metrics.entity"} 3`,
+		`memdb_rows_deleted_total{table="metrics.entity"} 1`,
+		`memdb_index_seeks_total{table="metrics.entity",index="Name"} 1`,
+		`memdb_index_seeks_total{table="metrics.entity",index="id"} 0`,
+		`memdb_query_keys_scanned_bucket{table="metrics.entity",index="Name",le="1"} 0`,
+		`memdb_query_keys_scanned_bucket{table="metrics.entity",index="Name",le="10"} 1`,
+		`memdb_query_keys_scanned_sum{table="metrics.entity",index="Name"} 2`,
+		`memdb_query_keys_scanned_count{table="metrics.entity",index="Name"} 1`,
+	} {
+		requireT.Contains(body, line+"\n")
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	requireT := require.New(t)
+
+	h := newHistogram([]uint64{1, 10})
+	for _, v := range []uint64{0, 1, 2, 10, 11} {
+		h.observe(v)
+	}
+
+	w := httptest.NewRecorder()
+	h.write(w, "h", `l="v"`, 10)
+	requireT.Equal(`h_bucket{l="v",le="0.1"} 2
+h_bucket{l="v",le="1"} 4
+h_bucket{l="v",le="+Inf"} 5
+h_sum{l="v"} 2.4
+h_count{l="v"} 5
+`, w.Body.String())
+}
@@ -0,0 +1,147 @@
+package memdb
+
+import (
+	"maps"
+	"reflect"
+	"unsafe"
+
+	"github.com/pkg/errors"
+
+	"github.com/outofforest/iradix"
+)
+
+// AddIndex adds index to the table storing entities of the index type. Index is filled with entities existing
+// in the table. Schema change becomes visible to other transactions when this one is committed.
+func (txn *Txn) AddIndex(index Index) error {
+	if !txn.write {
+		return errors.New("schema can't be modified by read-only transaction")
+	}
+
+	s := txn.getState()
+	eType := index.Type()
+	table := TableID(eType)
+	t, ok := s.schema[table]
+	if !ok {
+		return errors.Errorf("index for undefined entity %s", eType)
+	}
+
+	indexSchema, err := s.newIndexSchema(eType, t, index)
+	if err != nil {
+		return err
+	}
+
+	idSchema := t.indices[IDIndexID]
+	indexTxn := txn.writableIndex(indexSchema.id)
+	sc := &txn.scratch
+	m := sc.mark()
+	it := txn.readableIndex(idSchema.id, false).Root().Iterator()
+	for obj := it.Next(); obj != nil; obj = it.Next() {
+		id, err := primaryKey(sc, idSchema, obj)
+		if err != nil {
+			return err
+		}
+		addToIndex(sc, indexTxn, indexSchema, obj, id)
+		sc.release(m)
+	}
+
+	t = t.clone()
+	t.indices[index.ID()] = indexSchema
+	s.schema = maps.Clone(s.schema)
+	s.schema[table] = t
+
+	return nil
+}
+
+// DropIndex removes index from the table. Schema change becomes visible to other transactions when this one
+// is committed.
+func (txn *Txn) DropIndex(table, index uint64) error {
+	if !txn.write {
+		return errors.New("schema can't be modified by read-only transaction")
+	}
+	if index == IDIndexID {
+		return errors.New("ID index can't be dropped")
+	}
+
+	s := txn.getState()
+	t, ok := s.schema[table]
+	if !ok {
+		return errors.Errorf("invalid table '%d'", table)
+	}
+	indexSchema, ok := t.indices[index]
+	if !ok {
+		return errors.Errorf("invalid index '%d'", index)
+	}
+	for _, ref := range t.references {
+		if ref.index == index {
+			return errors.Errorf("index is used by reference %s", ref.name)
+		}
+	}
+
+	// Tree can't be removed, but its content is released.
+	s.tree.Set(indexSchema.id, iradix.NewTxn(iradix.New[unsafe.Pointer]()))
+
+	t = t.clone()
+	delete(t.indices, index)
+	s.schema = maps.Clone(s.schema)
+	s.schema[table] = t
+
+	return nil
+}
+
+// AddTable adds table storing entities of the given type together with its primary key and indices. If pk is nil,
+// entity must have ID field used as the primary key. Schema change becomes visible to other transactions when this one
+// is committed.
+func (txn *Txn) AddTable(eType reflect.Type, pk Index, indices ...Index) error {
+	if !txn.write {
+		return errors.New("schema can't be modified by read-only transaction")
+	}
+
+	s := txn.getState()
+	table := TableID(eType)
+	if _, exists := s.schema[table]; exists {
+		return errors.Errorf("table for entity %s already exists", eType)
+	}
+
+	t, err := s.newTableSchema(eType, pk, indices)
+	if err != nil {
+		return err
+	}
+
+	s.schema = maps.Clone(s.schema)
+	s.schema[table] = t
+
+	return nil
+}
+
+// DropTable removes table together with its indices. Schema change becomes visible to other transactions when
+// this one is committed.
+func (txn *Txn) DropTable(table uint64) error {
+	if !txn.write {
+		return errors.New("schema can't be modified by read-only transaction")
+	}
+
+	s := txn.getState()
+	t, ok := s.schema[table]
+	if !ok {
+		return errors.Errorf("invalid table '%d'", table)
+	}
+	if len(t.references) > 0 || len(t.referencedBy) > 0 {
+		return errors.Errorf("table of entity %s is used by references", t.eType)
+	}
+	if len(t.views) > 0 || t.isView {
+		return errors.Errorf("table of entity %s is used by views", t.eType)
+	}
+
+	// Trees can't be removed, but their content is released.
+	for _, indexSchema := range t.indices {
+		s.tree.Set(indexSchema.id, iradix.NewTxn(iradix.New[unsafe.Pointer]()))
+		if indexSchema.sequenceID != 0 {
+			s.tree.Set(indexSchema.sequenceID, iradix.NewTxn(iradix.New[unsafe.Pointer]()))
+		}
+	}
+
+	s.schema = maps.Clone(s.schema)
+	delete(s.schema, table)
+
+	return nil
+}
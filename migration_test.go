@@ -0,0 +1,201 @@
+package memdb_test
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/outofforest/memdb"
+	"github.com/outofforest/memdb/indices"
+)
+
+func collectObjects(t *testing.T, txn *memdb.Txn, index uint64, args ...any) []*TestObject {
+	it, err := txn.Iterator(objectTableID, index, args...)
+	require.NoError(t, err)
+	var result []*TestObject
+	for o := it.Next(); o != nil; o = it.Next() {
+		result = append(result, (*TestObject)(o))
+	}
+	return result
+}
+
+func TestMemDB_AddIndex(t *testing.T) {
+	db := testDB(t)
+
+	obj1 := &TestObject{ID: memdb.ID{1}, Foo: "abc", Baz: "b"}
+	obj2 := &TestObject{ID: memdb.ID{2}, Foo: "def", Baz: "a"}
+
+	txn := db.Txn(true)
+	_, err := txn.Insert(objectTableID, unsafe.Pointer(obj1))
+	require.NoError(t, err)
+	_, err = txn.Insert(objectTableID, unsafe.Pointer(obj2))
+	require.NoError(t, err)
+	require.NoError(t, txn.Commit())
+
+	oldTxn := db.Txn(false)
+
+	indexBaz := indices.NewFieldIndex(&o, &o.Baz)
+	require.NoError(t, db.AddIndex(indexBaz))
+	require.Error(t, db.AddIndex(indexBaz))
+
+	// Transactions started before the index was added don't see it.
+	_, err = oldTxn.First(objectTableID, indexBaz.ID(), "a")
+	require.Error(t, err)
+
+	txn = db.Txn(true)
+	require.Equal(t, []*TestObject{obj2, obj1}, collectObjects(t, txn, indexBaz.ID()))
+
+	// New entities are stored in the index.
+	obj3 := &TestObject{ID: memdb.ID{3}, Foo: "ghi", Baz: "a"}
+	_, err = txn.Insert(objectTableID, unsafe.Pointer(obj3))
+	require.NoError(t, err)
+	require.Equal(t, []*TestObject{obj2, obj3}, collectObjects(t, txn, indexBaz.ID(), "a"))
+	require.NoError(t, txn.Commit())
+}
+
+func TestMemDB_AddIndexRollback(t *testing.T) {
+	db := testDB(t)
+
+	indexBaz := indices.NewFieldIndex(&o, &o.Baz)
+
+	txn := db.Txn(true)
+	require.NoError(t, txn.AddIndex(indexBaz))
+	obj1 := &TestObject{ID: memdb.ID{1}, Baz: "a"}
+	_, err := txn.Insert(objectTableID, unsafe.Pointer(obj1))
+	require.NoError(t, err)
+	require.Equal(t, []*TestObject{obj1}, collectObjects(t, txn, indexBaz.ID()))
+
+	// Transaction is not committed.
+	txn = db.Txn(false)
+	_, err = txn.First(objectTableID, indexBaz.ID())
+	require.Error(t, err)
+	require.Error(t, txn.AddIndex(indexBaz))
+}
+
+func TestMemDB_DropIndex(t *testing.T) {
+	db := testDB(t)
+
+	obj1 := &TestObject{ID: memdb.ID{1}, Foo: "abc"}
+
+	txn := db.Txn(true)
+	_, err := txn.Insert(objectTableID, unsafe.Pointer(obj1))
+	require.NoError(t, err)
+	require.NoError(t, txn.Commit())
+
+	oldTxn := db.Txn(false)
+
+	require.Error(t, db.DropIndex(objectTableID, memdb.IDIndexID))
+	require.NoError(t, db.DropIndex(objectTableID, indexFoo.ID()))
+	require.Error(t, db.DropIndex(objectTableID, indexFoo.ID()))
+
+	// Transactions started before the index was dropped still use it.
+	require.Equal(t, []*TestObject{obj1}, collectObjects(t, oldTxn, indexFoo.ID()))
+
+	txn = db.Txn(true)
+	_, err = txn.First(objectTableID, indexFoo.ID())
+	require.Error(t, err)
+
+	obj2 := &TestObject{ID: memdb.ID{2}, Foo: "abc"}
+	_, err = txn.Insert(objectTableID, unsafe.Pointer(obj2))
+	require.NoError(t, err)
+	require.NoError(t, txn.Commit())
+
+	// Index is added again.
+	require.NoError(t, db.AddIndex(indexFoo))
+	require.Equal(t, []*TestObject{obj1, obj2}, collectObjects(t, db.Txn(false), indexFoo.ID(), "abc"))
+}
+
+func TestMemDB_AddTable(t *testing.T) {
+	db := testDB(t)
+
+	oldTxn := db.Txn(false)
+
+	require.NoError(t, db.AddTable(reflect.TypeFor[TestPlace](), nil, placeNameIndex))
+	require.Error(t, db.AddTable(reflect.TypeFor[TestPlace](), nil))
+	require.Error(t, db.AddTable(reflect.TypeFor[TestPerson](), nil, placeNameIndex))
+	require.Error(t, db.AddTable(reflect.TypeFor[testNoID](), nil))
+
+	place := &TestPlace{ID: memdb.ID{1}, Name: "Office"}
+
+	// Transactions started before the table was added don't see it.
+	_, err := oldTxn.First(placesTableID, memdb.IDIndexID)
+	require.Error(t, err)
+
+	txn := db.Txn(true)
+	_, err = txn.Insert(placesTableID, unsafe.Pointer(place))
+	require.NoError(t, err)
+	require.NoError(t, txn.Commit())
+
+	txn = db.Txn(false)
+	p, err := txn.First(placesTableID, placeNameIndex.ID(), "Office")
+	require.NoError(t, err)
+	require.Equal(t, place, (*TestPlace)(p))
+
+	// Existing tables are not affected.
+	_, err = txn.First(objectTableID, indexFoo.ID())
+	require.NoError(t, err)
+}
+
+func TestMemDB_AddTablePrimaryKey(t *testing.T) {
+	db := testDB(t)
+
+	var v testNoID
+	pk := indices.NewFieldIndex(&v, &v.Foo)
+	eType := reflect.TypeFor[testNoID]()
+	table := memdb.TableID(eType)
+
+	require.ErrorContains(t, db.AddTable(eType, indexFoo), "primary key is defined for entity")
+	require.ErrorContains(t, db.AddTable(eType, indices.NewCountIndex(pk)), "primary key of entity")
+	require.NoError(t, db.AddTable(eType, pk))
+
+	e1 := &testNoID{Foo: "foo"}
+	e2 := &testNoID{Foo: "bar"}
+
+	txn := db.Txn(true)
+	_, err := txn.Insert(table, unsafe.Pointer(e1))
+	require.NoError(t, err)
+	_, err = txn.Insert(table, unsafe.Pointer(e2))
+	require.NoError(t, err)
+	require.NoError(t, txn.Commit())
+
+	txn = db.Txn(false)
+	e, err := txn.First(table, memdb.IDIndexID, "foo")
+	require.NoError(t, err)
+	require.Equal(t, e1, (*testNoID)(e))
+
+	it, err := txn.Iterator(table, memdb.IDIndexID)
+	require.NoError(t, err)
+	require.Equal(t, unsafe.Pointer(e2), it.Next())
+	require.Equal(t, unsafe.Pointer(e1), it.Next())
+	require.Nil(t, it.Next())
+}
+
+func TestMemDB_DropTable(t *testing.T) {
+	db := testDB(t)
+
+	obj1 := &TestObject{ID: memdb.ID{1}, Foo: "abc"}
+
+	txn := db.Txn(true)
+	_, err := txn.Insert(objectTableID, unsafe.Pointer(obj1))
+	require.NoError(t, err)
+	require.NoError(t, txn.Commit())
+
+	oldTxn := db.Txn(false)
+
+	require.Error(t, db.DropTable(placesTableID))
+	require.NoError(t, db.DropTable(objectTableID))
+
+	// Transactions started before the table was dropped still use it.
+	require.Equal(t, []*TestObject{obj1}, collectObjects(t, oldTxn, indexFoo.ID()))
+
+	txn = db.Txn(true)
+	_, err = txn.Insert(objectTableID, unsafe.Pointer(obj1))
+	require.Error(t, err)
+
+	// Table is created again, but empty.
+	require.NoError(t, txn.AddTable(reflect.TypeFor[TestObject](), nil, indexFoo))
+	require.Empty(t, collectObjects(t, txn, indexFoo.ID()))
+	require.NoError(t, txn.Commit())
+}
@@ -0,0 +1,168 @@
+package memdb
+
+import (
+	"encoding/binary"
+	stderrors "errors"
+	"fmt"
+	"hash/fnv"
+	"runtime"
+	"sync"
+	"unsafe"
+	"weak"
+
+	"github.com/pkg/errors"
+)
+
+// MutationError is reported when the stored object produces keys different from the ones it was inserted with,
+// meaning it has been modified in place.
+type MutationError struct {
+	Table uint64
+
+	// ID is the primary key the object was inserted with.
+	ID []byte
+
+	Index     uint64
+	IndexName string
+}
+
+func (e *MutationError) Error() string {
+	return fmt.Sprintf("object %x in table '%d' has been modified in place, keys of index '%s' differ", e.ID, e.Table,
+		e.IndexName)
+}
+
+// Verify checks that objects stored in the database haven't been modified in place since they were inserted.
+// Errors of all the detected mutations are returned. It requires Config.DetectMutations.
+func (db *MemDB) Verify() error {
+	if db.mutations == nil {
+		return errors.New("mutation detection is disabled")
+	}
+
+	s, _ := db.getRoot()
+	var errs []error
+	for table, t := range s.schema {
+		it := s.indexRoot(t.indices[IDIndexID].id).Iterator()
+		for obj := it.Next(); obj != nil; obj = it.Next() {
+			errs = append(errs, db.mutations.check(table, t, obj)...)
+		}
+	}
+	return stderrors.Join(errs...)
+}
+
+// mutationDetector stores digests of keys objects were inserted with.
+type mutationDetector struct {
+	onMutation func(err error)
+
+	mu      sync.RWMutex
+	records map[objectKey]*objectRecord
+}
+
+// objectKey identifies the object stored in the table. Weak pointer is used, so records don't keep objects alive
+// and record is not taken over by another object allocated at the same address.
+type objectKey struct {
+	table uint64
+	obj   weak.Pointer[byte]
+}
+
+type objectRecord struct {
+	id      []byte
+	digests map[uint64]uint64
+}
+
+func newMutationDetector(onMutation func(err error)) *mutationDetector {
+	return &mutationDetector{
+		onMutation: onMutation,
+		records:    map[objectKey]*objectRecord{},
+	}
+}
+
+// record records digests of keys of the inserted object. Record is removed when object is garbage collected.
+func (d *mutationDetector) record(table uint64, t *tableSchema, obj unsafe.Pointer) {
+	id, _ := primaryKey(nil, t.indices[IDIndexID], obj)
+	r := &objectRecord{
+		id:      id,
+		digests: make(map[uint64]uint64, len(t.indices)),
+	}
+	for indexID, indexSchema := range t.indices {
+		r.digests[indexID] = keysDigest(indexSchema, obj, id)
+	}
+
+	key := objectKey{table: table, obj: weak.Make((*byte)(obj))}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, exists := d.records[key]; !exists {
+		runtime.AddCleanup((*byte)(obj), d.remove, key)
+	}
+	d.records[key] = r
+}
+
+func (d *mutationDetector) remove(key objectKey) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.records, key)
+}
+
+// check returns errors for indices of the object which keys have changed since it was inserted.
+func (d *mutationDetector) check(table uint64, t *tableSchema, obj unsafe.Pointer) []error {
+	d.mu.RLock()
+	r, ok := d.records[objectKey{table: table, obj: weak.Make((*byte)(obj))}]
+	d.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	// Recorded primary key is appended to keys of non-unique indices, so the change of the ID is reported
+	// by the ID index only.
+	var errs []error
+	for indexID, digest := range r.digests {
+		// Indices added after the object was inserted are not checked.
+		indexSchema, ok := t.indices[indexID]
+		if !ok || keysDigest(indexSchema, obj, r.id) == digest {
+			continue
+		}
+		errs = append(errs, &MutationError{
+			Table:     table,
+			ID:        r.id,
+			Index:     indexID,
+			IndexName: indexSchema.name,
+		})
+	}
+	return errs
+}
+
+// verifyRead checks the object returned by the query. Mutation is reported to the callback configured,
+// otherwise it panics.
+func (d *mutationDetector) verifyRead(table uint64, t *tableSchema, obj unsafe.Pointer) {
+	errs := d.check(table, t, obj)
+	if len(errs) == 0 {
+		return
+	}
+	err := stderrors.Join(errs...)
+	if d.onMutation == nil {
+		panic(err)
+	}
+	d.onMutation(err)
+}
+
+// keysDigest computes the digest of all the keys produced by the index for the object.
+func keysDigest(indexSchema *IndexSchema, obj unsafe.Pointer, id []byte) uint64 {
+	h := fnv.New64a()
+	write := func(key []byte) {
+		_, _ = h.Write(binary.BigEndian.AppendUint64(nil, uint64(len(key))))
+		_, _ = h.Write(key)
+	}
+
+	switch indexer := indexSchema.Indexer.(type) {
+	case AggregateIndexer:
+		write(aggregateKey(nil, indexer, obj))
+	case MultiKeyIndexer:
+		for _, key := range indexer.KeysFromObject(obj) {
+			write(multiKey(nil, indexSchema, key, id))
+		}
+	default:
+		write(indexKey(nil, indexSchema, obj, id))
+	}
+	return h.Sum64()
+}
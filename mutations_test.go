@@ -0,0 +1,139 @@
+package memdb_test
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/outofforest/memdb"
+)
+
+func TestMemDB_DetectMutations(t *testing.T) {
+	requireT := require.New(t)
+
+	var detected []error
+	db, err := memdb.NewMemDB(memdb.Config{
+		Entities:        []reflect.Type{reflect.TypeFor[TestObject]()},
+		Indices:         []memdb.Index{indexFoo, indexFooBaz, indexFooCount},
+		DetectMutations: true,
+		OnMutation: func(err error) {
+			detected = append(detected, err)
+		},
+	})
+	requireT.NoError(err)
+
+	obj1 := &TestObject{ID: memdb.ID{1}, Foo: "a", Baz: "b"}
+	obj2 := &TestObject{ID: memdb.ID{2}, Foo: "a", Baz: "b"}
+	txn := db.Txn(true)
+	requireT.NoError(insert(txn, objectTableID, unsafe.Pointer(obj1)))
+	_, err = txn.InsertBatch(objectTableID, []unsafe.Pointer{unsafe.Pointer(obj2)})
+	requireT.NoError(err)
+	requireT.NoError(txn.Commit())
+	requireT.NoError(db.Verify())
+
+	// Field not used by any index might be modified.
+	obj1.Int8 = 1
+	requireT.NoError(db.Verify())
+
+	obj1.Baz = "c"
+	err = db.Verify()
+	var mutationErr *memdb.MutationError
+	requireT.ErrorAs(err, &mutationErr)
+	requireT.Equal(&memdb.MutationError{
+		Table:     objectTableID,
+		ID:        obj1.ID[:],
+		Index:     indexFooBaz.ID(),
+		IndexName: indexFooBaz.Name(),
+	}, mutationErr)
+
+	txn = db.Txn(false)
+	_, err = txn.First(objectTableID, memdb.IDIndexID, obj2.ID)
+	requireT.NoError(err)
+	requireT.Empty(detected)
+	_, err = txn.First(objectTableID, memdb.IDIndexID, obj1.ID)
+	requireT.NoError(err)
+	requireT.Len(detected, 1)
+	requireT.ErrorAs(detected[0], &mutationErr)
+	requireT.Equal(indexFooBaz.ID(), mutationErr.Index)
+
+	// Change of the primary key is reported by ID index only.
+	obj1.Baz = "b"
+	id2 := obj2.ID
+	obj2.ID = memdb.ID{3}
+	err = db.Verify()
+	requireT.ErrorAs(err, &mutationErr)
+	requireT.Equal(&memdb.MutationError{
+		Table:     objectTableID,
+		ID:        id2[:],
+		Index:     memdb.IDIndexID,
+		IndexName: "id",
+	}, mutationErr)
+	requireT.Equal([]uint64{memdb.IDIndexID}, indicesOf(err))
+
+	// Object inserted again is recorded again.
+	txn = db.Txn(true)
+	_, err = txn.Delete(objectTableID, unsafe.Pointer(&TestObject{ID: id2}))
+	requireT.NoError(err)
+	requireT.NoError(insert(txn, objectTableID, unsafe.Pointer(obj2)))
+	requireT.NoError(txn.Commit())
+	requireT.NoError(db.Verify())
+
+	detected = nil
+	obj2.Foo = "b"
+	it, err := db.Txn(false).Iterator(objectTableID, indexFoo.ID())
+	requireT.NoError(err)
+	for o := it.Next(); o != nil; o = it.Next() {
+	}
+	requireT.Len(detected, 1)
+	requireT.ElementsMatch([]uint64{indexFoo.ID(), indexFooBaz.ID(), indexFooCount.ID()},
+		indicesOf(detected[0]))
+
+	// Objects returned by distinct are verified too.
+	detected = nil
+	obj2.Foo = "a"
+	obj1.Baz = "c"
+	distinct, err := db.Txn(false).Distinct(objectTableID, indexFoo.ID())
+	requireT.NoError(err)
+	for values, o := distinct.Next(); o != nil; values, o = distinct.Next() {
+		requireT.Len(values, 1)
+	}
+	requireT.Len(detected, 1)
+	requireT.Equal([]uint64{indexFooBaz.ID()}, indicesOf(detected[0]))
+}
+
+func indicesOf(err error) []uint64 {
+	var result []uint64
+	for _, err := range err.(interface{ Unwrap() []error }).Unwrap() {
+		result = append(result, err.(*memdb.MutationError).Index)
+	}
+	return result
+}
+
+func TestMemDB_DetectMutationsPanics(t *testing.T) {
+	requireT := require.New(t)
+
+	db, err := memdb.NewMemDB(memdb.Config{
+		Entities:        []reflect.Type{reflect.TypeFor[TestObject]()},
+		Indices:         []memdb.Index{indexFoo},
+		DetectMutations: true,
+	})
+	requireT.NoError(err)
+
+	obj := &TestObject{ID: memdb.ID{1}, Foo: "a"}
+	txn := db.Txn(true)
+	requireT.NoError(insert(txn, objectTableID, unsafe.Pointer(obj)))
+	requireT.NoError(txn.Commit())
+
+	obj.Foo = "b"
+	requireT.Panics(func() {
+		_, _ = db.Txn(false).First(objectTableID, memdb.IDIndexID, obj.ID)
+	})
+
+	db, err = memdb.NewMemDB(memdb.Config{
+		Entities: []reflect.Type{reflect.TypeFor[TestObject]()},
+	})
+	requireT.NoError(err)
+	requireT.ErrorContains(db.Verify(), "mutation detection is disabled")
+}
@@ -0,0 +1,134 @@
+package query
+
+import (
+	"fmt"
+	"slices"
+	"unsafe"
+
+	"github.com/outofforest/memdb"
+)
+
+// Pair is the pair of joined entities.
+type Pair[L, R any] struct {
+	Left  *L
+	Right *R
+}
+
+// JoinQuery joins entities returned by two queries.
+type JoinQuery[L, R any] struct {
+	left       *Query[L]
+	right      *Query[R]
+	leftField  memdb.Index
+	rightField memdb.Index
+}
+
+// Join joins each entity returned by the left query with entities returned by the right query having the right field
+// equal to the left field of the left entity. Right query is executed once for each left entity, so its limit applies
+// to the entities joined with single left entity. If index of the right table can be used to match the right field,
+// it is used to find the joined entities, otherwise nested loop is executed.
+func Join[L, R any](left *Query[L], right *Query[R], leftField, rightField memdb.Index) *JoinQuery[L, R] {
+	return &JoinQuery[L, R]{
+		left:       left,
+		right:      right,
+		leftField:  leftField,
+		rightField: rightField,
+	}
+}
+
+// All returns all the joined pairs.
+func (j *JoinQuery[L, R]) All() ([]Pair[L, R], error) {
+	leftRef, rightRef, err := j.fields()
+	if err != nil {
+		return nil, err
+	}
+	rightPlan, err := j.rightQuery(rightRef, nil).plan()
+	if err != nil {
+		return nil, err
+	}
+	leftEntities, err := j.left.All()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Pair[L, R]
+	for _, l := range leftEntities {
+		q := j.rightQuery(rightRef, leftRef.value(unsafe.Pointer(l)))
+		rightEntities, err := q.execute(rightPlan, q.conds)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range rightEntities {
+			result = append(result, Pair[L, R]{Left: l, Right: r})
+		}
+	}
+	return result, nil
+}
+
+// JoinPlan describes how the join is executed.
+type JoinPlan struct {
+	// Left is the plan of the left query.
+	Left Plan
+
+	// Right is the plan of the right query executed for each left entity.
+	Right Plan
+
+	// IndexJoin is true if joined entities are found using the index, false if nested loop is executed.
+	IndexJoin bool
+}
+
+// String returns human-readable representation of the plan.
+func (p JoinPlan) String() string {
+	method := "nested loop"
+	if p.IndexJoin {
+		method = "index join"
+	}
+	return fmt.Sprintf("%s: left: %s; right: %s", method, p.Left, p.Right)
+}
+
+// Explain returns the plan used to execute the join.
+func (j *JoinQuery[L, R]) Explain() (JoinPlan, error) {
+	_, rightRef, err := j.fields()
+	if err != nil {
+		return JoinPlan{}, err
+	}
+	left, err := j.left.Explain()
+	if err != nil {
+		return JoinPlan{}, err
+	}
+	q := j.rightQuery(rightRef, nil)
+	right, err := q.plan()
+	if err != nil {
+		return JoinPlan{}, err
+	}
+
+	joinCond := len(q.conds) - 1
+	return JoinPlan{
+		Left:      left,
+		Right:     right.Plan,
+		IndexJoin: slices.Contains(right.prefix, joinCond),
+	}, nil
+}
+
+func (j *JoinQuery[L, R]) fields() (fieldRef, fieldRef, error) {
+	leftRef, err := newFieldRef(j.leftField)
+	if err != nil {
+		return fieldRef{}, fieldRef{}, err
+	}
+	rightRef, err := newFieldRef(j.rightField)
+	if err != nil {
+		return fieldRef{}, fieldRef{}, err
+	}
+	return leftRef, rightRef, nil
+}
+
+// rightQuery returns the right query with the join condition appended. If value is nil, the condition is used
+// for planning only.
+func (j *JoinQuery[L, R]) rightQuery(rightRef fieldRef, value any) *Query[R] {
+	q := *j.right
+	cond := condition{field: rightRef, op: Eq, arg: value}
+	if value != nil {
+		cond = newCondition(rightRef, Eq, value)
+	}
+	q.conds = append(append(make([]condition, 0, len(j.right.conds)+1), j.right.conds...), cond)
+	return &q
+}
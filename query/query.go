@@ -0,0 +1,576 @@
+package query
+
+import (
+	"bytes"
+	"fmt"
+	"iter"
+	"reflect"
+	"slices"
+	"strings"
+	"unsafe"
+
+	"github.com/pkg/errors"
+
+	"github.com/outofforest/memdb"
+)
+
+// Op is the operator used to compare field value with the value of the condition.
+type Op uint8
+
+const (
+	// Eq matches entities having field equal to the value.
+	Eq Op = iota + 1
+
+	// Ne matches entities having field not equal to the value.
+	Ne
+
+	// Lt matches entities having field lower than the value.
+	Lt
+
+	// Le matches entities having field lower than or equal to the value.
+	Le
+
+	// Gt matches entities having field greater than the value.
+	Gt
+
+	// Ge matches entities having field greater than or equal to the value.
+	Ge
+)
+
+func (op Op) String() string {
+	switch op {
+	case Eq:
+		return "="
+	case Ne:
+		return "!="
+	case Lt:
+		return "<"
+	case Le:
+		return "<="
+	case Gt:
+		return ">"
+	case Ge:
+		return ">="
+	default:
+		return fmt.Sprintf("op(%d)", op)
+	}
+}
+
+// Query selects entities of type T. Fields used in conditions and ordering are defined by single-field indices,
+// e.g. indices.NewFieldIndex, those indices don't need to be added to the database. Index used to execute the query
+// is selected from the indices defined for the table.
+type Query[T any] struct {
+	txn   *memdb.Txn
+	table uint64
+	conds []condition
+	order []order
+	limit int
+	err   error
+}
+
+// New creates new query selecting entities of type T.
+func New[T any](txn *memdb.Txn) *Query[T] {
+	return &Query[T]{
+		txn:   txn,
+		table: memdb.TableID(reflect.TypeFor[T]()),
+	}
+}
+
+// Where adds condition comparing the field with the value.
+func (q *Query[T]) Where(field memdb.Index, op Op, value any) *Query[T] {
+	f, err := newFieldRef(field)
+	if err != nil {
+		q.setErr(err)
+		return q
+	}
+	if op < Eq || op > Ge {
+		q.setErr(errors.Errorf("invalid operator %s", op))
+		return q
+	}
+	q.conds = append(q.conds, newCondition(f, op, value))
+	return q
+}
+
+// OrderBy sorts entities by the field in ascending order.
+func (q *Query[T]) OrderBy(field memdb.Index) *Query[T] {
+	return q.orderBy(field, false)
+}
+
+// OrderByDesc sorts entities by the field in descending order.
+func (q *Query[T]) OrderByDesc(field memdb.Index) *Query[T] {
+	return q.orderBy(field, true)
+}
+
+// Limit limits the number of returned entities.
+func (q *Query[T]) Limit(n int) *Query[T] {
+	q.limit = n
+	return q
+}
+
+// All returns all the entities matching the query.
+func (q *Query[T]) All() ([]*T, error) {
+	p, err := q.plan()
+	if err != nil {
+		return nil, err
+	}
+	return q.execute(p, q.conds)
+}
+
+// Seq returns the sequence of entities matching the query. If the index doesn't return entities in the requested
+// order, they are read and sorted before the first one is returned. Sequence may be iterated only once.
+func (q *Query[T]) Seq() (iter.Seq[*T], error) {
+	p, err := q.plan()
+	if err != nil {
+		return nil, err
+	}
+	if len(p.sort) == 0 {
+		return q.stream(p, q.conds)
+	}
+	result, err := q.execute(p, q.conds)
+	if err != nil {
+		return nil, err
+	}
+	return slices.Values(result), nil
+}
+
+// First returns the first entity matching the query. Nil is returned if there is no such entity.
+func (q *Query[T]) First() (*T, error) {
+	limit := q.limit
+	q.limit = 1
+	result, err := q.All()
+	q.limit = limit
+	if err != nil || len(result) == 0 {
+		return nil, err
+	}
+	return result[0], nil
+}
+
+// Explain returns the plan used to execute the query.
+func (q *Query[T]) Explain() (Plan, error) {
+	p, err := q.plan()
+	if err != nil {
+		return Plan{}, err
+	}
+	return p.Plan, nil
+}
+
+func (q *Query[T]) orderBy(field memdb.Index, desc bool) *Query[T] {
+	f, err := newFieldRef(field)
+	if err != nil {
+		q.setErr(err)
+		return q
+	}
+	q.order = append(q.order, order{field: f, desc: desc})
+	return q
+}
+
+func (q *Query[T]) setErr(err error) {
+	if q.err == nil {
+		q.err = err
+	}
+}
+
+// Plan describes how the query is executed.
+type Plan struct {
+	// Index is the name of the index used to iterate over entities.
+	Index string
+
+	// Prefix lists fields of the index key matched by equality conditions.
+	Prefix []string
+
+	// Range is the field of the index key, following the prefix, used to seek to the first matching entity
+	// and to stop the iteration.
+	Range string
+
+	// Filter lists conditions evaluated on each visited entity.
+	Filter []string
+
+	// Sort lists fields entities are sorted by after they are collected. It is empty if index returns entities
+	// in the requested order.
+	Sort []string
+
+	// Limit is the maximum number of returned entities. Zero means no limit.
+	Limit int
+}
+
+// String returns human-readable representation of the plan.
+func (p Plan) String() string {
+	var sb strings.Builder
+	sb.WriteString("index " + p.Index)
+	if len(p.Prefix) > 0 {
+		sb.WriteString(", prefix [" + strings.Join(p.Prefix, ", ") + "]")
+	}
+	if p.Range != "" {
+		sb.WriteString(", range " + p.Range)
+	}
+	if len(p.Filter) > 0 {
+		sb.WriteString(", filter [" + strings.Join(p.Filter, ", ") + "]")
+	}
+	if len(p.Sort) > 0 {
+		sb.WriteString(", sort [" + strings.Join(p.Sort, ", ") + "]")
+	}
+	if p.Limit > 0 {
+		sb.WriteString(fmt.Sprintf(", limit %d", p.Limit))
+	}
+	return sb.String()
+}
+
+type fieldRef struct {
+	name    string
+	field   memdb.IndexField
+	indexer memdb.Indexer
+}
+
+func newFieldRef(index memdb.Index) (fieldRef, error) {
+	indexer, ok := index.Schema().Indexer.(memdb.FieldIndexer)
+	if !ok {
+		return fieldRef{}, errors.Errorf("index %q is not built from field", index.Name())
+	}
+	fields := indexer.Fields()
+	if len(fields) != 1 || len(indexer.Args()) != 1 {
+		return fieldRef{}, errors.Errorf("index %q is not built from single field", index.Name())
+	}
+	return fieldRef{
+		name:    index.Name(),
+		field:   fields[0],
+		indexer: indexer,
+	}, nil
+}
+
+// matches returns true if index component is built from the field.
+func (f fieldRef) matches(component memdb.IndexField) bool {
+	return f.field.Offset == component.Offset && f.field.Type == component.Type
+}
+
+// compare compares field values of two entities.
+func (f fieldRef) compare(o1, o2 unsafe.Pointer) int {
+	return f.order(bytes.Compare(f.key(o1), f.key(o2)))
+}
+
+// key returns the encoded value of the field.
+func (f fieldRef) key(o unsafe.Pointer) []byte {
+	b := make([]byte, f.indexer.SizeFromObject(o))
+	return b[:f.indexer.FromObject(b, o)]
+}
+
+// order converts the result of comparing encoded values to the order of field values.
+func (f fieldRef) order(cmp int) int {
+	if f.field.Desc {
+		return -cmp
+	}
+	return cmp
+}
+
+// value returns the value of the field usable as the argument of the index.
+func (f fieldRef) value(o unsafe.Pointer) any {
+	return reflect.NewAt(f.field.Type, unsafe.Add(o, f.field.Offset)).Elem().Interface()
+}
+
+type condition struct {
+	field fieldRef
+	op    Op
+	arg   any
+	key   []byte
+}
+
+func newCondition(f fieldRef, op Op, arg any) condition {
+	argDef := f.indexer.Args()[0]
+	key := make([]byte, argDef.SizeFromArg(arg))
+	return condition{
+		field: f,
+		op:    op,
+		arg:   arg,
+		key:   key[:argDef.FromArg(key, arg)],
+	}
+}
+
+func (c condition) String() string {
+	return fmt.Sprintf("%s %s", c.field.name, c.op)
+}
+
+// match checks if entity matches the condition.
+func (c condition) match(o unsafe.Pointer) bool {
+	cmp := c.field.order(bytes.Compare(c.field.key(o), c.key))
+	switch c.op {
+	case Eq:
+		return cmp == 0
+	case Ne:
+		return cmp != 0
+	case Lt:
+		return cmp < 0
+	case Le:
+		return cmp <= 0
+	case Gt:
+		return cmp > 0
+	default:
+		return cmp >= 0
+	}
+}
+
+type order struct {
+	field fieldRef
+	desc  bool
+}
+
+// plan is the execution plan of the query. Conditions are referenced by their positions.
+type plan struct {
+	Plan
+
+	index uint64
+
+	// prefix lists conditions providing values of the index key prefix.
+	prefix []int
+
+	// from is the condition used to seek to the first entity, or -1.
+	from int
+
+	// stop lists conditions which, when not met, end the iteration.
+	stop []int
+
+	// filter lists conditions evaluated on each visited entity.
+	filter []int
+
+	sort []order
+}
+
+type candidate struct {
+	id     uint64
+	schema *memdb.IndexSchema
+	fields []memdb.IndexField
+	prefix int
+	rng    bool
+	sorted bool
+}
+
+// better returns true if the candidate is expected to visit fewer entities than the other one.
+func (c candidate) better(other candidate) bool {
+	switch {
+	case c.prefix != other.prefix:
+		return c.prefix > other.prefix
+	case c.rng != other.rng:
+		return c.rng
+	case c.sorted != other.sorted:
+		return c.sorted
+	case len(c.fields) != len(other.fields):
+		return len(c.fields) < len(other.fields)
+	default:
+		return c.schema.Name() < other.schema.Name()
+	}
+}
+
+func (q *Query[T]) plan() (plan, error) {
+	if q.err != nil {
+		return plan{}, q.err
+	}
+
+	indices, err := q.txn.Indices(q.table)
+	if err != nil {
+		return plan{}, err
+	}
+
+	// Ordering by fields fixed by equality conditions is a noop.
+	sortOrder := make([]order, 0, len(q.order))
+	for _, o := range q.order {
+		if q.findCondition(o.field.field, Eq) < 0 {
+			sortOrder = append(sortOrder, o)
+		}
+	}
+
+	best := candidate{
+		id:     memdb.IDIndexID,
+		schema: indices[memdb.IDIndexID],
+	}
+	if fieldIndexer, ok := best.schema.Indexer.(memdb.FieldIndexer); ok {
+		best.fields = fieldIndexer.Fields()
+	}
+	best.prefix, best.rng, best.sorted = q.match(best.fields, sortOrder)
+
+	for id, schema := range indices {
+		if id == memdb.IDIndexID {
+			continue
+		}
+		fieldIndexer, ok := schema.Indexer.(memdb.FieldIndexer)
+		if !ok {
+			continue
+		}
+		if _, ok := schema.Indexer.(memdb.MultiKeyIndexer); ok {
+			continue
+		}
+		if _, ok := schema.Indexer.(memdb.AggregateIndexer); ok {
+			continue
+		}
+		fields := fieldIndexer.Fields()
+		if len(fields) == 0 {
+			continue
+		}
+
+		c := candidate{
+			id:     id,
+			schema: schema,
+			fields: fields,
+		}
+		c.prefix, c.rng, c.sorted = q.match(fields, sortOrder)
+		if c.better(best) {
+			best = c
+		}
+	}
+
+	p := plan{
+		Plan: Plan{
+			Index: best.schema.Name(),
+			Limit: q.limit,
+		},
+		index: best.id,
+		from:  -1,
+	}
+	pushed := map[int]bool{}
+	for _, f := range best.fields[:best.prefix] {
+		i := q.findCondition(f, Eq)
+		p.prefix = append(p.prefix, i)
+		p.Prefix = append(p.Prefix, q.conds[i].field.name)
+		pushed[i] = true
+	}
+	if best.rng {
+		rangeField := best.fields[best.prefix]
+		for i, c := range q.conds {
+			if c.op < Lt || !c.field.matches(rangeField) {
+				continue
+			}
+			p.Range = c.field.name
+
+			// Conditions bounding the component from above, in the order of keys, stop the iteration.
+			if (c.op == Gt || c.op == Ge) == rangeField.Desc {
+				p.stop = append(p.stop, i)
+				pushed[i] = true
+				continue
+			}
+			// Value of the condition defined first is used to seek. Seek includes entities equal to the value,
+			// so only inclusive condition is fully handled by it.
+			if p.from < 0 {
+				p.from = i
+				pushed[i] = c.op == Ge || c.op == Le
+			}
+		}
+	}
+	for i, c := range q.conds {
+		if !pushed[i] {
+			p.filter = append(p.filter, i)
+			p.Filter = append(p.Filter, c.String())
+		}
+	}
+	if !best.sorted {
+		p.sort = sortOrder
+		for _, o := range sortOrder {
+			name := o.field.name
+			if o.desc {
+				name += " desc"
+			}
+			p.Sort = append(p.Sort, name)
+		}
+	}
+	return p, nil
+}
+
+// findCondition returns the position of the first condition using the operator on the field, or -1.
+func (q *Query[T]) findCondition(f memdb.IndexField, op Op) int {
+	return slices.IndexFunc(q.conds, func(c condition) bool {
+		return c.op == op && c.field.matches(f)
+	})
+}
+
+// match returns the number of index components matched by equality conditions, whether the next component
+// is matched by range condition and whether index returns entities in the requested order.
+func (q *Query[T]) match(fields []memdb.IndexField, sortOrder []order) (int, bool, bool) {
+	var prefix int
+	for _, f := range fields {
+		if q.findCondition(f, Eq) < 0 {
+			break
+		}
+		prefix++
+	}
+
+	var rng bool
+	if prefix < len(fields) {
+		rng = slices.ContainsFunc(q.conds, func(c condition) bool {
+			return c.op >= Lt && c.field.matches(fields[prefix])
+		})
+	}
+
+	sorted := len(sortOrder) <= len(fields)-prefix
+	for i := 0; sorted && i < len(sortOrder); i++ {
+		f := fields[prefix+i]
+		sorted = sortOrder[i].field.matches(f) && sortOrder[i].desc == f.Desc
+	}
+
+	return prefix, rng, sorted
+}
+
+func (q *Query[T]) execute(p plan, conds []condition) ([]*T, error) {
+	seq, err := q.stream(p, conds)
+	if err != nil {
+		return nil, err
+	}
+	result := slices.Collect(seq)
+
+	if len(p.sort) > 0 {
+		slices.SortStableFunc(result, func(o1, o2 *T) int {
+			for _, o := range p.sort {
+				cmp := o.field.compare(unsafe.Pointer(o1), unsafe.Pointer(o2))
+				if o.desc {
+					cmp = -cmp
+				}
+				if cmp != 0 {
+					return cmp
+				}
+			}
+			return 0
+		})
+		if p.Limit > 0 && len(result) > p.Limit {
+			result = result[:p.Limit]
+		}
+	}
+	return result, nil
+}
+
+// stream returns entities read from the index in the index order. Limit is applied only if entities
+// don't need to be sorted.
+func (q *Query[T]) stream(p plan, conds []condition) (iter.Seq[*T], error) {
+	args := make([]any, 0, len(p.prefix)+2)
+	for _, i := range p.prefix {
+		args = append(args, conds[i].arg)
+	}
+	if p.from >= 0 {
+		args = append(args, memdb.From, conds[p.from].arg)
+	}
+
+	it, err := q.txn.Iterator(q.table, p.index, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(yield func(*T) bool) {
+		defer it.Close()
+
+		var count int
+	loop:
+		for o := it.Next(); o != nil; o = it.Next() {
+			for _, i := range p.stop {
+				if !conds[i].match(o) {
+					return
+				}
+			}
+			for _, i := range p.filter {
+				if !conds[i].match(o) {
+					continue loop
+				}
+			}
+			if !yield((*T)(o)) {
+				return
+			}
+			count++
+			if len(p.sort) == 0 && p.Limit > 0 && count == p.Limit {
+				return
+			}
+		}
+	}, nil
+}
@@ -0,0 +1,294 @@
+package query
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/outofforest/memdb"
+	"github.com/outofforest/memdb/indices"
+)
+
+type job struct {
+	ID     memdb.ID
+	Tenant string
+	Status uint8
+	Score  int32
+	NodeID memdb.ID
+}
+
+type node struct {
+	ID   memdb.ID
+	Name string
+}
+
+var (
+	j           job
+	idField     = indices.NewFieldIndex(&j, &j.ID)
+	tenantField = indices.NewFieldIndex(&j, &j.Tenant)
+	statusField = indices.NewFieldIndex(&j, &j.Status)
+	scoreField  = indices.NewFieldIndex(&j, &j.Score)
+	nodeIDField = indices.NewFieldIndex(&j, &j.NodeID)
+
+	tenantStatusIndex = indices.NewMultiIndex(tenantField, statusField)
+	tenantScoreIndex  = indices.NewMultiIndex(tenantField, indices.NewReverseIndex(scoreField))
+
+	n           node
+	nodeField   = indices.NewFieldIndex(&n, &n.ID)
+	nodeNameIdx = indices.NewFieldIndex(&n, &n.Name)
+
+	jobsTableID  = memdb.TableID(reflect.TypeFor[job]())
+	nodesTableID = memdb.TableID(reflect.TypeFor[node]())
+
+	testJobs = []*job{
+		{ID: memdb.ID{1}, Tenant: "a", Status: 1, Score: 5, NodeID: memdb.ID{101}},
+		{ID: memdb.ID{2}, Tenant: "a", Status: 2, Score: 3, NodeID: memdb.ID{101}},
+		{ID: memdb.ID{3}, Tenant: "a", Status: 1, Score: 9, NodeID: memdb.ID{102}},
+		{ID: memdb.ID{4}, Tenant: "b", Status: 1, Score: 7, NodeID: memdb.ID{102}},
+		{ID: memdb.ID{5}, Tenant: "a", Status: 3, Score: -1, NodeID: memdb.ID{103}},
+	}
+	testNodes = []*node{
+		{ID: memdb.ID{101}, Name: "n1"},
+		{ID: memdb.ID{102}, Name: "n2"},
+	}
+)
+
+func testDB(t *testing.T, dbIndices ...memdb.Index) *memdb.Txn {
+	db, err := memdb.NewMemDB(memdb.Config{
+		Entities: []reflect.Type{reflect.TypeFor[job](), reflect.TypeFor[node]()},
+		Indices:  dbIndices,
+	})
+	require.NoError(t, err)
+
+	txn := db.Txn(true)
+	for _, e := range testJobs {
+		_, err := txn.Insert(jobsTableID, unsafe.Pointer(e))
+		require.NoError(t, err)
+	}
+	for _, e := range testNodes {
+		_, err := txn.Insert(nodesTableID, unsafe.Pointer(e))
+		require.NoError(t, err)
+	}
+	require.NoError(t, txn.Commit())
+	return db.Txn(false)
+}
+
+func jobIDs(jobs []*job) []byte {
+	ids := make([]byte, 0, len(jobs))
+	for _, e := range jobs {
+		ids = append(ids, e.ID[0])
+	}
+	return ids
+}
+
+func TestQueryPrefix(t *testing.T) {
+	requireT := require.New(t)
+	txn := testDB(t, tenantStatusIndex, tenantScoreIndex)
+
+	q := New[job](txn).Where(tenantField, Eq, "a").Where(statusField, Eq, uint8(1))
+	p, err := q.Explain()
+	requireT.NoError(err)
+	requireT.Equal(Plan{
+		Index:  tenantStatusIndex.Name(),
+		Prefix: []string{"Tenant", "Status"},
+	}, p)
+	requireT.Equal("index multi(Tenant,Status), prefix [Tenant, Status]", p.String())
+
+	result, err := q.All()
+	requireT.NoError(err)
+	requireT.Equal([]byte{1, 3}, jobIDs(result))
+}
+
+func TestQueryRange(t *testing.T) {
+	requireT := require.New(t)
+	txn := testDB(t, tenantStatusIndex, tenantScoreIndex)
+
+	// Score is stored in descending order, so Le seeks and Gt stops the iteration.
+	q := New[job](txn).Where(tenantField, Eq, "a").Where(scoreField, Gt, int32(0)).Where(scoreField, Le, int32(5))
+	p, err := q.Explain()
+	requireT.NoError(err)
+	requireT.Equal(Plan{
+		Index:  tenantScoreIndex.Name(),
+		Prefix: []string{"Tenant"},
+		Range:  "Score",
+	}, p)
+
+	result, err := q.All()
+	requireT.NoError(err)
+	requireT.Equal([]byte{1, 2}, jobIDs(result))
+
+	q = New[job](txn).Where(tenantField, Eq, "a").Where(scoreField, Lt, int32(9))
+	p, err = q.Explain()
+	requireT.NoError(err)
+	requireT.Equal([]string{"Score <"}, p.Filter)
+
+	result, err = q.All()
+	requireT.NoError(err)
+	requireT.Equal([]byte{1, 2, 5}, jobIDs(result))
+}
+
+func TestQueryFullScan(t *testing.T) {
+	requireT := require.New(t)
+	txn := testDB(t, tenantStatusIndex)
+
+	q := New[job](txn).Where(statusField, Ne, uint8(1))
+	p, err := q.Explain()
+	requireT.NoError(err)
+	requireT.Equal(Plan{
+		Index:  "id",
+		Filter: []string{"Status !="},
+	}, p)
+
+	result, err := q.All()
+	requireT.NoError(err)
+	requireT.Equal([]byte{2, 5}, jobIDs(result))
+
+	// ID index is used for conditions on ID.
+	p, err = New[job](txn).Where(idField, Ge, memdb.ID{3}).Explain()
+	requireT.NoError(err)
+	requireT.Equal(Plan{
+		Index: "id",
+		Range: "ID",
+	}, p)
+}
+
+func TestQueryOrder(t *testing.T) {
+	requireT := require.New(t)
+	txn := testDB(t, tenantStatusIndex, tenantScoreIndex)
+
+	// Index returns entities in the requested order.
+	q := New[job](txn).Where(tenantField, Eq, "a").OrderByDesc(scoreField).Limit(2)
+	p, err := q.Explain()
+	requireT.NoError(err)
+	requireT.Equal(Plan{
+		Index:  tenantScoreIndex.Name(),
+		Prefix: []string{"Tenant"},
+		Limit:  2,
+	}, p)
+
+	result, err := q.All()
+	requireT.NoError(err)
+	requireT.Equal([]byte{3, 1}, jobIDs(result))
+
+	// Entities are sorted.
+	q = New[job](txn).Where(tenantField, Eq, "a").OrderBy(scoreField).Limit(3)
+	p, err = q.Explain()
+	requireT.NoError(err)
+	requireT.Equal([]string{"Score"}, p.Sort)
+
+	result, err = q.All()
+	requireT.NoError(err)
+	requireT.Equal([]byte{5, 2, 1}, jobIDs(result))
+
+	q = New[job](txn).OrderBy(statusField).OrderByDesc(scoreField)
+	p, err = q.Explain()
+	requireT.NoError(err)
+	requireT.Equal([]string{"Status", "Score desc"}, p.Sort)
+
+	result, err = q.All()
+	requireT.NoError(err)
+	requireT.Equal([]byte{3, 4, 1, 2, 5}, jobIDs(result))
+
+	first, err := q.First()
+	requireT.NoError(err)
+	requireT.Equal(testJobs[2], first)
+
+	first, err = New[job](txn).Where(tenantField, Eq, "c").First()
+	requireT.NoError(err)
+	requireT.Nil(first)
+}
+
+func TestQueryErrors(t *testing.T) {
+	requireT := require.New(t)
+	txn := testDB(t)
+
+	_, err := New[job](txn).Where(tenantStatusIndex, Eq, "a").All()
+	requireT.ErrorContains(err, "is not built from single field")
+
+	_, err = New[job](txn).OrderBy(indices.NewIfIndex(tenantField, func(j *job) bool { return true })).All()
+	requireT.ErrorContains(err, "is not built from field")
+
+	_, err = New[job](txn).Where(tenantField, Op(100), "a").All()
+	requireT.ErrorContains(err, "invalid operator")
+}
+
+func TestJoin(t *testing.T) {
+	requireT := require.New(t)
+	txn := testDB(t, tenantStatusIndex, indices.NewFieldIndex(&j, &j.NodeID), nodeNameIdx)
+
+	// Nodes are found by primary key.
+	jn := Join(New[job](txn).Where(tenantField, Eq, "a"), New[node](txn), nodeIDField, nodeField)
+	p, err := jn.Explain()
+	requireT.NoError(err)
+	requireT.True(p.IndexJoin)
+	requireT.Equal("index join: left: index multi(Tenant,Status), prefix [Tenant]; right: index id, prefix [ID]",
+		p.String())
+
+	pairs, err := jn.All()
+	requireT.NoError(err)
+	requireT.Equal([]Pair[job, node]{
+		{Left: testJobs[0], Right: testNodes[0]},
+		{Left: testJobs[2], Right: testNodes[1]},
+		{Left: testJobs[1], Right: testNodes[0]},
+	}, pairs)
+
+	// Jobs are found by node index.
+	nj := Join(New[node](txn).Where(nodeNameIdx, Eq, "n2"), New[job](txn), nodeField, nodeIDField)
+	p, err = nj.Explain()
+	requireT.NoError(err)
+	requireT.True(p.IndexJoin)
+
+	pairs2, err := nj.All()
+	requireT.NoError(err)
+	requireT.Equal([]Pair[node, job]{
+		{Left: testNodes[1], Right: testJobs[2]},
+		{Left: testNodes[1], Right: testJobs[3]},
+	}, pairs2)
+}
+
+func TestNestedLoopJoin(t *testing.T) {
+	requireT := require.New(t)
+	txn := testDB(t)
+
+	nj := Join(New[node](txn), New[job](txn).Where(statusField, Eq, uint8(1)), nodeField, nodeIDField)
+	p, err := nj.Explain()
+	requireT.NoError(err)
+	requireT.False(p.IndexJoin)
+	requireT.Equal([]string{"Status =", "NodeID ="}, p.Right.Filter)
+
+	pairs, err := nj.All()
+	requireT.NoError(err)
+	requireT.Equal([]Pair[node, job]{
+		{Left: testNodes[0], Right: testJobs[0]},
+		{Left: testNodes[1], Right: testJobs[2]},
+		{Left: testNodes[1], Right: testJobs[3]},
+	}, pairs)
+}
+
+func TestQuerySeq(t *testing.T) {
+	requireT := require.New(t)
+	txn := testDB(t, tenantStatusIndex, tenantScoreIndex)
+
+	// Entities are streamed from the index.
+	seq, err := New[job](txn).Where(tenantField, Eq, "a").Where(scoreField, Gt, int32(0)).Limit(2).Seq()
+	requireT.NoError(err)
+	requireT.Equal([]byte{3, 1}, jobIDs(slices.Collect(seq)))
+
+	// Entities are sorted before they are returned.
+	seq, err = New[job](txn).Where(tenantField, Eq, "a").OrderBy(scoreField).Seq()
+	requireT.NoError(err)
+	var ids []byte
+	for e := range seq {
+		ids = append(ids, e.ID[0])
+		if len(ids) == 3 {
+			break
+		}
+	}
+	requireT.Equal([]byte{5, 2, 1}, ids)
+
+	_, err = New[job](txn).Where(tenantField, Op(100), "a").Seq()
+	requireT.ErrorContains(err, "invalid operator")
+}
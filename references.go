@@ -0,0 +1,253 @@
+package memdb
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"unsafe"
+
+	"github.com/pkg/errors"
+)
+
+// ErrReference is returned when reference constraint is violated.
+var ErrReference = errors.New("reference constraint violated")
+
+// OnDelete defines the action taken when referenced entity is deleted.
+type OnDelete uint8
+
+const (
+	// Restrict prevents referenced entity from being deleted.
+	Restrict OnDelete = iota
+
+	// Cascade deletes referencing entities together with the referenced one.
+	Cascade
+
+	// SetNull clears the reference in referencing entities.
+	SetNull
+)
+
+// Reference defines the constraint requiring that each key produced by the index of the child entity exists
+// in the ID index of the parent entity. Reference is null, and not checked, if index produces no key for the entity
+// or the key consists of zero bytes only.
+//
+// Encoding of keys produced by the index must be equal to the encoding of the parent primary key and prefix-free.
+type Reference struct {
+	// Index is the index of the child entity producing the keys of referenced entities.
+	Index Index
+
+	// Parent is the type of referenced entity.
+	Parent reflect.Type
+
+	// OnDelete defines the action taken when referenced entity is deleted.
+	OnDelete OnDelete
+
+	// Deferred causes the constraint to be checked when transaction is committed instead of on each insert
+	// and delete. Cascade and SetNull actions are always executed immediately.
+	Deferred bool
+}
+
+// Nullifier is implemented by indices able to clear the reference stored in the entity. It is required by SetNull
+// action.
+type Nullifier interface {
+	// SetNull clears the value indexed by the index in the entity.
+	SetNull(o unsafe.Pointer)
+}
+
+type reference struct {
+	name        string
+	childTable  uint64
+	parentTable uint64
+	index       uint64
+	nullifier   Nullifier
+	onDelete    OnDelete
+	deferred    bool
+}
+
+// pendingCheck is the deferred reference check executed on commit.
+type pendingCheck struct {
+	ref *reference
+
+	// id is the primary key of the child entity or, if parentDeleted is true, of the deleted parent.
+	id            []byte
+	parentDeleted bool
+}
+
+// addReference validates the reference and adds it to the schema.
+func (s *state) addReference(r Reference) error {
+	childType := r.Index.Type()
+	childTable := TableID(childType)
+	child, ok := s.schema[childTable]
+	if !ok {
+		return fmt.Errorf("reference from undefined entity %s", childType)
+	}
+	if _, ok := child.indices[r.Index.ID()]; !ok {
+		return fmt.Errorf("reference index %q of entity %s is not defined", r.Index.Name(), childType)
+	}
+	if _, ok := child.indices[r.Index.ID()].Indexer.(AggregateIndexer); ok {
+		return fmt.Errorf("reference index %q of entity %s is aggregate", r.Index.Name(), childType)
+	}
+	if _, ok := s.schema[TableID(r.Parent)]; !ok {
+		return fmt.Errorf("reference to undefined entity %s", r.Parent)
+	}
+
+	ref := &reference{
+		name:        fmt.Sprintf("%s(%s) -> %s", childType, r.Index.Name(), r.Parent),
+		childTable:  childTable,
+		parentTable: TableID(r.Parent),
+		index:       r.Index.ID(),
+		onDelete:    r.OnDelete,
+		deferred:    r.Deferred,
+	}
+	switch r.OnDelete {
+	case Restrict, Cascade:
+	case SetNull:
+		nullifier, ok := r.Index.(Nullifier)
+		if !ok {
+			return fmt.Errorf("reference %s: index does not support setting null", ref.name)
+		}
+		ref.nullifier = nullifier
+	default:
+		return fmt.Errorf("reference %s: invalid on-delete action %d", ref.name, r.OnDelete)
+	}
+
+	// Schema is modified in place, so it must be called on the schema not shared with other transactions.
+	child.references = append(child.references, ref)
+	parent := s.schema[ref.parentTable]
+	parent.referencedBy = append(parent.referencedBy, ref)
+	return nil
+}
+
+// checkParents verifies that entities referenced by the child exist.
+func (txn *Txn) checkParents(ref *reference, obj unsafe.Pointer, id []byte) error {
+	s := txn.getState()
+	parent, ok := s.schema[ref.parentTable]
+	if !ok {
+		return nil
+	}
+	parentIndex := txn.readableIndex(parent.indices[IDIndexID].id, false)
+	for _, key := range referenceKeys(s.schema[ref.childTable].indices[ref.index], obj) {
+		if ref.parentTable == ref.childTable && bytes.Equal(key, id) {
+			continue
+		}
+		if parentIndex.Get(key) == defaultPointer {
+			return errors.Wrapf(ErrReference, "%s: referenced entity does not exist", ref.name)
+		}
+	}
+	return nil
+}
+
+// children returns entities referencing the parent with the given primary key.
+func (txn *Txn) children(ref *reference, id []byte) []unsafe.Pointer {
+	child, ok := txn.getState().schema[ref.childTable]
+	if !ok {
+		return nil
+	}
+
+	it := txn.readableIndex(child.indices[ref.index].id, true).Root().Iterator()
+	it.SeekPrefix(id)
+	var result []unsafe.Pointer
+	for obj := it.Next(); obj != defaultPointer; obj = it.Next() {
+		result = append(result, obj)
+	}
+	return result
+}
+
+// restrictDelete returns error if entity is referenced by restricting reference.
+func (txn *Txn) restrictDelete(ref *reference, obj unsafe.Pointer, id []byte) error {
+	for _, child := range txn.children(ref, id) {
+		if child != obj {
+			return errors.Wrapf(ErrReference, "%s: entity is referenced", ref.name)
+		}
+	}
+	return nil
+}
+
+// onDelete executes actions of references pointing to the deleted entity.
+func (txn *Txn) onDelete(t *tableSchema, obj unsafe.Pointer, id []byte) error {
+	for _, ref := range t.referencedBy {
+		switch ref.onDelete {
+		case Restrict:
+			if ref.deferred {
+				s := txn.getState()
+				s.pending = append(s.pending, pendingCheck{ref: ref, id: bytes.Clone(id), parentDeleted: true})
+			}
+		case Cascade:
+			for _, child := range txn.children(ref, id) {
+				if _, err := txn.Delete(ref.childTable, child); err != nil && !errors.Is(err, ErrNotFound) {
+					return err
+				}
+			}
+		case SetNull:
+			childType := txn.getState().schema[ref.childTable].eType
+			for _, child := range txn.children(ref, id) {
+				// Stored objects must not be modified, so copy is updated.
+				v := reflect.New(childType)
+				v.Elem().Set(reflect.NewAt(childType, child).Elem())
+				ref.nullifier.SetNull(v.UnsafePointer())
+				if _, err := txn.Insert(ref.childTable, v.UnsafePointer()); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkPending executes deferred reference checks.
+func (txn *Txn) checkPending() error {
+	s := txn.getState()
+	for _, c := range s.pending {
+		if c.parentDeleted {
+			parent, ok := s.schema[c.ref.parentTable]
+			if !ok {
+				continue
+			}
+			// Parent might be inserted again.
+			obj := txn.readableIndex(parent.indices[IDIndexID].id, false).Get(c.id)
+			if obj != defaultPointer {
+				continue
+			}
+			if err := txn.restrictDelete(c.ref, nil, c.id); err != nil {
+				return err
+			}
+			continue
+		}
+
+		child, ok := s.schema[c.ref.childTable]
+		if !ok {
+			continue
+		}
+		obj := txn.readableIndex(child.indices[IDIndexID].id, false).Get(c.id)
+		if obj == defaultPointer {
+			continue
+		}
+		if err := txn.checkParents(c.ref, obj, c.id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// referenceKeys returns non-null keys produced by the index for the object.
+func referenceKeys(indexSchema *IndexSchema, obj unsafe.Pointer) [][]byte {
+	var keys [][]byte
+	add := func(key []byte) {
+		for _, b := range key {
+			if b != 0 {
+				keys = append(keys, key)
+				return
+			}
+		}
+	}
+
+	if multiIndexer, ok := indexSchema.Indexer.(MultiKeyIndexer); ok {
+		for _, key := range multiIndexer.KeysFromObject(obj) {
+			add(key)
+		}
+		return keys
+	}
+
+	key := make([]byte, indexSchema.Indexer.SizeFromObject(obj))
+	add(key[:indexSchema.Indexer.FromObject(key, obj)])
+	return keys
+}
@@ -0,0 +1,223 @@
+package memdb_test
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/outofforest/memdb"
+	"github.com/outofforest/memdb/indices"
+)
+
+type testProject struct {
+	ID   memdb.ID
+	Name string
+}
+
+type testJob struct {
+	ID        memdb.ID
+	ProjectID memdb.ID
+}
+
+var (
+	job             testJob
+	jobProjectIndex = indices.NewFieldIndex(&job, &job.ProjectID)
+	projectsTableID = memdb.TableID(reflect.TypeFor[testProject]())
+	jobsTableID     = memdb.TableID(reflect.TypeFor[testJob]())
+	testProjectID   = memdb.ID{1}
+	testMissingID   = memdb.ID{2}
+	testJobID1      = memdb.ID{3}
+	testJobID2      = memdb.ID{4}
+	testJobID3      = memdb.ID{5}
+)
+
+func testReferenceDB(t *testing.T, onDelete memdb.OnDelete, deferred bool) *memdb.MemDB {
+	db, err := memdb.NewMemDB(memdb.Config{
+		Entities: []reflect.Type{
+			reflect.TypeFor[testProject](),
+			reflect.TypeFor[testJob](),
+		},
+		Indices: []memdb.Index{jobProjectIndex},
+		References: []memdb.Reference{
+			{
+				Index:    jobProjectIndex,
+				Parent:   reflect.TypeFor[testProject](),
+				OnDelete: onDelete,
+				Deferred: deferred,
+			},
+		},
+	})
+	require.NoError(t, err)
+	return db
+}
+
+func insert(txn *memdb.Txn, table uint64, obj unsafe.Pointer) error {
+	_, err := txn.Insert(table, obj)
+	return err
+}
+
+func TestReference_Validation(t *testing.T) {
+	config := func(ref memdb.Reference, indices ...memdb.Index) memdb.Config {
+		return memdb.Config{
+			Entities: []reflect.Type{
+				reflect.TypeFor[testProject](),
+				reflect.TypeFor[testJob](),
+			},
+			Indices:    indices,
+			References: []memdb.Reference{ref},
+		}
+	}
+
+	_, err := memdb.NewMemDB(config(memdb.Reference{
+		Index:  jobProjectIndex,
+		Parent: reflect.TypeFor[testProject](),
+	}))
+	require.ErrorContains(t, err, "is not defined")
+
+	_, err = memdb.NewMemDB(config(memdb.Reference{
+		Index:  jobProjectIndex,
+		Parent: reflect.TypeFor[TestObject](),
+	}, jobProjectIndex))
+	require.ErrorContains(t, err, "reference to undefined entity")
+
+	uniqueIndex := indices.NewUniqueIndex(jobProjectIndex)
+	_, err = memdb.NewMemDB(config(memdb.Reference{
+		Index:    uniqueIndex,
+		Parent:   reflect.TypeFor[testProject](),
+		OnDelete: memdb.SetNull,
+	}, uniqueIndex))
+	require.ErrorContains(t, err, "does not support setting null")
+
+	db := testReferenceDB(t, memdb.Restrict, false)
+	require.Error(t, db.DropIndex(jobsTableID, jobProjectIndex.ID()))
+	require.Error(t, db.DropTable(projectsTableID))
+	require.Error(t, db.DropTable(jobsTableID))
+}
+
+func TestReference_Restrict(t *testing.T) {
+	db := testReferenceDB(t, memdb.Restrict, false)
+
+	project := &testProject{ID: testProjectID}
+	job1 := &testJob{ID: testJobID1, ProjectID: testProjectID}
+
+	txn := db.Txn(true)
+	require.ErrorIs(t, insert(txn, jobsTableID, unsafe.Pointer(&testJob{ID: testJobID1, ProjectID: testMissingID})),
+		memdb.ErrReference)
+
+	// Null reference is not checked.
+	require.NoError(t, insert(txn, jobsTableID, unsafe.Pointer(&testJob{ID: testJobID2})))
+
+	require.NoError(t, insert(txn, projectsTableID, unsafe.Pointer(project)))
+	require.NoError(t, insert(txn, jobsTableID, unsafe.Pointer(job1)))
+	require.NoError(t, txn.Commit())
+
+	txn = db.Txn(true)
+	_, err := txn.Delete(projectsTableID, unsafe.Pointer(project))
+	require.ErrorIs(t, err, memdb.ErrReference)
+
+	// Project may be updated.
+	require.NoError(t, insert(txn, projectsTableID, unsafe.Pointer(&testProject{ID: testProjectID, Name: "A"})))
+
+	_, err = txn.Delete(jobsTableID, unsafe.Pointer(job1))
+	require.NoError(t, err)
+	_, err = txn.Delete(projectsTableID, unsafe.Pointer(project))
+	require.NoError(t, err)
+	require.NoError(t, txn.Commit())
+}
+
+func TestReference_Cascade(t *testing.T) {
+	db := testReferenceDB(t, memdb.Cascade, false)
+
+	project := &testProject{ID: testProjectID}
+	job1 := &testJob{ID: testJobID1, ProjectID: testProjectID}
+	job2 := &testJob{ID: testJobID2, ProjectID: testProjectID}
+	job3 := &testJob{ID: testJobID3}
+
+	txn := db.Txn(true)
+	require.NoError(t, insert(txn, projectsTableID, unsafe.Pointer(project)))
+	require.NoError(t, insert(txn, jobsTableID, unsafe.Pointer(job1)))
+	require.NoError(t, insert(txn, jobsTableID, unsafe.Pointer(job2)))
+	require.NoError(t, insert(txn, jobsTableID, unsafe.Pointer(job3)))
+	require.NoError(t, txn.Commit())
+
+	txn = db.Txn(true)
+	_, err := txn.Delete(projectsTableID, unsafe.Pointer(project))
+	require.NoError(t, err)
+	require.NoError(t, txn.Commit())
+
+	txn = db.Txn(false)
+	it, err := txn.Iterator(jobsTableID, memdb.IDIndexID)
+	require.NoError(t, err)
+	require.Equal(t, job3, (*testJob)(it.Next()))
+	require.Nil(t, it.Next())
+}
+
+func TestReference_SetNull(t *testing.T) {
+	db := testReferenceDB(t, memdb.SetNull, false)
+
+	project := &testProject{ID: testProjectID}
+	job1 := &testJob{ID: testJobID1, ProjectID: testProjectID}
+
+	txn := db.Txn(true)
+	require.NoError(t, insert(txn, projectsTableID, unsafe.Pointer(project)))
+	require.NoError(t, insert(txn, jobsTableID, unsafe.Pointer(job1)))
+	require.NoError(t, txn.Commit())
+
+	txn = db.Txn(true)
+	_, err := txn.Delete(projectsTableID, unsafe.Pointer(project))
+	require.NoError(t, err)
+	require.NoError(t, txn.Commit())
+
+	// Stored object is not modified.
+	require.Equal(t, testProjectID, job1.ProjectID)
+
+	txn = db.Txn(false)
+	j, err := txn.First(jobsTableID, memdb.IDIndexID, testJobID1)
+	require.NoError(t, err)
+	require.Equal(t, &testJob{ID: testJobID1}, (*testJob)(j))
+
+	j, err = txn.First(jobsTableID, jobProjectIndex.ID(), testProjectID)
+	require.NoError(t, err)
+	require.Nil(t, j)
+}
+
+func TestReference_Deferred(t *testing.T) {
+	db := testReferenceDB(t, memdb.Restrict, true)
+
+	project := &testProject{ID: testProjectID}
+	job1 := &testJob{ID: testJobID1, ProjectID: testProjectID}
+
+	// Child might be inserted before parent.
+	txn := db.Txn(true)
+	require.NoError(t, insert(txn, jobsTableID, unsafe.Pointer(job1)))
+	require.ErrorIs(t, txn.Commit(), memdb.ErrReference)
+	require.NoError(t, insert(txn, projectsTableID, unsafe.Pointer(project)))
+	require.NoError(t, txn.Commit())
+
+	// Parent might be deleted and inserted again.
+	txn = db.Txn(true)
+	_, err := txn.Delete(projectsTableID, unsafe.Pointer(project))
+	require.NoError(t, err)
+	require.NoError(t, insert(txn, projectsTableID, unsafe.Pointer(project)))
+	require.NoError(t, txn.Commit())
+
+	// Deleted parent must not be referenced at commit.
+	txn = db.Txn(true)
+	_, err = txn.Delete(projectsTableID, unsafe.Pointer(project))
+	require.NoError(t, err)
+	require.ErrorIs(t, txn.Commit(), memdb.ErrReference)
+
+	// Checks from subtransaction are executed by parent.
+	txn = db.Txn(true)
+	subTxn := txn.Txn(true)
+	require.NoError(t, insert(subTxn, jobsTableID, unsafe.Pointer(&testJob{ID: testJobID2, ProjectID: testMissingID})))
+	require.NoError(t, subTxn.Commit())
+	require.ErrorIs(t, txn.Commit(), memdb.ErrReference)
+
+	txn = db.Txn(false)
+	j, err := txn.First(jobsTableID, memdb.IDIndexID, testJobID2)
+	require.NoError(t, err)
+	require.Nil(t, j)
+}
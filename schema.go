@@ -4,6 +4,8 @@
 package memdb
 
 import (
+	"hash/fnv"
+	"maps"
 	"reflect"
 	"unsafe"
 
@@ -14,10 +16,27 @@ import (
 //
 // MemDB will require a valid schema. Schema validation can be tested using
 // the Validate function. Calling this function is recommended in unit tests.
-type dbSchema []tableSchema
+type dbSchema map[uint64]*tableSchema
 
 // tableSchema contains indexes.
-type tableSchema map[uint64]*IndexSchema
+type tableSchema struct {
+	eType        reflect.Type
+	indices      map[uint64]*IndexSchema
+	references   []*reference
+	referencedBy []*reference
+	validators   []Validator
+	triggers     []Trigger
+	views        []View
+	isView       bool
+	copyPolicy   CopyPolicy
+}
+
+// clone creates a copy of table schema which might be modified without affecting the original one.
+func (t *tableSchema) clone() *tableSchema {
+	t2 := *t
+	t2.indices = maps.Clone(t.indices)
+	return &t2
+}
 
 // Validate validates the schema.
 func (s dbSchema) Validate() error {
@@ -25,11 +44,8 @@ func (s dbSchema) Validate() error {
 		return errors.New("schema is empty")
 	}
 
-	if len(s) == 0 {
-		return errors.New("schema has no tables defined")
-	}
-
-	for tableID, indexes := range s {
+	for tableID, table := range s {
+		indexes := table.indices
 		if len(indexes) == 0 {
 			return errors.Errorf("missing table indexes for %d", tableID)
 		}
@@ -54,11 +70,36 @@ func (s dbSchema) Validate() error {
 
 // Index represents DB index.
 type Index interface {
+	// ID returns the stable ID of the index. It must be equal to IndexID(Name()).
 	ID() uint64
+
+	// Name returns the name of the index. It must be unique within the table.
+	Name() string
+
 	Schema() *IndexSchema
 	Type() reflect.Type
 }
 
+// IndexID returns stable ID of the index with the given name.
+func IndexID(name string) uint64 {
+	return hashName(name)
+}
+
+// TableID returns stable ID of the table storing entities of the given type.
+func TableID(eType reflect.Type) uint64 {
+	name := eType.String()
+	if eType.Name() != "" {
+		name = eType.PkgPath() + "." + eType.Name()
+	}
+	return hashName(name)
+}
+
+func hashName(name string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+	return h.Sum64()
+}
+
 // ArgSerializerIndexer combines ArgSerializer and Indexer.
 type ArgSerializerIndexer interface {
 	ArgSerializer
@@ -72,6 +113,11 @@ type ArgSerializer interface {
 }
 
 // Indexer is an interface used for defining indexes.
+//
+// Indexers are shared by transactions running in different goroutines, and Txn.BulkLoad and Txn.InsertBatch
+// build different indices concurrently, so methods of indexers, including the ones of the interfaces
+// extending Indexer, might be called concurrently. They must be safe for concurrent use, which is the case
+// for stateless indexers.
 type Indexer interface {
 	// Args returns arg serializer for index.
 	Args() []ArgSerializer
@@ -83,13 +129,90 @@ type Indexer interface {
 	FromObject(b []byte, o unsafe.Pointer) uint64
 }
 
+// MultiKeyIndexer is implemented by indexers which may produce many keys for a single object.
+// Object is stored in the index once per key.
+type MultiKeyIndexer interface {
+	Indexer
+
+	// KeysFromObject returns all the index keys produced for the object. Keys are computed together,
+	// so values they are built from are computed once per object.
+	KeysFromObject(o unsafe.Pointer) [][]byte
+}
+
+// IndexField describes the entity field used as the component of the index key.
+type IndexField struct {
+	// Offset is the offset of the field in the entity.
+	Offset uintptr
+
+	// Type is the type used to read the field value and pass it as the argument of the index.
+	Type reflect.Type
+
+	// Desc is true if component is stored in descending order.
+	Desc bool
+}
+
+// FieldIndexer is implemented by indexers building the key from entity fields. It is used by query planners
+// to match conditions with indices. Each entity must be stored in the index.
+type FieldIndexer interface {
+	Indexer
+
+	// Fields returns fields used as the components of the key, in the order of components.
+	// Nil is returned if any of the components is not built directly from the field.
+	Fields() []IndexField
+}
+
+// DecodingIndexer is implemented by indexers able to decode the index key back into values of its components.
+type DecodingIndexer interface {
+	Indexer
+
+	// Decode returns values of the key components. Bytes following the components, like the primary key
+	// appended to the keys of non-unique index, are ignored. Nil is returned if key is malformed.
+	Decode(key []byte) []any
+}
+
+// SequenceIndexer is implemented by primary key indexers taking values from the per-table sequence.
+// When entity having zero value is inserted, next value of the sequence is assigned to it. When entity having
+// nonzero value is inserted, sequence is moved forward if needed. Sequence is stored together with the data, so it
+// is rolled back if transaction is not committed.
+type SequenceIndexer interface {
+	Indexer
+
+	// Sequence returns the value of the primary key field.
+	Sequence(o unsafe.Pointer) uint64
+
+	// SetSequence sets the value of the primary key field.
+	SetSequence(o unsafe.Pointer, v uint64)
+}
+
+// AggregateIndexer is implemented by indexers maintaining the aggregate of entities sharing the index key instead
+// of indexing the entities themselves. Single aggregate is stored for each key, so iterating the index returns
+// aggregates, not entities. Aggregate is updated by the same insert or delete modifying the entity.
+//
+// Aggregates are shared by snapshots, so they must never be modified. New aggregate must be returned on each change.
+type AggregateIndexer interface {
+	Indexer
+
+	// Add returns the aggregate updated by adding the object. agg is nil if there is no entity for the key yet.
+	Add(agg, o unsafe.Pointer) unsafe.Pointer
+
+	// Remove returns the aggregate updated by removing the object. Nil is returned if no entity for the key remains.
+	Remove(agg, o unsafe.Pointer) unsafe.Pointer
+}
+
 // IndexSchema is the schema for an index. An index defines how a table is
 // queried.
 type IndexSchema struct {
 	Unique  bool
 	Indexer Indexer
 
-	id uint64
+	name       string
+	id         uint64
+	sequenceID uint64
+}
+
+// Name returns the name of the index.
+func (s *IndexSchema) Name() string {
+	return s.name
 }
 
 // Validate validates schema.
@@ -97,5 +220,10 @@ func (s *IndexSchema) Validate() error {
 	if s.Indexer == nil {
 		return errors.New("missing index function")
 	}
+	if _, ok := s.Indexer.(AggregateIndexer); ok {
+		if _, ok := s.Indexer.(MultiKeyIndexer); ok {
+			return errors.New("aggregate index must produce single key")
+		}
+	}
 	return nil
 }
@@ -6,6 +6,9 @@ package memdb_test
 import (
 	"reflect"
 	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
 
 	"github.com/outofforest/memdb"
 	"github.com/outofforest/memdb/indices"
@@ -40,9 +43,30 @@ func TestIndexSchema_Validate(t *testing.T) {
 	}
 }
 
+func TestTableID(t *testing.T) {
+	require.Equal(t, memdb.TableID(reflect.TypeFor[TestObject]()), memdb.TableID(reflect.TypeFor[TestObject]()))
+	require.NotEqual(t, memdb.TableID(reflect.TypeFor[TestObject]()), memdb.TableID(reflect.TypeFor[TestPerson]()))
+
+	db, err := memdb.NewMemDB(memdb.Config{
+		Entities: []reflect.Type{
+			reflect.TypeFor[TestPerson](),
+			reflect.TypeFor[TestObject](),
+		},
+	})
+	require.NoError(t, err)
+
+	// Table ID does not depend on the position of the entity in the config.
+	txn := db.Txn(true)
+	_, err = txn.Insert(objectTableID, unsafe.Pointer(&TestObject{ID: memdb.ID{1}}))
+	require.NoError(t, err)
+	_, err = txn.Insert(0, unsafe.Pointer(&TestObject{ID: memdb.ID{1}}))
+	require.Error(t, err)
+}
+
 var (
-	o        TestObject
-	indexFoo = indices.NewFieldIndex(&o, &o.Foo)
+	o             TestObject
+	objectTableID = memdb.TableID(reflect.TypeFor[TestObject]())
+	indexFoo      = indices.NewFieldIndex(&o, &o.Foo)
 )
 
 func testValidSchema() memdb.Config {
@@ -0,0 +1,65 @@
+package memdb
+
+import (
+	"sync"
+)
+
+const minScratchSize = 1024
+
+// scratchPool provides buffers used to build keys of queries.
+var scratchPool = sync.Pool{
+	New: func() any {
+		return &scratch{}
+	},
+}
+
+// scratch is the buffer keys are built in. Keys are allocated one after another and released together
+// when operation completes, so the memory is reused by subsequent operations. Keys allocated from scratch
+// must be copied if they are stored anywhere. Nil scratch allocates each key separately.
+type scratch struct {
+	buf []byte
+}
+
+// alloc returns zeroed slice of size n.
+func (s *scratch) alloc(n uint64) []byte {
+	if s == nil {
+		return make([]byte, n)
+	}
+
+	l := len(s.buf)
+	end := l + int(n)
+	if end > cap(s.buf) {
+		// Slices allocated before are still valid because they point to the old buffer. Its length is preserved,
+		// so marks taken before remain valid too.
+		s.buf = make([]byte, l, max(2*cap(s.buf), end, minScratchSize))
+	}
+	s.buf = s.buf[:end]
+	b := s.buf[l:end:end]
+	clear(b)
+	return b
+}
+
+// mark returns the position slices allocated later are released to.
+func (s *scratch) mark() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.buf)
+}
+
+// release releases slices allocated after the mark was taken.
+func (s *scratch) release(mark int) {
+	if s == nil {
+		return
+	}
+	s.buf = s.buf[:mark]
+}
+
+func getScratch() *scratch {
+	return scratchPool.Get().(*scratch)
+}
+
+func putScratch(s *scratch) {
+	s.release(0)
+	scratchPool.Put(s)
+}
@@ -0,0 +1,365 @@
+package memdb
+
+import (
+	"bytes"
+	"iter"
+	"reflect"
+	"slices"
+	"sync"
+	"unsafe"
+
+	"github.com/outofforest/iradix"
+)
+
+var (
+	nodeSize    = uint64(unsafe.Sizeof(iradix.Node[unsafe.Pointer]{}))
+	pointerSize = uint64(unsafe.Sizeof(unsafe.Pointer(nil)))
+)
+
+// Stats reports memory used by the database.
+type Stats struct {
+	// Revision is the revision of the snapshot stats are computed for.
+	Revision uint64
+
+	// Tables contains stats of tables by their IDs.
+	Tables map[uint64]TableStats
+
+	// Snapshots contains old snapshots still held by transactions which haven't been committed or aborted,
+	// ordered by revision. It is reported only if Config.TrackSnapshots is set.
+	Snapshots []SnapshotStats
+
+	// PinnedBytes is the estimated memory held by old snapshots only.
+	PinnedBytes uint64
+}
+
+// TableStats reports memory used by the table.
+type TableStats struct {
+	Type reflect.Type
+
+	// Entries is the number of objects stored in the table.
+	Entries uint64
+
+	// ObjectBytes is the memory used by stored objects, not including memory referenced by them.
+	ObjectBytes uint64
+
+	// Indices contains stats of indices by their IDs, including the ID index.
+	Indices map[uint64]IndexStats
+}
+
+// IndexStats reports memory used by the index.
+type IndexStats struct {
+	Name string
+
+	// Entries is the number of keys stored in the index.
+	Entries uint64
+
+	// KeyBytes is the total length of keys stored in the index.
+	KeyBytes uint64
+
+	// Nodes is the approximate number of radix tree nodes.
+	Nodes uint64
+
+	// Bytes is the approximate memory used by radix tree nodes.
+	Bytes uint64
+}
+
+// SnapshotStats reports memory pinned by the old snapshot.
+type SnapshotStats struct {
+	Revision uint64
+
+	// Transactions is the number of transactions started from the snapshot which haven't been committed or aborted.
+	// Transactions dropped without being committed or aborted are counted until they are garbage collected.
+	Transactions uint64
+
+	// PinnedBytes is the estimated memory which would be released if the snapshot was not held.
+	PinnedBytes uint64
+}
+
+// Stats computes memory usage of the database. All the indices are walked, so it is expensive and is meant
+// for capacity planning and diagnostics only.
+//
+// Snapshots are reported only if Config.TrackSnapshots is set. Snapshot is reported until all the transactions
+// started from it are committed or aborted. Transactions dropped without being committed or aborted hold
+// the snapshot until they are garbage collected. Memory shared by snapshots is accounted to the newest one.
+func (db *MemDB) Stats() Stats {
+	current, _ := db.getRoot()
+	stats := Stats{
+		Revision: current.revision,
+		Tables:   make(map[uint64]TableStats, len(current.schema)),
+	}
+	for tableID, t := range current.schema {
+		tableStats := TableStats{
+			Type:    t.eType,
+			Indices: make(map[uint64]IndexStats, len(t.indices)),
+		}
+		for indexID, indexSchema := range t.indices {
+			var size treeSize
+			for key := range current.indexEntries(t, indexSchema) {
+				size.add(key)
+			}
+			indexStats := size.stats()
+			indexStats.Name = indexSchema.name
+			tableStats.Indices[indexID] = indexStats
+		}
+		tableStats.Entries = tableStats.Indices[IDIndexID].Entries
+		tableStats.ObjectBytes = tableStats.Entries * uint64(t.eType.Size())
+		stats.Tables[tableID] = tableStats
+	}
+
+	// Each snapshot is compared with the newer one, so memory shared by many snapshots is counted once.
+	newer := current
+	var snapshots []snapshot
+	if db.snapshots != nil {
+		snapshots = db.snapshots.live()
+	}
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		s := snapshots[i]
+		if s.state.revision >= current.revision {
+			continue
+		}
+		pinned := s.state.pinnedBytes(newer)
+		stats.Snapshots = append(stats.Snapshots, SnapshotStats{
+			Revision:     s.state.revision,
+			Transactions: s.holders,
+			PinnedBytes:  pinned,
+		})
+		stats.PinnedBytes += pinned
+		newer = s.state
+	}
+	slices.Reverse(stats.Snapshots)
+
+	return stats
+}
+
+// snapshots tracks states transactions have been started from.
+type snapshots struct {
+	mu sync.Mutex
+
+	// holders counts transactions started from the state which haven't been committed, aborted or garbage
+	// collected yet. State is removed once it isn't held by any transaction.
+	holders map[*state]uint64
+}
+
+// snapshot is the state held by transactions.
+type snapshot struct {
+	state   *state
+	holders uint64
+}
+
+// register records the state transaction is started from.
+func (s *snapshots) register(st *state) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.holders == nil {
+		s.holders = map[*state]uint64{}
+	}
+	s.holders[st]++
+}
+
+// release records that transaction started from the state has ended.
+func (s *snapshots) release(st *state) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	holders, ok := s.holders[st]
+	switch {
+	case !ok:
+	case holders == 1:
+		delete(s.holders, st)
+	default:
+		s.holders[st] = holders - 1
+	}
+}
+
+// live returns tracked states, ordered by revision.
+func (s *snapshots) live() []snapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	states := make([]snapshot, 0, len(s.holders))
+	for st, holders := range s.holders {
+		states = append(states, snapshot{state: st, holders: holders})
+	}
+	slices.SortFunc(states, func(a, b snapshot) int {
+		switch {
+		case a.state.revision < b.state.revision:
+			return -1
+		case a.state.revision > b.state.revision:
+			return 1
+		default:
+			return 0
+		}
+	})
+	return states
+}
+
+// indexRoot returns the root of the index tree.
+func (s *state) indexRoot(indexID uint64) *iradix.Node[unsafe.Pointer] {
+	index, _ := s.tree.Get(indexID)
+	return index.Root()
+}
+
+// indexEntries returns entries stored in the index ordered by key.
+func (s *state) indexEntries(t *tableSchema, indexSchema *IndexSchema) iter.Seq2[[]byte, unsafe.Pointer] {
+	root := s.indexRoot(indexSchema.id)
+	idSchema := t.indices[IDIndexID]
+
+	// Keys of aggregates can't be computed from values, so they are taken from all the stored objects.
+	if aggregateIndexer, ok := indexSchema.Indexer.(AggregateIndexer); ok {
+		return func(yield func([]byte, unsafe.Pointer) bool) {
+			var keys [][]byte
+			it := s.indexRoot(idSchema.id).Iterator()
+			for obj := it.Next(); obj != nil; obj = it.Next() {
+				if key := aggregateKey(nil, aggregateIndexer, obj); key != nil {
+					keys = append(keys, key)
+				}
+			}
+			slices.SortFunc(keys, bytes.Compare)
+			for _, key := range slices.CompactFunc(keys, bytes.Equal) {
+				if !yield(key, root.Get(key)) {
+					return
+				}
+			}
+		}
+	}
+
+	return func(yield func([]byte, unsafe.Pointer) bool) {
+		it := &radixIterator{
+			iter:        root.Iterator(),
+			idSchema:    idSchema,
+			indexSchema: indexSchema,
+		}
+		for obj := it.Next(); obj != nil; obj = it.Next() {
+			if !yield(it.key(), obj) {
+				return
+			}
+		}
+	}
+}
+
+// pinnedBytes estimates memory used by the state and not shared with the newer one.
+func (s *state) pinnedBytes(newer *state) uint64 {
+	var pinned uint64
+	for tableID, t := range s.schema {
+		for indexID, indexSchema := range t.indices {
+			root := s.indexRoot(indexSchema.id)
+
+			var newerEntries iter.Seq2[[]byte, unsafe.Pointer]
+			if newerTable, ok := newer.schema[tableID]; ok {
+				if newerIndex, ok := newerTable.indices[indexID]; ok && newerIndex.id == indexSchema.id {
+					if newer.indexRoot(newerIndex.id) == root {
+						continue
+					}
+					newerEntries = newer.indexEntries(newerTable, newerIndex)
+				}
+			}
+
+			var size treeSize
+			var changed uint64
+			diffEntries(s.indexEntries(t, indexSchema), newerEntries, func(key []byte, equal bool) {
+				size.add(key)
+				if !equal {
+					changed++
+				}
+			})
+			if changed == 0 {
+				continue
+			}
+
+			// Nodes on the path of changed entry are copied, so it is estimated that old snapshot holds
+			// average number of bytes per entry for each changed one.
+			indexStats := size.stats()
+			pinned += indexStats.Bytes * changed / indexStats.Entries
+			if indexID == IDIndexID {
+				pinned += changed * uint64(t.eType.Size())
+			}
+		}
+	}
+	return pinned
+}
+
+// diffEntries walks entries and reports if the same entry exists in newer ones.
+func diffEntries(
+	entries, newerEntries iter.Seq2[[]byte, unsafe.Pointer],
+	fn func(key []byte, equal bool),
+) {
+	if newerEntries == nil {
+		for key := range entries {
+			fn(key, false)
+		}
+		return
+	}
+
+	next, stop := iter.Pull2(newerEntries)
+	defer stop()
+
+	newerKey, newerValue, ok := next()
+	for key, value := range entries {
+		for ok && bytes.Compare(newerKey, key) < 0 {
+			newerKey, newerValue, ok = next()
+		}
+		fn(key, ok && newerValue == value && bytes.Equal(newerKey, key))
+	}
+}
+
+// treeSize estimates the size of radix tree storing keys added in ascending order.
+type treeSize struct {
+	entries     uint64
+	keyBytes    uint64
+	nodes       uint64
+	prefixBytes uint64
+
+	lastKey []byte
+	// depths contains lengths of prefixes represented by the nodes on the path to the last key.
+	depths []int
+}
+
+// add adds next key to the tree.
+func (ts *treeSize) add(key []byte) {
+	if ts.depths == nil {
+		ts.depths = []int{0}
+	}
+
+	ts.entries++
+	ts.keyBytes += uint64(len(key))
+
+	// Node is created where the key diverges from the previous one, and at the end of the key.
+	common := 0
+	for common < len(key) && common < len(ts.lastKey) && key[common] == ts.lastKey[common] {
+		common++
+	}
+	ts.pop(common)
+	if ts.depths[len(ts.depths)-1] < common {
+		ts.depths = append(ts.depths, common)
+		ts.nodes++
+	}
+	if len(key) > common {
+		ts.depths = append(ts.depths, len(key))
+		ts.nodes++
+	}
+	ts.lastKey = key
+}
+
+// pop removes nodes deeper than depth from the path, accounting their prefixes.
+func (ts *treeSize) pop(depth int) {
+	for len(ts.depths) > 1 && ts.depths[len(ts.depths)-1] > depth {
+		d := ts.depths[len(ts.depths)-1]
+		ts.depths = ts.depths[:len(ts.depths)-1]
+		ts.prefixBytes += uint64(d - max(ts.depths[len(ts.depths)-1], depth))
+	}
+}
+
+// stats returns stats of the tree.
+func (ts *treeSize) stats() IndexStats {
+	ts.pop(0)
+
+	// Root node always exists and each other node is referenced by the edge of its parent.
+	nodes := ts.nodes + 1
+	return IndexStats{
+		Entries:  ts.entries,
+		KeyBytes: ts.keyBytes,
+		Nodes:    nodes,
+		Bytes:    nodes*nodeSize + ts.prefixBytes + ts.nodes*pointerSize,
+	}
+}
@@ -0,0 +1,168 @@
+package memdb_test
+
+import (
+	"reflect"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/outofforest/memdb"
+)
+
+func TestMemDB_Stats(t *testing.T) {
+	requireT := require.New(t)
+
+	db, err := memdb.NewMemDB(memdb.Config{
+		Entities:       []reflect.Type{reflect.TypeFor[TestObject]()},
+		Indices:        []memdb.Index{indexFoo, indexFooBaz, indexFooCount},
+		TrackSnapshots: true,
+	})
+	requireT.NoError(err)
+
+	stats := db.Stats()
+	requireT.Zero(stats.Revision)
+	requireT.Zero(stats.Tables[objectTableID].Entries)
+	requireT.Equal("id", stats.Tables[objectTableID].Indices[memdb.IDIndexID].Name)
+	requireT.Equal(uint64(1), stats.Tables[objectTableID].Indices[memdb.IDIndexID].Nodes)
+
+	txn := db.Txn(true)
+	for i := range 100 {
+		requireT.NoError(insert(txn, objectTableID, unsafe.Pointer(&TestObject{
+			ID:  memdb.ID{byte(i)},
+			Foo: "foo" + strconv.Itoa(i%10),
+			Baz: "baz" + strconv.Itoa(i),
+		})))
+	}
+	requireT.NoError(txn.Commit())
+
+	// Snapshot of the committed transaction is released.
+	stats = db.Stats()
+	requireT.Equal(uint64(1), stats.Revision)
+	requireT.Empty(stats.Snapshots)
+	requireT.Zero(stats.PinnedBytes)
+
+	tableStats := stats.Tables[objectTableID]
+	requireT.Equal(reflect.TypeFor[TestObject](), tableStats.Type)
+	requireT.Equal(uint64(100), tableStats.Entries)
+	requireT.Equal(100*uint64(unsafe.Sizeof(TestObject{})), tableStats.ObjectBytes)
+	requireT.Len(tableStats.Indices, 4)
+
+	idStats := tableStats.Indices[memdb.IDIndexID]
+	requireT.Equal(uint64(100), idStats.Entries)
+	requireT.Equal(uint64(100*memdb.IDLength), idStats.KeyBytes)
+	// All IDs differ on the first byte, so the root has 100 children.
+	requireT.Equal(uint64(101), idStats.Nodes)
+	requireT.NotZero(idStats.Bytes)
+
+	requireT.Equal(indexFoo.Name(), tableStats.Indices[indexFoo.ID()].Name)
+	requireT.Equal(uint64(100), tableStats.Indices[indexFoo.ID()].Entries)
+	requireT.Equal(uint64(200), tableStats.Indices[indexFooBaz.ID()].Entries)
+	requireT.Equal(uint64(10), tableStats.Indices[indexFooCount.ID()].Entries)
+	// String keys are terminated with zero byte.
+	requireT.Equal(uint64(10*len("foo0\x00")), tableStats.Indices[indexFooCount.ID()].KeyBytes)
+
+	// Snapshot is pinned by the read transactions.
+	readTxn := db.Txn(false)
+	readTxn2 := db.Txn(false)
+	txn = db.Txn(true)
+	for i := range 50 {
+		_, err := txn.Delete(objectTableID, unsafe.Pointer(&TestObject{ID: memdb.ID{byte(i)}}))
+		requireT.NoError(err)
+	}
+	requireT.NoError(txn.Commit())
+
+	// Snapshot not modifying anything doesn't pin any memory.
+	readTxn3 := db.Txn(false)
+	requireT.NoError(db.Txn(true).Commit())
+
+	stats = db.Stats()
+	requireT.Equal(uint64(3), stats.Revision)
+	requireT.Equal(uint64(50), stats.Tables[objectTableID].Entries)
+	requireT.Len(stats.Snapshots, 2)
+	requireT.Equal(uint64(1), stats.Snapshots[0].Revision)
+	requireT.Equal(uint64(2), stats.Snapshots[0].Transactions)
+	requireT.Greater(stats.Snapshots[0].PinnedBytes, 50*uint64(unsafe.Sizeof(TestObject{})))
+	requireT.Equal(uint64(2), stats.Snapshots[1].Revision)
+	requireT.Equal(uint64(1), stats.Snapshots[1].Transactions)
+	requireT.Zero(stats.Snapshots[1].PinnedBytes)
+	requireT.Equal(stats.Snapshots[0].PinnedBytes, stats.PinnedBytes)
+
+	// Snapshot is held until all the transactions started from it are aborted.
+	readTxn.Abort()
+	readTxn.Abort()
+	stats = db.Stats()
+	requireT.Len(stats.Snapshots, 2)
+	requireT.Equal(uint64(1), stats.Snapshots[0].Transactions)
+
+	readTxn2.Abort()
+	stats = db.Stats()
+	requireT.Len(stats.Snapshots, 1)
+	requireT.Equal(uint64(2), stats.Snapshots[0].Revision)
+	requireT.Zero(stats.PinnedBytes)
+
+	// Transaction dropped without being aborted holds the snapshot until it is garbage collected.
+	stats = db.Stats()
+	requireT.Len(stats.Snapshots, 1)
+	runtime.KeepAlive(readTxn3)
+
+	// Cleanup releasing the snapshot runs asynchronously after the transaction is collected.
+	requireT.Eventually(func() bool {
+		runtime.GC()
+		return len(db.Stats().Snapshots) == 0
+	}, time.Second, time.Millisecond)
+	requireT.Zero(db.Stats().PinnedBytes)
+}
+
+func TestMemDB_StatsUntracked(t *testing.T) {
+	requireT := require.New(t)
+
+	db, err := memdb.NewMemDB(memdb.Config{
+		Entities: []reflect.Type{reflect.TypeFor[TestObject]()},
+		Indices:  []memdb.Index{indexFoo},
+	})
+	requireT.NoError(err)
+
+	readTxn := db.Txn(false)
+	defer readTxn.Abort()
+
+	txn := db.Txn(true)
+	requireT.NoError(insert(txn, objectTableID, unsafe.Pointer(&TestObject{ID: memdb.ID{1}, Foo: "a"})))
+	requireT.NoError(txn.Commit())
+
+	// Snapshots are not tracked by default.
+	stats := db.Stats()
+	requireT.Equal(uint64(1), stats.Tables[objectTableID].Entries)
+	requireT.Empty(stats.Snapshots)
+	requireT.Zero(stats.PinnedBytes)
+}
+
+func TestMemDB_StatsCommit(t *testing.T) {
+	requireT := require.New(t)
+
+	db, err := memdb.NewMemDB(memdb.Config{
+		Entities:       []reflect.Type{reflect.TypeFor[TestObject]()},
+		Indices:        []memdb.Index{indexFoo},
+		TrackSnapshots: true,
+	})
+	requireT.NoError(err)
+
+	txn := db.Txn(true)
+	txn2 := db.Txn(true)
+	requireT.NoError(insert(txn, objectTableID, unsafe.Pointer(&TestObject{ID: memdb.ID{1}, Foo: "a"})))
+	requireT.NoError(txn.Commit())
+
+	// Committed transaction doesn't hold the snapshot, even if it is still referenced.
+	stats := db.Stats()
+	requireT.Len(stats.Snapshots, 1)
+	requireT.Equal(uint64(1), stats.Snapshots[0].Transactions)
+
+	txn2.Abort()
+	stats = db.Stats()
+	requireT.Empty(stats.Snapshots)
+
+	runtime.KeepAlive(txn)
+}
@@ -0,0 +1,150 @@
+package memdb
+
+import (
+	"reflect"
+	"slices"
+
+	"github.com/pkg/errors"
+
+	"github.com/outofforest/memdb/internal/tags"
+)
+
+// Schema is the database schema built from struct tags.
+type Schema struct {
+	// Config is the config to be passed to NewMemDB.
+	Config Config
+
+	indices map[reflect.Type]map[string]Index
+}
+
+// Index returns the index defined by the struct tags of the entity type.
+// Nil is returned if index does not exist.
+func (s *Schema) Index(eType reflect.Type, name string) Index {
+	return s.indices[eType][name]
+}
+
+// TaggedField is the entity field used as the component of the index defined by struct tags.
+type TaggedField struct {
+	IndexField
+
+	// Name is the name of the field.
+	Name string
+}
+
+// TaggedIndexFunc creates the index defined by struct tags. Fields are passed in the order of key components.
+type TaggedIndexFunc func(eType reflect.Type, name string, unique bool, fields []TaggedField) (Index, error)
+
+var taggedIndexFunc TaggedIndexFunc
+
+// RegisterTaggedIndexFunc sets the function used by SchemaFromTypes to create indices. It is called by package
+// indices when it is initialized.
+func RegisterTaggedIndexFunc(fn TaggedIndexFunc) {
+	taggedIndexFunc = fn
+}
+
+// SchemaFromTypes builds the schema from `memdb` struct tags defined on entity types. Fields of embedded structs
+// are indexed too, unless struct is embedded by pointer.
+//
+// Single-field indices are built the same way as FieldIndex, fields sharing the index name are
+// combined as MultiIndex, `unique` option works like UniqueIndex and `desc` like ReverseIndex applied
+// to the field. Indexers are provided by package indices, so it must be imported.
+func SchemaFromTypes(eTypes ...reflect.Type) (*Schema, error) {
+	if taggedIndexFunc == nil {
+		return nil, errors.New("indexers are not registered, package indices must be imported")
+	}
+
+	s := &Schema{
+		Config: Config{
+			Entities: eTypes,
+		},
+		indices: map[reflect.Type]map[string]Index{},
+	}
+	for _, eType := range eTypes {
+		if eType.Kind() != reflect.Struct {
+			return nil, errors.Errorf("entity %s is not a struct", eType)
+		}
+		if _, exists := s.indices[eType]; exists {
+			return nil, errors.Errorf("duplicated entity %s", eType)
+		}
+
+		fields := map[string]TaggedField{}
+		tagFields, err := taggedFields(eType, 0, nil, fields, nil)
+		if err != nil {
+			return nil, errors.Wrapf(err, "entity %s", eType)
+		}
+		tagIndices, err := tags.Indices(tagFields)
+		if err != nil {
+			return nil, errors.Wrapf(err, "entity %s", eType)
+		}
+
+		eIndices := make(map[string]Index, len(tagIndices))
+		for _, ti := range tagIndices {
+			components := make([]TaggedField, 0, len(ti.Components))
+			for _, c := range ti.Components {
+				f := fields[c.Field]
+				f.Desc = c.Desc
+				components = append(components, f)
+			}
+			index, err := taggedIndexFunc(eType, ti.Name, ti.Unique, components)
+			if err != nil {
+				return nil, errors.Wrapf(err, "entity %s", eType)
+			}
+			eIndices[ti.Name] = index
+			s.Config.Indices = append(s.Config.Indices, index)
+		}
+		s.indices[eType] = eIndices
+	}
+	return s, nil
+}
+
+// taggedFields collects tagged fields of the struct, including the ones of embedded structs. Offsets are relative
+// to the entity. Embedded pointers are followed only to report tagged fields which can't be indexed.
+func taggedFields(
+	t reflect.Type,
+	offset uintptr,
+	pointers []reflect.Type,
+	fields map[string]TaggedField,
+	result []tags.Field,
+) ([]tags.Field, error) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get(tags.Name)
+		if f.Anonymous && tag == "" {
+			var err error
+			switch {
+			case f.Type.Kind() == reflect.Struct:
+				result, err = taggedFields(f.Type, offset+f.Offset, pointers, fields, result)
+			case f.Type.Kind() == reflect.Pointer && f.Type.Elem().Kind() == reflect.Struct:
+				// Types embedding themselves by pointer are visited once.
+				if !slices.Contains(pointers, f.Type) {
+					result, err = taggedFields(f.Type.Elem(), 0, append(pointers, f.Type), fields, result)
+				}
+			}
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if tag == "" {
+			continue
+		}
+		if len(pointers) > 0 {
+			return nil, errors.Errorf("field %s: field of struct embedded by pointer can't be indexed", f.Name)
+		}
+		if _, exists := fields[f.Name]; exists {
+			return nil, errors.Errorf("field %s: field name is ambiguous", f.Name)
+		}
+		fields[f.Name] = TaggedField{
+			IndexField: IndexField{
+				Offset: offset + f.Offset,
+				Type:   f.Type,
+			},
+			Name: f.Name,
+		}
+		result = append(result, tags.Field{
+			Name: f.Name,
+			Tag:  tag,
+		})
+	}
+	return result, nil
+}
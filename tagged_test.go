@@ -0,0 +1,74 @@
+package memdb_test
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/outofforest/memdb"
+)
+
+type taggedBase struct {
+	Owner uint64 `memdb:"index:byOwner,order:1"`
+}
+
+type taggedEmbedding struct {
+	ID memdb.ID
+	taggedBase
+
+	Name string `memdb:"index:byOwner,order:2"`
+}
+
+func TestSchemaFromTypes_Embedded(t *testing.T) {
+	requireT := require.New(t)
+
+	s, err := memdb.SchemaFromTypes(reflect.TypeFor[taggedEmbedding]())
+	requireT.NoError(err)
+	byOwner := s.Index(reflect.TypeFor[taggedEmbedding](), "byOwner")
+	requireT.NotNil(byOwner)
+
+	db, err := memdb.NewMemDB(s.Config)
+	requireT.NoError(err)
+	tableID := memdb.TableID(reflect.TypeFor[taggedEmbedding]())
+
+	e1 := &taggedEmbedding{ID: memdb.ID{1}, taggedBase: taggedBase{Owner: 2}, Name: "a"}
+	e2 := &taggedEmbedding{ID: memdb.ID{2}, taggedBase: taggedBase{Owner: 1}, Name: "b"}
+
+	txn := db.Txn(true)
+	for _, e := range []*taggedEmbedding{e1, e2} {
+		_, err := txn.Insert(tableID, unsafe.Pointer(e))
+		requireT.NoError(err)
+	}
+	requireT.NoError(txn.Commit())
+
+	txn = db.Txn(false)
+	defer txn.Abort()
+
+	e, err := txn.First(tableID, byOwner.ID(), uint64(2), "a")
+	requireT.NoError(err)
+	requireT.Equal(e1, (*taggedEmbedding)(e))
+}
+
+func TestSchemaFromTypes_EmbeddedErrors(t *testing.T) {
+	requireT := require.New(t)
+
+	type pointerEmbedding struct {
+		ID memdb.ID
+		*taggedBase
+	}
+
+	type ambiguous struct {
+		ID memdb.ID
+		taggedBase
+
+		Owner uint64 `memdb:"index"`
+	}
+
+	_, err := memdb.SchemaFromTypes(reflect.TypeFor[pointerEmbedding]())
+	requireT.ErrorContains(err, "field Owner: field of struct embedded by pointer can't be indexed")
+
+	_, err = memdb.SchemaFromTypes(reflect.TypeFor[ambiguous]())
+	requireT.ErrorContains(err, "field Owner: field name is ambiguous")
+}
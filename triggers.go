@@ -0,0 +1,101 @@
+package memdb
+
+import (
+	"reflect"
+	"unsafe"
+
+	"github.com/pkg/errors"
+)
+
+// MaxTriggerDepth is the maximum number of nested trigger executions.
+const MaxTriggerDepth = 16
+
+// ErrTriggerDepth is returned when triggers are nested deeper than MaxTriggerDepth.
+var ErrTriggerDepth = errors.New("trigger depth exceeded")
+
+// Event defines the operation on which trigger is executed.
+type Event uint8
+
+const (
+	// BeforeInsert trigger is executed before entity is inserted or updated.
+	BeforeInsert Event = iota
+
+	// AfterInsert trigger is executed after entity is inserted or updated.
+	AfterInsert
+
+	// BeforeDelete trigger is executed before entity is deleted.
+	BeforeDelete
+
+	// AfterDelete trigger is executed after entity is deleted.
+	AfterDelete
+)
+
+// Trigger is executed inside the write transaction when entity is inserted or deleted.
+// It may read and write other entities using the transaction.
+type Trigger interface {
+	// Type returns type of entity trigger is created for.
+	Type() reflect.Type
+
+	// Event returns the operation on which trigger is executed.
+	Event() Event
+
+	// Fire executes the trigger. On insert oldObj is the entity being replaced, or nil, and newObj is the inserted one.
+	// On delete oldObj is the deleted entity and newObj is nil. Objects must not be modified, except newObj passed
+	// to BeforeInsert trigger, which is not stored yet. Its primary key must not be changed.
+	Fire(txn *Txn, oldObj, newObj unsafe.Pointer) error
+}
+
+// NewTrigger creates trigger executed on the event.
+func NewTrigger[T any](event Event, f func(txn *Txn, oldObj, newObj *T) error) Trigger {
+	return &trigger[T]{event: event, f: f}
+}
+
+type trigger[T any] struct {
+	event Event
+	f     func(txn *Txn, oldObj, newObj *T) error
+}
+
+func (t *trigger[T]) Type() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+func (t *trigger[T]) Event() Event {
+	return t.event
+}
+
+func (t *trigger[T]) Fire(txn *Txn, oldObj, newObj unsafe.Pointer) error {
+	return t.f(txn, (*T)(oldObj), (*T)(newObj))
+}
+
+// hasTriggers returns true if any trigger is defined for one of the events.
+func (t *tableSchema) hasTriggers(events ...Event) bool {
+	for _, tr := range t.triggers {
+		for _, e := range events {
+			if tr.Event() == e {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fire executes triggers defined for the event.
+func (txn *Txn) fire(t *tableSchema, event Event, oldObj, newObj unsafe.Pointer) error {
+	for _, tr := range t.triggers {
+		if tr.Event() != event {
+			continue
+		}
+		if txn.triggerDepth >= MaxTriggerDepth {
+			return errors.WithStack(ErrTriggerDepth)
+		}
+
+		txn.triggerDepth++
+		err := tr.Fire(txn, oldObj, newObj)
+		txn.triggerDepth--
+
+		if err != nil {
+			return errors.Wrapf(err, "trigger of entity %s failed", t.eType)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package memdb_test
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/outofforest/memdb"
+)
+
+type testCounter struct {
+	ID    memdb.ID
+	Count uint64
+}
+
+var countersTableID = memdb.TableID(reflect.TypeFor[testCounter]())
+
+func addToCounter(txn *memdb.Txn, id memdb.ID, delta int) error {
+	counter := &testCounter{ID: id}
+	o, err := txn.First(countersTableID, memdb.IDIndexID, id)
+	if err != nil {
+		return err
+	}
+	if o != nil {
+		*counter = *(*testCounter)(o)
+	}
+	counter.Count += uint64(delta)
+	return insert(txn, countersTableID, unsafe.Pointer(counter))
+}
+
+func readCounter(t *testing.T, txn *memdb.Txn, id memdb.ID) uint64 {
+	o, err := txn.First(countersTableID, memdb.IDIndexID, id)
+	require.NoError(t, err)
+	if o == nil {
+		return 0
+	}
+	return (*testCounter)(o).Count
+}
+
+func TestTrigger_Counter(t *testing.T) {
+	requireT := require.New(t)
+
+	db, err := memdb.NewMemDB(memdb.Config{
+		Entities: []reflect.Type{
+			reflect.TypeFor[testJob](),
+			reflect.TypeFor[testCounter](),
+		},
+		Triggers: []memdb.Trigger{
+			memdb.NewTrigger(memdb.AfterInsert, func(txn *memdb.Txn, oldJob, newJob *testJob) error {
+				if oldJob != nil {
+					if err := addToCounter(txn, oldJob.ProjectID, -1); err != nil {
+						return err
+					}
+				}
+				return addToCounter(txn, newJob.ProjectID, 1)
+			}),
+			memdb.NewTrigger(memdb.AfterDelete, func(txn *memdb.Txn, oldJob, newJob *testJob) error {
+				requireT.Nil(newJob)
+				return addToCounter(txn, oldJob.ProjectID, -1)
+			}),
+		},
+	})
+	requireT.NoError(err)
+
+	txn := db.Txn(true)
+	requireT.NoError(insert(txn, jobsTableID, unsafe.Pointer(&testJob{ID: testJobID1, ProjectID: testProjectID})))
+	requireT.NoError(insert(txn, jobsTableID, unsafe.Pointer(&testJob{ID: testJobID2, ProjectID: testProjectID})))
+	requireT.NoError(insert(txn, jobsTableID, unsafe.Pointer(&testJob{ID: testJobID3, ProjectID: testMissingID})))
+	requireT.NoError(txn.Commit())
+
+	txn = db.Txn(false)
+	requireT.EqualValues(2, readCounter(t, txn, testProjectID))
+	requireT.EqualValues(1, readCounter(t, txn, testMissingID))
+
+	txn = db.Txn(true)
+	requireT.NoError(insert(txn, jobsTableID, unsafe.Pointer(&testJob{ID: testJobID3, ProjectID: testProjectID})))
+	_, err = txn.Delete(jobsTableID, unsafe.Pointer(&testJob{ID: testJobID1}))
+	requireT.NoError(err)
+	requireT.NoError(txn.Commit())
+
+	txn = db.Txn(false)
+	requireT.EqualValues(2, readCounter(t, txn, testProjectID))
+	requireT.EqualValues(0, readCounter(t, txn, testMissingID))
+}
+
+func TestTrigger_Before(t *testing.T) {
+	requireT := require.New(t)
+
+	errProtected := errors.New("protected")
+	db, err := memdb.NewMemDB(memdb.Config{
+		Entities: []reflect.Type{reflect.TypeFor[testProject]()},
+		Validators: []memdb.Validator{
+			memdb.NewValidator(func(txn *memdb.Txn, p *testProject) error {
+				if p.Name == "" {
+					return errInvalidName
+				}
+				return nil
+			}),
+		},
+		Triggers: []memdb.Trigger{
+			memdb.NewTrigger(memdb.BeforeInsert, func(txn *memdb.Txn, oldProject, newProject *testProject) error {
+				if newProject.Name == "" {
+					newProject.Name = "default"
+				}
+				if oldProject != nil && oldProject.Name != newProject.Name {
+					return errProtected
+				}
+				return nil
+			}),
+			memdb.NewTrigger(memdb.BeforeDelete, func(txn *memdb.Txn, oldProject, newProject *testProject) error {
+				if oldProject.Name == "default" {
+					return errProtected
+				}
+				return nil
+			}),
+		},
+	})
+	requireT.NoError(err)
+
+	txn := db.Txn(true)
+	requireT.NoError(insert(txn, projectsTableID, unsafe.Pointer(&testProject{ID: testProjectID})))
+	requireT.ErrorIs(insert(txn, projectsTableID, unsafe.Pointer(&testProject{ID: testProjectID, Name: "A"})),
+		errProtected)
+
+	o, err := txn.First(projectsTableID, memdb.IDIndexID, testProjectID)
+	requireT.NoError(err)
+	requireT.Equal("default", (*testProject)(o).Name)
+
+	_, err = txn.Delete(projectsTableID, unsafe.Pointer(&testProject{ID: testProjectID}))
+	requireT.ErrorIs(err, errProtected)
+
+	o, err = txn.First(projectsTableID, memdb.IDIndexID, testProjectID)
+	requireT.NoError(err)
+	requireT.NotNil(o)
+}
+
+func TestTrigger_Depth(t *testing.T) {
+	requireT := require.New(t)
+
+	var calls int
+	db, err := memdb.NewMemDB(memdb.Config{
+		Entities: []reflect.Type{reflect.TypeFor[testCounter]()},
+		Triggers: []memdb.Trigger{
+			memdb.NewTrigger(memdb.AfterInsert, func(txn *memdb.Txn, oldCounter, newCounter *testCounter) error {
+				calls++
+				return addToCounter(txn, newCounter.ID, 1)
+			}),
+		},
+	})
+	requireT.NoError(err)
+
+	txn := db.Txn(true)
+	requireT.ErrorIs(insert(txn, countersTableID, unsafe.Pointer(&testCounter{ID: testProjectID})),
+		memdb.ErrTriggerDepth)
+	requireT.Equal(memdb.MaxTriggerDepth, calls)
+}
+
+func TestTrigger_UndefinedEntity(t *testing.T) {
+	_, err := memdb.NewMemDB(memdb.Config{
+		Entities: []reflect.Type{reflect.TypeFor[testJob]()},
+		Triggers: []memdb.Trigger{
+			memdb.NewTrigger(memdb.AfterInsert, func(txn *memdb.Txn, oldProject, newProject *testProject) error {
+				return nil
+			}),
+		},
+	})
+	require.ErrorContains(t, err, "trigger for undefined entity")
+}
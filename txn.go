@@ -5,13 +5,15 @@ package memdb
 
 import (
 	"bytes"
+	"maps"
+	"runtime"
 	"sync/atomic"
+	"time"
 	"unsafe"
 
 	"github.com/pkg/errors"
 
 	"github.com/outofforest/iradix"
-	"github.com/outofforest/memdb/tree"
 )
 
 var defaultPointer unsafe.Pointer
@@ -31,40 +33,121 @@ const (
 )
 
 // Txn is a transaction against a MemDB.
-// This can be a read or write transaction.
+// This can be a read or write transaction. Top-level transaction must be ended by calling Commit or Abort,
+// so read transactions must always be aborted.
 type Txn struct {
-	schema        dbSchema
 	write         bool
+	nested        bool
+	triggerDepth  int
+	updatingViews bool
 	root          unsafe.Pointer
 	parentRoot    *unsafe.Pointer
 	oldParentRoot unsafe.Pointer
+
+	// snapshots tracks the snapshot top-level transaction has been started from until it is committed or aborted.
+	// It is nil if tracking is disabled.
+	snapshots *snapshots
+	snapshot  *state
+	cleanup   runtime.Cleanup
+
+	instrumentation Instrumentation
+	mutations       *mutationDetector
+
+	// scratch is used to build keys of written objects.
+	scratch scratch
 }
 
 // Txn is used to start a new subtransaction in either read or write mode.
 func (txn *Txn) Txn(write bool) *Txn {
 	return &Txn{
-		schema:        txn.schema,
 		write:         write,
-		root:          unsafe.Pointer(txn.getRoot().Next()),
+		nested:        true,
+		triggerDepth:  txn.triggerDepth,
+		root:          unsafe.Pointer(txn.getState().next()),
 		parentRoot:    &txn.root,
 		oldParentRoot: txn.root,
+
+		instrumentation: txn.instrumentation,
+		mutations:       txn.mutations,
 	}
 }
 
 // Commit is used to finalize this transaction.
 // This is a noop for read transactions,
 // already aborted or committed transactions.
-func (txn *Txn) Commit() {
+//
+// Views are updated, and deferred validators and constraints are checked, when top-level transaction is committed.
+// If any of them fails, error is returned and nothing is committed, so transaction should be aborted.
+// Subtransaction passes changes and deferred checks to its parent.
+func (txn *Txn) Commit() error {
 	// Noop for a read transaction.
 	if !txn.write {
 		panic("commit called on read-only transaction")
 	}
 
+	var start time.Time
+	if !txn.nested {
+		start = time.Now()
+		if err := txn.updateViews(); err != nil {
+			return err
+		}
+		if err := txn.checkValidations(); err != nil {
+			return err
+		}
+		if err := txn.checkPending(); err != nil {
+			return err
+		}
+
+		s := txn.getState()
+		s.pending = nil
+		s.validations = nil
+		s.changes = nil
+		s.revision++
+	}
+
 	// Update the parentRoot of the DB.
 	previousRoot := atomic.SwapPointer(txn.parentRoot, txn.root)
 	if previousRoot != txn.oldParentRoot {
 		panic("parentRoot pointer has changed during transaction")
 	}
+	if !txn.nested {
+		txn.instrumentation.TxnCommitted(time.Since(start))
+		txn.releaseSnapshot()
+	}
+	return nil
+}
+
+// Abort discards the transaction. Snapshot it has been started from is no longer reported by MemDB.Stats
+// as held by the transaction. Transaction must not be used after it is aborted. It is a noop for committed,
+// already aborted and nested transactions.
+func (txn *Txn) Abort() {
+	txn.releaseSnapshot()
+}
+
+// trackSnapshot starts tracking the snapshot transaction has been started from.
+func (txn *Txn) trackSnapshot(s *snapshots, st *state) {
+	s.register(st)
+	txn.snapshots = s
+	txn.snapshot = st
+	// Transaction dropped without being committed or aborted stops holding the snapshot once it is garbage collected.
+	txn.cleanup = runtime.AddCleanup(txn, s.release, st)
+}
+
+// releaseSnapshot stops tracking the snapshot transaction has been started from.
+func (txn *Txn) releaseSnapshot() {
+	if txn.snapshots == nil {
+		return
+	}
+	txn.cleanup.Stop()
+	txn.snapshots.release(txn.snapshot)
+	txn.snapshots = nil
+	txn.snapshot = nil
+}
+
+// Revision returns the revision of the snapshot the transaction was started from.
+// Revision is incremented each time top-level transaction is committed.
+func (txn *Txn) Revision() uint64 {
+	return txn.getState().revision
 }
 
 // Insert is used to add or update an object into the given table.
@@ -72,103 +155,121 @@ func (txn *Txn) Commit() {
 // When updating an object, the obj provided should be a copy rather
 // than a value updated in-place. Modifying values in-place that are already
 // inserted into MemDB is not supported behavior.
+//
+// Triggers defined for the entity are executed. If error is returned by any of them,
+// transaction should be discarded.
 func (txn *Txn) Insert(table uint64, obj unsafe.Pointer) (unsafe.Pointer, error) {
-	if table >= uint64(len(txn.schema)) {
+	// Iterator the table schema
+	tableSchema, ok := txn.getState().schema[table]
+	if !ok {
 		return nil, errors.Errorf("invalid table '%d'", table)
 	}
+	if tableSchema.isView && !txn.updatingViews {
+		return nil, errors.Errorf("table '%d' is a view", table)
+	}
 
-	// Iterator the table schema
-	tableSchema := txn.schema[table]
+	sc := &txn.scratch
+	defer sc.release(sc.mark())
 
 	// Iterator the primary ID of the object
-	idSchema := tableSchema[IDIndexID]
-	idIndexer := idSchema.Indexer
-	id := make([]byte, IDLength)
-	idIndexer.FromObject(id, obj)
-
-	idTxn := txn.writableIndex(idSchema.id)
-	previousObj := idTxn.Insert(id, obj)
+	idSchema := tableSchema.indices[IDIndexID]
+	if seqIndexer, ok := idSchema.Indexer.(SequenceIndexer); ok {
+		txn.assignSequence(idSchema, seqIndexer, obj)
+	}
+	// Sequence is assigned before copying, so it is visible to the caller.
+	obj = tableSchema.copyOnInsert(obj)
+	id, err := primaryKey(sc, idSchema, obj)
+	if err != nil {
+		return nil, err
+	}
 
-	// On an update, there is an existing object with the given
-	// primary ID. We do the update by deleting the current object
-	// and inserting the new object.
-	for indexID, indexSchema := range tableSchema {
-		if indexID == IDIndexID {
-			continue
+	if tableSchema.hasTriggers(BeforeInsert) {
+		oldObj := txn.readableRoot(idSchema.id, false).Get(id)
+		if err := txn.fire(tableSchema, BeforeInsert, oldObj, obj); err != nil {
+			return nil, err
 		}
+	}
 
-		indexer := indexSchema.Indexer
-		keySize := indexer.SizeFromObject(obj)
-		var b []byte
-		var n uint64
-		if keySize > 0 {
-			if !indexSchema.Unique {
-				keySize += uint64(len(id))
-			}
-
-			b = make([]byte, keySize)
-			n = indexSchema.Indexer.FromObject(b, obj)
+	if err := txn.validate(table, tableSchema, obj, id); err != nil {
+		return nil, err
+	}
 
-			// Handle non-unique index by computing a unique index.
-			// This is done by appending the primary key which must
-			// be unique anyway.
-			if !indexSchema.Unique {
-				copy(b[n:], id)
-			}
+	for _, ref := range tableSchema.references {
+		if ref.deferred {
+			s := txn.getState()
+			s.pending = append(s.pending, pendingCheck{ref: ref, id: bytes.Clone(id)})
+			continue
 		}
+		if err := txn.checkParents(ref, obj, id); err != nil {
+			return nil, err
+		}
+	}
 
-		indexTxn := txn.writableIndex(indexSchema.id)
-
-		// Handle the update by deleting from the index first
-		//nolint:nestif
-		if previousObj != defaultPointer {
-			if keySize := indexer.SizeFromObject(previousObj); keySize > 0 {
-				if !indexSchema.Unique {
-					keySize += uint64(len(id))
-				}
+	idTxn := txn.writableIndex(idSchema.id)
+	previousObj := idTxn.Insert(id, obj)
 
-				existingB := make([]byte, keySize)
-				existingN := indexer.FromObject(existingB, previousObj)
-
-				// If we are writing to the same index with the same value,
-				// we can avoid the delete as the insert will overwrite the
-				// value anyway.
-				if b == nil || !bytes.Equal(existingB[:existingN], b[:n]) {
-					// Handle non-unique index by computing a unique index.
-					// This is done by appending the primary key which must
-					// be unique anyways.
-					if !indexSchema.Unique {
-						copy(existingB[existingN:], id)
-					}
-
-					indexTxn.Delete(existingB)
-				}
-			}
+	for indexID, indexSchema := range tableSchema.indices {
+		if indexID == IDIndexID {
+			continue
 		}
+		updateIndex(sc, txn.writableIndex(indexSchema.id), indexSchema, previousObj, obj, id)
+	}
+	txn.instrumentation.RowsInserted(table, 1)
+	if txn.mutations != nil {
+		txn.mutations.record(table, tableSchema, obj)
+	}
 
-		// Update the value of the index
-		if b != nil {
-			indexTxn.Insert(b, obj)
-		}
+	if len(tableSchema.views) > 0 {
+		txn.recordChange(table, id, previousObj)
 	}
-	return previousObj, nil
+
+	if err := txn.fire(tableSchema, AfterInsert, previousObj, obj); err != nil {
+		return nil, err
+	}
+	return tableSchema.copyOnRead(previousObj), nil
 }
 
 // Delete is used to delete a single object from the given table.
 // This object must already exist in the table.
+//
+// Actions of references pointing to the object and triggers are executed. If error is returned by any of them,
+// transaction should be discarded.
 func (txn *Txn) Delete(table uint64, obj unsafe.Pointer) (unsafe.Pointer, error) {
-	if table >= uint64(len(txn.schema)) {
+	// Iterator the table schema.
+	tableSchema, ok := txn.getState().schema[table]
+	if !ok {
 		return nil, errors.Errorf("invalid table '%d'", table)
 	}
+	if tableSchema.isView && !txn.updatingViews {
+		return nil, errors.Errorf("table '%d' is a view", table)
+	}
 
-	// Iterator the table schema.
-	tableSchema := txn.schema[table]
+	sc := &txn.scratch
+	defer sc.release(sc.mark())
 
 	// Iterator the primary ID of the object.
-	idSchema := tableSchema[IDIndexID]
-	idIndexer := idSchema.Indexer
-	id := make([]byte, IDLength)
-	idIndexer.FromObject(id, obj)
+	idSchema := tableSchema.indices[IDIndexID]
+	id, err := primaryKey(sc, idSchema, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tableSchema.referencedBy) > 0 || tableSchema.hasTriggers(BeforeDelete) {
+		previousObj := txn.readableRoot(idSchema.id, false).Get(id)
+		if previousObj == defaultPointer {
+			return nil, ErrNotFound
+		}
+		if err := txn.fire(tableSchema, BeforeDelete, previousObj, nil); err != nil {
+			return nil, err
+		}
+		for _, ref := range tableSchema.referencedBy {
+			if ref.onDelete == Restrict && !ref.deferred {
+				if err := txn.restrictDelete(ref, previousObj, id); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
 
 	idTxn := txn.writableIndex(idSchema.id)
 	previousObj := idTxn.Delete(id)
@@ -177,33 +278,26 @@ func (txn *Txn) Delete(table uint64, obj unsafe.Pointer) (unsafe.Pointer, error)
 	}
 
 	// Remove the object from all the indexes.
-	for indexID, indexSchema := range tableSchema {
+	for indexID, indexSchema := range tableSchema.indices {
 		if indexID == IDIndexID {
 			continue
 		}
 
-		indexer := indexSchema.Indexer
-		if keySize := indexer.SizeFromObject(previousObj); keySize > 0 {
-			if !indexSchema.Unique {
-				keySize += uint64(len(id))
-			}
-
-			existingB := make([]byte, keySize)
-			existingN := indexer.FromObject(existingB, previousObj)
-
-			// Handle non-unique index by computing a unique index.
-			// This is done by appending the primary key which must
-			// be unique anyways.
-			if !indexSchema.Unique {
-				copy(existingB[existingN:], id)
-			}
+		removeFromIndex(sc, txn.writableIndex(indexSchema.id), indexSchema, previousObj, id)
+	}
+	txn.instrumentation.RowsDeleted(table, 1)
 
-			indexTxn := txn.writableIndex(indexSchema.id)
+	if len(tableSchema.views) > 0 {
+		txn.recordChange(table, id, previousObj)
+	}
 
-			indexTxn.Delete(existingB)
-		}
+	if err := txn.onDelete(tableSchema, previousObj, id); err != nil {
+		return nil, err
 	}
-	return previousObj, nil
+	if err := txn.fire(tableSchema, AfterDelete, previousObj, nil); err != nil {
+		return nil, err
+	}
+	return tableSchema.copyOnRead(previousObj), nil
 }
 
 // First is used to return the first matching object for
@@ -212,12 +306,73 @@ func (txn *Txn) Delete(table uint64, obj unsafe.Pointer) (unsafe.Pointer, error)
 // Note that all values read in the transaction form a consistent snapshot
 // from the time when the transaction was created.
 func (txn *Txn) First(table, index uint64, args ...any) (unsafe.Pointer, error) {
-	iter, err := txn.getIndexIterator(false, table, index, args...)
+	sc := getScratch()
+	defer putScratch(sc)
+
+	iter, _, err := txn.getIndexIterator(sc, txn.instrumentation, false, table, index, args...)
 	if err != nil {
 		return nil, err
 	}
 
-	return iter.Next(), nil
+	obj := iter.Next()
+	txn.instrumentation.QueryFinished(table, index, numOfKeys(obj))
+	tableSchema := txn.getState().schema[table]
+	if _, ok := tableSchema.indices[index].Indexer.(AggregateIndexer); ok {
+		// Aggregates are not entities of the table, so they are neither verified nor copied.
+		return obj, nil
+	}
+	if txn.mutations != nil && obj != nil {
+		txn.mutations.verifyRead(table, tableSchema, obj)
+	}
+	return tableSchema.copyOnRead(obj), nil
+}
+
+// Aggregate returns the aggregate stored by the aggregate index for the key built from args.
+// Nil is returned if there is no entity for the key.
+func (txn *Txn) Aggregate(table, index uint64, args ...any) (unsafe.Pointer, error) {
+	tableSchema, ok := txn.getState().schema[table]
+	if !ok {
+		return nil, errors.Errorf("invalid table '%d'", table)
+	}
+	indexSchema, ok := tableSchema.indices[index]
+	if !ok {
+		return nil, errors.Errorf("invalid index '%d'", index)
+	}
+	if _, ok := indexSchema.Indexer.(AggregateIndexer); !ok {
+		return nil, errors.Errorf("index '%d' is not aggregate", index)
+	}
+
+	argDefs := indexSchema.Indexer.Args()
+	if len(args) != len(argDefs) {
+		return nil, errors.Errorf("invalid argument count, received: %d, expected: %d", len(args), len(argDefs))
+	}
+
+	var keySize uint64
+	for i, a := range args {
+		keySize += argDefs[i].SizeFromArg(a)
+	}
+	sc := getScratch()
+	defer putScratch(sc)
+
+	key := sc.alloc(keySize)
+	var n uint64
+	for i, a := range args {
+		n += argDefs[i].FromArg(key[n:], a)
+	}
+
+	txn.instrumentation.Seek(table, index)
+	obj := txn.readableRoot(indexSchema.id, false).Get(key[:n])
+	txn.instrumentation.QueryFinished(table, index, numOfKeys(obj))
+	return obj, nil
+}
+
+// Indices returns schemas of indices defined for the table, keyed by index ID.
+func (txn *Txn) Indices(table uint64) (map[uint64]*IndexSchema, error) {
+	tableSchema, ok := txn.getState().schema[table]
+	if !ok {
+		return nil, errors.Errorf("invalid table '%d'", table)
+	}
+	return maps.Clone(tableSchema.indices), nil
 }
 
 // Iterator is used to construct a ResultIterator over all the rows that match the
@@ -230,22 +385,66 @@ func (txn *Txn) First(table, index uint64, args ...any) (unsafe.Pointer, error)
 // example, a StringFieldIndex will match any string with the given value
 // as a prefix: "mem" matches "memdb".
 //
+// If index is built by MultiKeyIndexer, object is returned once for each of its
+// keys matching the constraints. If index is built by AggregateIndexer, aggregates
+// are returned instead of objects.
+//
+// If Cursor is passed as the last argument, iteration continues right after the
+// result the cursor was taken for. Cursor may be used together with the arguments
+// it was taken with, but not with operators.
+//
 // See the documentation for ResultIterator to understand the behaviour of the
 // returned ResultIterator.
 func (txn *Txn) Iterator(table, index uint64, args ...any) (ResultIterator, error) {
-	indexIter, err := txn.getIndexIterator(true, table, index, args...)
+	sc := getScratch()
+	defer putScratch(sc)
+
+	indexIter, s, err := txn.getIndexIterator(sc, txn.instrumentation, true, table, index, args...)
 	if err != nil {
 		return nil, err
 	}
 
+	tableSchema := txn.getState().schema[table]
+	if _, ok := tableSchema.indices[index].Indexer.(MultiKeyIndexer); ok {
+		// Keys are used by the iterator to track the position.
+		s.prefix = bytes.Clone(s.prefix)
+		s.lowerBound = bytes.Clone(s.lowerBound)
+	}
+
 	// Create an iterator
 	iter := &radixIterator{
-		iter: indexIter,
+		iter:        indexIter,
+		seek:        s,
+		revision:    txn.getState().revision,
+		table:       table,
+		index:       index,
+		idSchema:    tableSchema.indices[IDIndexID],
+		indexSchema: tableSchema.indices[index],
+		lastKey:     s.lastKey,
+
+		instrumentation: txn.instrumentation,
+		mutations:       txn.mutations,
+		tableSchema:     tableSchema,
+	}
+	if _, ok := iter.indexSchema.Indexer.(AggregateIndexer); ok {
+		// Aggregates are not entities of the table, so they are neither verified nor copied.
+		iter.mutations = nil
+		iter.tableSchema = nil
 	}
 
 	return iter, nil
 }
 
+// KeyIterator is used to construct a KeyResultIterator over all the rows that match the
+// given constraints of an index. Arguments are the same as for Iterator.
+func (txn *Txn) KeyIterator(table, index uint64, args ...any) (KeyResultIterator, error) {
+	it, err := txn.Iterator(table, index, args...)
+	if err != nil {
+		return nil, err
+	}
+	return &keyIterator{iter: it.(*radixIterator)}, nil
+}
+
 // ResultIterator is used to iterate over a list of results from a query on a table.
 //
 // When a ResultIterator is created from a write transaction, the results from
@@ -267,24 +466,108 @@ type ResultIterator interface {
 	// Next returns the next result from the iterator. If there are no more results
 	// nil is returned.
 	Next() unsafe.Pointer
+
+	// Cursor returns the cursor pointing to the last result returned by Next. Passed to Txn.Iterator,
+	// it continues the iteration right after that result. Nil is returned if cursor is not available.
+	Cursor() Cursor
+
+	// Close reports the query to the instrumentation if iteration is stopped before Next returns nil.
+	// It is a noop if the iterator is exhausted or already closed.
+	Close()
 }
 
 // radixIterator is used to wrap an underlying iradix iterator.
 // This is much more efficient than a sliceIterator as we are not
 // materializing the entire view.
 type radixIterator struct {
-	iter *iradix.Iterator[unsafe.Pointer]
+	iter     *iradix.Iterator[unsafe.Pointer]
+	seek     seek
+	revision uint64
+	table    uint64
+	index    uint64
+
+	idSchema    *IndexSchema
+	indexSchema *IndexSchema
+
+	// lastObj is the last returned object, its key is computed when cursor is requested.
+	lastObj unsafe.Pointer
+	lastKey []byte
+
+	// instrumentation is notified when iteration is finished. It is nil for internal iterators.
+	instrumentation Instrumentation
+	scanned         uint64
+
+	// mutations verifies returned objects. It is nil if mutation detection is disabled or index stores aggregates.
+	mutations *mutationDetector
+
+	// tableSchema defines copying of returned objects. It is nil for internal iterators and aggregate indices.
+	tableSchema *tableSchema
 }
 
 func (r *radixIterator) Next() unsafe.Pointer {
-	return r.iter.Next()
+	obj := r.iter.Next()
+	if obj == nil {
+		r.Close()
+		return nil
+	}
+	r.scanned++
+	if r.mutations != nil {
+		r.mutations.verifyRead(r.table, r.tableSchema, obj)
+	}
+
+	// Object is returned for each of its keys, so the key must be tracked on each step.
+	if multiIndexer, ok := r.indexSchema.Indexer.(MultiKeyIndexer); ok && !r.seek.back {
+		r.lastKey = r.multiKey(multiIndexer, obj)
+	} else {
+		r.lastObj = obj
+	}
+	if r.tableSchema != nil {
+		return r.tableSchema.copyOnRead(obj)
+	}
+	return obj
+}
+
+func (r *radixIterator) Close() {
+	if r.instrumentation != nil {
+		r.instrumentation.QueryFinished(r.table, r.index, r.scanned)
+		r.instrumentation = nil
+	}
+}
+
+// KeyResultIterator is used to iterate over a list of results together with the keys they are stored
+// under in the index. For non-unique indices the key contains the primary key appended to the key produced
+// by the indexer. Key is not available for aggregate indices and for multi-key indices when Back is used.
+type KeyResultIterator interface {
+	// Next returns the next result and its key. If there are no more results
+	// nil is returned for both.
+	Next() ([]byte, unsafe.Pointer)
+
+	// Close reports the query to the instrumentation if iteration is stopped before Next returns nil.
+	// It is a noop if the iterator is exhausted or already closed.
+	Close()
+}
+
+type keyIterator struct {
+	iter *radixIterator
+}
+
+func (i *keyIterator) Next() ([]byte, unsafe.Pointer) {
+	obj := i.iter.Next()
+	if obj == nil {
+		return nil, nil
+	}
+	return i.iter.key(), obj
+}
+
+func (i *keyIterator) Close() {
+	i.iter.Close()
 }
 
 // readableIndex returns a transaction usable for reading the given index in a
 // table. If the transaction is a write transaction with modifications, a clone of the
 // modified index will be returned.
 func (txn *Txn) readableIndex(indexID uint64, clone bool) *iradix.Txn[unsafe.Pointer] {
-	index, dirty := txn.getRoot().Get(indexID)
+	index, dirty := txn.getState().tree.Get(indexID)
 	if dirty {
 		if clone {
 			return index.Clone()
@@ -294,10 +577,20 @@ func (txn *Txn) readableIndex(indexID uint64, clone bool) *iradix.Txn[unsafe.Poi
 	return iradix.NewTxn(index.Root())
 }
 
+// readableRoot returns the root of the index. If clone is true and the index has been modified by the transaction,
+// further modifications don't affect the returned root.
+func (txn *Txn) readableRoot(indexID uint64, clone bool) *iradix.Node[unsafe.Pointer] {
+	index, dirty := txn.getState().tree.Get(indexID)
+	if dirty && clone {
+		return index.Clone().Root()
+	}
+	return index.Root()
+}
+
 // writableIndex returns a transaction usable for modifying the
 // given index in a table.
 func (txn *Txn) writableIndex(indexID uint64) *iradix.Txn[unsafe.Pointer] {
-	root := txn.getRoot()
+	root := txn.getState().tree
 	index, dirty := root.Get(indexID)
 	if !dirty {
 		index = iradix.NewTxn(index.Root())
@@ -307,20 +600,37 @@ func (txn *Txn) writableIndex(indexID uint64) *iradix.Txn[unsafe.Pointer] {
 }
 
 func (txn *Txn) getIndexIterator(
+	sc *scratch,
+	instrumentation Instrumentation,
 	clone bool,
 	table, index uint64,
 	args ...any,
-) (*iradix.Iterator[unsafe.Pointer], error) {
-	if table >= uint64(len(txn.schema)) {
-		return nil, errors.Errorf("invalid table '%d'", table)
-	}
+) (*iradix.Iterator[unsafe.Pointer], seek, error) {
 	// Iterator the table schema.
-	tableSchema := txn.schema[table]
+	tableSchema, ok := txn.getState().schema[table]
+	if !ok {
+		return nil, seek{}, errors.Errorf("invalid table '%d'", table)
+	}
 
 	// Iterator the index schema.
-	indexSchema, ok := tableSchema[index]
+	indexSchema, ok := tableSchema.indices[index]
 	if !ok {
-		return nil, errors.Errorf("invalid index '%d'", index)
+		return nil, seek{}, errors.Errorf("invalid index '%d'", index)
+	}
+
+	// Cursor, if passed, is the last argument.
+	var cursorKey []byte
+	if len(args) > 0 {
+		if cursor, ok := args[len(args)-1].(Cursor); ok {
+			args = args[:len(args)-1]
+			if len(cursor) > 0 {
+				var err error
+				cursorKey, err = cursor.key(table, index)
+				if err != nil {
+					return nil, seek{}, err
+				}
+			}
+		}
 	}
 
 	// Iterator the exact match index.
@@ -330,36 +640,36 @@ func (txn *Txn) getIndexIterator(
 	var keySize uint64
 	for i, a := range args {
 		if op, ok := a.(Operator); ok {
+			if cursorKey != nil {
+				return nil, seek{}, errors.New("operators can't be used together with cursor")
+			}
 			if op == Back {
 				if len(args) != i+2 {
-					return nil, errors.New("invalid argument count")
+					return nil, seek{}, errors.New("invalid argument count")
 				}
 				break
 			}
 			continue
 		}
 		if numOfArgs == len(argDefs) {
-			return nil, errors.Errorf("too many arguments, received: %d, acceptable: %d", len(args),
+			return nil, seek{}, errors.Errorf("too many arguments, received: %d, acceptable: %d", len(args),
 				len(argDefs))
 		}
 		keySize += argDefs[numOfArgs].SizeFromArg(a)
 		numOfArgs++
 	}
 
-	indexTxn := txn.readableIndex(indexSchema.id, clone)
-	indexRoot := indexTxn.Root()
-
 	// Iterator an iterator over the index.
-	indexIter := indexRoot.Iterator()
+	indexIter := txn.readableRoot(indexSchema.id, clone).Iterator()
 
-	if numOfArgs == 0 {
-		return indexIter, nil
+	if numOfArgs == 0 && cursorKey == nil {
+		return indexIter, seek{}, nil
 	}
-	if keySize == 0 {
-		return nil, errors.Errorf("empty key")
+	if numOfArgs > 0 && keySize == 0 {
+		return nil, seek{}, errors.Errorf("empty key")
 	}
 
-	key := make([]byte, keySize)
+	key := sc.alloc(keySize)
 	fromArgs := keySize
 	var backCount uint64
 	var lastOperator Operator
@@ -370,7 +680,7 @@ loop:
 	for i, a := range args {
 		if op, ok := a.(Operator); ok {
 			if op <= lastOperator {
-				return nil, errors.New("invalid operator")
+				return nil, seek{}, errors.New("invalid operator")
 			}
 
 			switch op {
@@ -383,12 +693,12 @@ loop:
 				}
 				count, ok := args[i+1].(uint64)
 				if !ok {
-					return nil, errors.New("invalid count")
+					return nil, seek{}, errors.New("invalid count")
 				}
 				backCount = count
 				break loop
 			default:
-				return nil, errors.New("invalid operator")
+				return nil, seek{}, errors.New("invalid operator")
 			}
 			continue
 		}
@@ -396,18 +706,234 @@ loop:
 		argI++
 	}
 
+	if cursorKey != nil {
+		// Iteration continues right after the key stored in the cursor.
+		if !bytes.HasPrefix(cursorKey, key) {
+			return nil, seek{}, errors.New("cursor does not match the arguments")
+		}
+		lowerBound := make([]byte, len(cursorKey)+1)
+		copy(lowerBound, cursorKey)
+
+		if len(key) > 0 {
+			indexIter.SeekPrefix(key)
+		}
+		indexIter.SeekLowerBound(lowerBound[len(key):])
+		instrumentation.Seek(table, index)
+		return indexIter, seek{prefix: key, lowerBound: lowerBound, lastKey: cursorKey}, nil
+	}
+
+	s := seek{prefix: key[:fromArgs], back: backCount > 0}
 	if fromArgs > 0 {
 		indexIter.SeekPrefix(key[:fromArgs])
 	}
 	if fromArgs < uint64(len(key)) {
 		indexIter.SeekLowerBound(key[fromArgs:])
+		s.lowerBound = key
+	}
+	if len(key) > 0 {
+		instrumentation.Seek(table, index)
 	}
 	if backCount > 0 {
 		indexIter.Back(backCount)
 	}
-	return indexIter, nil
+	return indexIter, s, nil
+}
+
+var sequenceKey = []byte{0x00}
+
+// assignSequence assigns next value of the table sequence to the object if its primary key is not set,
+// otherwise it moves the sequence forward if needed.
+func (txn *Txn) assignSequence(idSchema *IndexSchema, indexer SequenceIndexer, obj unsafe.Pointer) {
+	seqTxn := txn.writableIndex(idSchema.sequenceID)
+	var current uint64
+	if v := seqTxn.Get(sequenceKey); v != nil {
+		current = *(*uint64)(v)
+	}
+
+	v := indexer.Sequence(obj)
+	switch {
+	case v == 0:
+		v = current + 1
+		indexer.SetSequence(obj, v)
+	case v <= current:
+		return
+	}
+	seqTxn.Insert(sequenceKey, unsafe.Pointer(&v))
+}
+
+// primaryKey returns the primary key of the object.
+func primaryKey(sc *scratch, idSchema *IndexSchema, obj unsafe.Pointer) ([]byte, error) {
+	id := sc.alloc(idSchema.Indexer.SizeFromObject(obj))
+	if len(id) == 0 {
+		return nil, errors.New("empty primary key")
+	}
+	idSchema.Indexer.FromObject(id, obj)
+	return id, nil
+}
+
+// updateIndex replaces previous version of the object in the index with the new one.
+// On an update, there is an existing object with the given primary ID.
+// We do the update by deleting the current object and inserting the new object.
+func updateIndex(
+	sc *scratch,
+	indexTxn *iradix.Txn[unsafe.Pointer],
+	indexSchema *IndexSchema,
+	previousObj, obj unsafe.Pointer,
+	id []byte,
+) {
+	defer sc.release(sc.mark())
+
+	if isMultiKeyOrAggregate(indexSchema) {
+		if previousObj != defaultPointer {
+			removeFromIndex(sc, indexTxn, indexSchema, previousObj, id)
+		}
+		addToIndex(sc, indexTxn, indexSchema, obj, id)
+		return
+	}
+
+	b := indexKey(sc, indexSchema, obj, id)
+
+	// Handle the update by deleting from the index first.
+	// If we are writing to the same index with the same value,
+	// we can avoid the delete as the insert will overwrite the
+	// value anyway.
+	if previousObj != defaultPointer {
+		if existingB := indexKey(sc, indexSchema, previousObj, id); existingB != nil && !bytes.Equal(existingB, b) {
+			indexTxn.Delete(existingB)
+		}
+	}
+
+	// Update the value of the index
+	if b != nil {
+		indexTxn.Insert(b, obj)
+	}
+}
+
+// addToIndex stores object in the index.
+func addToIndex(
+	sc *scratch,
+	indexTxn *iradix.Txn[unsafe.Pointer],
+	indexSchema *IndexSchema,
+	obj unsafe.Pointer,
+	id []byte,
+) {
+	defer sc.release(sc.mark())
+
+	if aggregateIndexer, ok := indexSchema.Indexer.(AggregateIndexer); ok {
+		if b := aggregateKey(sc, aggregateIndexer, obj); b != nil {
+			indexTxn.Insert(b, aggregateIndexer.Add(indexTxn.Get(b), obj))
+		}
+		return
+	}
+	if multiIndexer, ok := indexSchema.Indexer.(MultiKeyIndexer); ok {
+		m := sc.mark()
+		for _, key := range multiIndexer.KeysFromObject(obj) {
+			if b := multiKey(sc, indexSchema, key, id); b != nil {
+				indexTxn.Insert(b, obj)
+			}
+			sc.release(m)
+		}
+		return
+	}
+	if b := indexKey(sc, indexSchema, obj, id); b != nil {
+		indexTxn.Insert(b, obj)
+	}
+}
+
+// removeFromIndex removes object from the index.
+func removeFromIndex(
+	sc *scratch,
+	indexTxn *iradix.Txn[unsafe.Pointer],
+	indexSchema *IndexSchema,
+	obj unsafe.Pointer,
+	id []byte,
+) {
+	defer sc.release(sc.mark())
+
+	if aggregateIndexer, ok := indexSchema.Indexer.(AggregateIndexer); ok {
+		if b := aggregateKey(sc, aggregateIndexer, obj); b != nil {
+			if agg := aggregateIndexer.Remove(indexTxn.Get(b), obj); agg != nil {
+				indexTxn.Insert(b, agg)
+			} else {
+				indexTxn.Delete(b)
+			}
+		}
+		return
+	}
+	if multiIndexer, ok := indexSchema.Indexer.(MultiKeyIndexer); ok {
+		m := sc.mark()
+		for _, key := range multiIndexer.KeysFromObject(obj) {
+			if b := multiKey(sc, indexSchema, key, id); b != nil {
+				indexTxn.Delete(b)
+			}
+			sc.release(m)
+		}
+		return
+	}
+	if b := indexKey(sc, indexSchema, obj, id); b != nil {
+		indexTxn.Delete(b)
+	}
+}
+
+// indexKey builds the key of the object in the index.
+// For non-unique index the primary key is appended.
+func indexKey(sc *scratch, indexSchema *IndexSchema, o unsafe.Pointer, id []byte) []byte {
+	keySize := indexSchema.Indexer.SizeFromObject(o)
+	if keySize == 0 {
+		return nil
+	}
+
+	// Handle non-unique index by computing a unique index.
+	// This is done by appending the primary key which must
+	// be unique anyway.
+	if !indexSchema.Unique {
+		keySize += uint64(len(id))
+	}
+
+	b := sc.alloc(keySize)
+	n := indexSchema.Indexer.FromObject(b, o)
+	if !indexSchema.Unique {
+		copy(b[n:], id)
+	}
+	return b
+}
+
+// multiKey builds the index key from the key produced by multi-key indexer for the object.
+// For non-unique index the primary key is appended.
+func multiKey(sc *scratch, indexSchema *IndexSchema, key, id []byte) []byte {
+	if len(key) == 0 {
+		return nil
+	}
+	if indexSchema.Unique {
+		return key
+	}
+
+	b := sc.alloc(uint64(len(key) + len(id)))
+	copy(b[copy(b, key):], id)
+	return b
+}
+
+// aggregateKey builds the key of the aggregate the object belongs to.
+func aggregateKey(sc *scratch, indexer AggregateIndexer, o unsafe.Pointer) []byte {
+	keySize := indexer.SizeFromObject(o)
+	if keySize == 0 {
+		return nil
+	}
+
+	b := sc.alloc(keySize)
+	return b[:indexer.FromObject(b, o)]
+}
+
+// isMultiKeyOrAggregate returns true if object must be removed from the index before it is updated.
+func isMultiKeyOrAggregate(indexSchema *IndexSchema) bool {
+	switch indexSchema.Indexer.(type) {
+	case MultiKeyIndexer, AggregateIndexer:
+		return true
+	default:
+		return false
+	}
 }
 
-func (txn *Txn) getRoot() *tree.Tree[*iradix.Txn[unsafe.Pointer]] {
-	return (*tree.Tree[*iradix.Txn[unsafe.Pointer]])(txn.root)
+func (txn *Txn) getState() *state {
+	return (*state)(txn.root)
 }
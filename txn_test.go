@@ -19,13 +19,13 @@ func TestTxn_Insert_First(t *testing.T) {
 	txn := db.Txn(true)
 
 	obj := testObj()
-	oldV, err := txn.Insert(0, unsafe.Pointer(obj))
+	oldV, err := txn.Insert(objectTableID, unsafe.Pointer(obj))
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
 	require.Zero(t, oldV)
 
-	raw, err := txn.First(0, memdb.IDIndexID, obj.ID)
+	raw, err := txn.First(objectTableID, memdb.IDIndexID, obj.ID)
 	require.NoError(t, err)
 	require.NotZero(t, raw)
 	require.Equal(t, obj, (*TestObject)(raw))
@@ -39,13 +39,13 @@ func TestTxn_InsertUpdate_First(t *testing.T) {
 		ID:  memdb.ID{1},
 		Foo: "abc",
 	}
-	oldV, err := txn.Insert(0, unsafe.Pointer(obj))
+	oldV, err := txn.Insert(objectTableID, unsafe.Pointer(obj))
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
 	require.Zero(t, oldV)
 
-	raw, err := txn.First(0, memdb.IDIndexID, obj.ID)
+	raw, err := txn.First(objectTableID, memdb.IDIndexID, obj.ID)
 	require.NoError(t, err)
 	require.NotZero(t, raw)
 	require.Equal(t, obj, (*TestObject)(raw))
@@ -55,12 +55,12 @@ func TestTxn_InsertUpdate_First(t *testing.T) {
 		ID:  memdb.ID{1},
 		Foo: "xyz",
 	}
-	oldV, err = txn.Insert(0, unsafe.Pointer(obj2))
+	oldV, err = txn.Insert(objectTableID, unsafe.Pointer(obj2))
 	require.NoError(t, err)
 	require.NotZero(t, oldV)
 	require.Equal(t, obj, (*TestObject)(oldV))
 
-	raw, err = txn.First(0, memdb.IDIndexID, obj.ID)
+	raw, err = txn.First(objectTableID, memdb.IDIndexID, obj.ID)
 	require.NoError(t, err)
 	require.NotZero(t, raw)
 	require.Equal(t, obj2, (*TestObject)(raw))
@@ -74,11 +74,11 @@ func TestTxn_InsertUpdate_First_NonUnique(t *testing.T) {
 		ID:  memdb.ID{1},
 		Foo: "abc",
 	}
-	oldV, err := txn.Insert(0, unsafe.Pointer(obj))
+	oldV, err := txn.Insert(objectTableID, unsafe.Pointer(obj))
 	require.NoError(t, err)
 	require.Zero(t, oldV)
 
-	raw, err := txn.First(0, indexFoo.ID(), obj.Foo)
+	raw, err := txn.First(objectTableID, indexFoo.ID(), obj.Foo)
 	require.NoError(t, err)
 	require.NotZero(t, raw)
 	require.Equal(t, obj, (*TestObject)(raw))
@@ -88,18 +88,18 @@ func TestTxn_InsertUpdate_First_NonUnique(t *testing.T) {
 		ID:  memdb.ID{1},
 		Foo: "xyz",
 	}
-	oldV, err = txn.Insert(0, unsafe.Pointer(obj2))
+	oldV, err = txn.Insert(objectTableID, unsafe.Pointer(obj2))
 	require.NoError(t, err)
 	require.NotZero(t, oldV)
 	require.Equal(t, obj, (*TestObject)(oldV))
 
-	raw, err = txn.First(0, indexFoo.ID(), obj2.Foo)
+	raw, err = txn.First(objectTableID, indexFoo.ID(), obj2.Foo)
 	require.NoError(t, err)
 	require.NotZero(t, raw)
 	require.Equal(t, obj2, (*TestObject)(raw))
 
 	// Lookup of the old value should fail
-	raw, err = txn.First(0, indexFoo.ID(), obj.Foo)
+	raw, err = txn.First(objectTableID, indexFoo.ID(), obj.Foo)
 	require.NoError(t, err)
 	require.Zero(t, raw)
 }
@@ -121,27 +121,27 @@ func TestTxn_First_NonUnique_Multiple(t *testing.T) {
 		Foo: "xyz",
 	}
 
-	oldV, err := txn.Insert(0, unsafe.Pointer(obj))
+	oldV, err := txn.Insert(objectTableID, unsafe.Pointer(obj))
 	require.NoError(t, err)
 	require.Zero(t, oldV)
 
-	oldV, err = txn.Insert(0, unsafe.Pointer(obj2))
+	oldV, err = txn.Insert(objectTableID, unsafe.Pointer(obj2))
 	require.NoError(t, err)
 	require.Zero(t, oldV)
 
-	oldV, err = txn.Insert(0, unsafe.Pointer(obj3))
+	oldV, err = txn.Insert(objectTableID, unsafe.Pointer(obj3))
 	require.NoError(t, err)
 	require.Zero(t, oldV)
 
 	// The first object has a unique secondary value
-	raw, err := txn.First(0, indexFoo.ID(), obj.Foo)
+	raw, err := txn.First(objectTableID, indexFoo.ID(), obj.Foo)
 	require.NoError(t, err)
 	require.NotZero(t, raw)
 	require.Equal(t, obj, (*TestObject)(raw))
 
 	// Second and third object share secondary value,
 	// but the primary ID of obj2 should be first
-	raw, err = txn.First(0, indexFoo.ID(), obj2.Foo)
+	raw, err = txn.First(objectTableID, indexFoo.ID(), obj2.Foo)
 	require.NoError(t, err)
 	require.NotZero(t, raw)
 	require.Equal(t, obj2, (*TestObject)(raw))
@@ -160,53 +160,53 @@ func TestTxn_InsertDelete_Simple(t *testing.T) {
 		Foo: "xyz",
 	}
 
-	oldV, err := txn.Insert(0, unsafe.Pointer(obj1))
+	oldV, err := txn.Insert(objectTableID, unsafe.Pointer(obj1))
 	require.NoError(t, err)
 	require.Zero(t, oldV)
 
-	oldV, err = txn.Insert(0, unsafe.Pointer(obj2))
+	oldV, err = txn.Insert(objectTableID, unsafe.Pointer(obj2))
 	require.NoError(t, err)
 	require.Zero(t, oldV)
 
 	// Check the shared secondary value,
 	// but the primary ID of obj2 should be first
-	raw, err := txn.First(0, indexFoo.ID(), obj2.Foo)
+	raw, err := txn.First(objectTableID, indexFoo.ID(), obj2.Foo)
 	require.NoError(t, err)
 	require.NotZero(t, raw)
 	require.Equal(t, obj1, (*TestObject)(raw))
 
 	// Commit and start a new transaction
-	txn.Commit()
+	require.NoError(t, txn.Commit())
 	txn = db.Txn(true)
 
 	// Delete obj1
-	oldV, err = txn.Delete(0, unsafe.Pointer(obj1))
+	oldV, err = txn.Delete(objectTableID, unsafe.Pointer(obj1))
 	require.NoError(t, err)
 	require.NotZero(t, oldV)
 	require.Equal(t, obj1, (*TestObject)(oldV))
 
 	// Delete obj1 again and expect ErrNotFound
-	oldV, err = txn.Delete(0, unsafe.Pointer(obj1))
+	oldV, err = txn.Delete(objectTableID, unsafe.Pointer(obj1))
 	require.ErrorIs(t, err, memdb.ErrNotFound)
 	require.Zero(t, oldV)
 
 	// Lookup of the primary obj1 should fail
-	raw, err = txn.First(0, memdb.IDIndexID, obj1.ID)
+	raw, err = txn.First(objectTableID, memdb.IDIndexID, obj1.ID)
 	require.NoError(t, err)
 	require.Zero(t, raw)
 
 	// Commit and start a new read transaction
-	txn.Commit()
+	require.NoError(t, txn.Commit())
 	txn = db.Txn(false)
 
 	// Lookup of the primary obj1 should fail
-	raw, err = txn.First(0, memdb.IDIndexID, obj1.ID)
+	raw, err = txn.First(objectTableID, memdb.IDIndexID, obj1.ID)
 	require.NoError(t, err)
 	require.Zero(t, raw)
 
 	// Check the shared secondary value,
 	// but the primary ID of obj2 should be first
-	raw, err = txn.First(0, indexFoo.ID(), obj2.Foo)
+	raw, err = txn.First(objectTableID, indexFoo.ID(), obj2.Foo)
 	require.NoError(t, err)
 	require.NotZero(t, raw)
 	require.Equal(t, obj2, (*TestObject)(raw))
@@ -225,24 +225,24 @@ func TestTxn_InsertGet_Simple(t *testing.T) {
 		Foo: "xyz",
 	}
 
-	oldV, err := txn.Insert(0, unsafe.Pointer(obj1))
+	oldV, err := txn.Insert(objectTableID, unsafe.Pointer(obj1))
 	require.NoError(t, err)
 	require.Zero(t, oldV)
 
-	oldV, err = txn.Insert(0, unsafe.Pointer(obj2))
+	oldV, err = txn.Insert(objectTableID, unsafe.Pointer(obj2))
 	require.NoError(t, err)
 	require.Zero(t, oldV)
 
 	checkResult := func(txn *memdb.Txn) {
 		// Attempt a row scan on the ID
-		result, err := txn.Iterator(0, memdb.IDIndexID)
+		result, err := txn.Iterator(objectTableID, memdb.IDIndexID)
 		require.NoError(t, err)
 		require.Equal(t, obj1, (*TestObject)(result.Next()))
 		require.Equal(t, obj2, (*TestObject)(result.Next()))
 		require.Zero(t, result.Next())
 
 		// Attempt a row scan on the ID with specific ID
-		result, err = txn.Iterator(0, memdb.IDIndexID, obj1.ID)
+		result, err = txn.Iterator(objectTableID, memdb.IDIndexID, obj1.ID)
 		require.NoError(t, err)
 		if err != nil {
 			t.Fatalf("err: %v", err)
@@ -251,7 +251,7 @@ func TestTxn_InsertGet_Simple(t *testing.T) {
 		require.Zero(t, result.Next())
 
 		// Attempt a row scan secondary index
-		result, err = txn.Iterator(0, indexFoo.ID(), obj1.Foo)
+		result, err = txn.Iterator(objectTableID, indexFoo.ID(), obj1.Foo)
 		require.NoError(t, err)
 		require.Equal(t, obj1, (*TestObject)(result.Next()))
 		require.Equal(t, obj2, (*TestObject)(result.Next()))
@@ -262,7 +262,7 @@ func TestTxn_InsertGet_Simple(t *testing.T) {
 	checkResult(txn)
 
 	// Commit and start a new read transaction
-	txn.Commit()
+	require.NoError(t, txn.Commit())
 	txn = db.Txn(false)
 
 	// Check the results in a new txn
@@ -282,36 +282,36 @@ func TestTxn_GetIterAndDelete(t *testing.T) {
 
 	key := "aaaa"
 	txn := db.Txn(true)
-	oldV, err := txn.Insert(0, unsafe.Pointer(&TestObject{ID: memdb.ID{1}, Foo: key}))
+	oldV, err := txn.Insert(objectTableID, unsafe.Pointer(&TestObject{ID: memdb.ID{1}, Foo: key}))
 	require.NoError(t, err)
 	require.Zero(t, oldV)
 
-	oldV, err = txn.Insert(0, unsafe.Pointer(&TestObject{ID: memdb.ID{123}, Foo: key}))
+	oldV, err = txn.Insert(objectTableID, unsafe.Pointer(&TestObject{ID: memdb.ID{123}, Foo: key}))
 	require.NoError(t, err)
 	require.Zero(t, oldV)
 
-	oldV, err = txn.Insert(0, unsafe.Pointer(&TestObject{ID: memdb.ID{2}, Foo: key}))
+	oldV, err = txn.Insert(objectTableID, unsafe.Pointer(&TestObject{ID: memdb.ID{2}, Foo: key}))
 	require.NoError(t, err)
 	require.Zero(t, oldV)
 
-	txn.Commit()
+	require.NoError(t, txn.Commit())
 
 	txn = db.Txn(true)
 	// Delete something
-	oldV, err = txn.Delete(0, unsafe.Pointer(&TestObject{ID: memdb.ID{123}, Foo: key}))
+	oldV, err = txn.Delete(objectTableID, unsafe.Pointer(&TestObject{ID: memdb.ID{123}, Foo: key}))
 	require.NoError(t, err)
 	require.NotZero(t, oldV)
 	require.Equal(t, &TestObject{ID: memdb.ID{123}, Foo: key}, (*TestObject)(oldV))
 
-	iter, err := txn.Iterator(0, indexFoo.ID(), key)
+	iter, err := txn.Iterator(objectTableID, indexFoo.ID(), key)
 	require.NoError(t, err)
 
 	for obj := iter.Next(); obj != nil; obj = iter.Next() {
-		_, err := txn.Delete(0, obj)
+		_, err := txn.Delete(objectTableID, obj)
 		require.NoError(t, err)
 	}
 
-	txn.Commit()
+	require.NoError(t, txn.Commit())
 }
 
 func TestTxn_LowerBound(t *testing.T) {
@@ -369,16 +369,16 @@ func TestTxn_LowerBound(t *testing.T) {
 
 			txn := db.Txn(true)
 			for _, row := range tc.Rows {
-				_, err := txn.Insert(0, unsafe.Pointer(&row))
+				_, err := txn.Insert(objectTableID, unsafe.Pointer(&row))
 				if err != nil {
 					t.Fatalf("err inserting: %s", err)
 				}
 			}
-			txn.Commit()
+			require.NoError(t, txn.Commit())
 
 			txn = db.Txn(false)
 
-			iterator, err := txn.Iterator(0, 0, memdb.From, tc.Search)
+			iterator, err := txn.Iterator(objectTableID, 0, memdb.From, tc.Search)
 			if err != nil {
 				t.Fatalf("err lower bound: %s", err)
 			}
@@ -410,14 +410,14 @@ func TestTxn_Back(t *testing.T) {
 
 	txn := db.Txn(true)
 	for _, row := range rows {
-		_, err := txn.Insert(0, unsafe.Pointer(&row))
+		_, err := txn.Insert(objectTableID, unsafe.Pointer(&row))
 		require.NoError(t, err)
 	}
-	txn.Commit()
+	require.NoError(t, txn.Commit())
 
 	txn = db.Txn(false)
 
-	iterator, err := txn.Iterator(0, 0, memdb.From, rows[5].ID, memdb.Back, 3)
+	iterator, err := txn.Iterator(objectTableID, 0, memdb.From, rows[5].ID, memdb.Back, 3)
 	require.NoError(t, err)
 
 	// Now range scan and built a result set
@@ -436,3 +436,38 @@ func testDB(t *testing.T) *memdb.MemDB {
 	}
 	return db
 }
+
+func TestTxn_KeyIterator(t *testing.T) {
+	db := testCursorDB(t)
+	txn := db.Txn(false)
+
+	decoder := indexFoo.Schema().Indexer.(memdb.DecodingIndexer)
+	iterator, err := txn.KeyIterator(objectTableID, indexFoo.ID(), "b")
+	require.NoError(t, err)
+
+	var keys [][]byte
+	for key, obj := iterator.Next(); obj != nil; key, obj = iterator.Next() {
+		// Primary key is appended to the key of non-unique index.
+		id := (*TestObject)(obj).ID
+		require.Equal(t, append([]byte("b\x00"), id[:]...), key)
+		require.Equal(t, []any{"b"}, decoder.Decode(key))
+		keys = append(keys, key)
+	}
+	require.Len(t, keys, 2)
+
+	// Multi-key index returns the key object is returned for.
+	iterator, err = txn.KeyIterator(objectTableID, indexFooBaz.ID())
+	require.NoError(t, err)
+
+	decoder = indexFooBaz.Schema().Indexer.(memdb.DecodingIndexer)
+	var values []any
+	for key, obj := iterator.Next(); obj != nil; key, obj = iterator.Next() {
+		values = append(values, decoder.Decode(key)...)
+	}
+	require.Equal(t, []any{"a", "a", "a", "a", "b", "b", "b", "c", "c"}, values)
+
+	iterator, err = txn.KeyIterator(objectTableID, memdb.IDIndexID)
+	require.NoError(t, err)
+	key, obj := iterator.Next()
+	require.Equal(t, []any{(*TestObject)(obj).ID}, memdb.IDIndexer{}.Decode(key))
+}
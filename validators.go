@@ -0,0 +1,107 @@
+package memdb
+
+import (
+	"bytes"
+	"reflect"
+	"unsafe"
+
+	"github.com/pkg/errors"
+)
+
+// Validator validates entities stored in the table.
+type Validator interface {
+	// Type returns type of entity validator is created for.
+	Type() reflect.Type
+
+	// Deferred returns true if validator is executed on commit instead of on each insert.
+	Deferred() bool
+
+	// Validate validates the entity.
+	Validate(txn *Txn, o unsafe.Pointer) error
+}
+
+// NewValidator creates validator executed on each insert of the entity, before it is stored.
+func NewValidator[T any](f func(txn *Txn, obj *T) error) Validator {
+	return &validator[T]{f: f}
+}
+
+// NewDeferredValidator creates validator executed on commit, once for each entity inserted or updated
+// by the transaction.
+func NewDeferredValidator[T any](f func(txn *Txn, obj *T) error) Validator {
+	return &validator[T]{f: f, deferred: true}
+}
+
+type validator[T any] struct {
+	f        func(txn *Txn, obj *T) error
+	deferred bool
+}
+
+func (v *validator[T]) Type() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+func (v *validator[T]) Deferred() bool {
+	return v.deferred
+}
+
+func (v *validator[T]) Validate(txn *Txn, o unsafe.Pointer) error {
+	return v.f(txn, (*T)(o))
+}
+
+// pendingValidation is the deferred validation of the entity executed on commit.
+type pendingValidation struct {
+	table uint64
+	id    []byte
+}
+
+// validate executes immediate validators and schedules deferred ones.
+func (txn *Txn) validate(table uint64, t *tableSchema, obj unsafe.Pointer, id []byte) error {
+	var deferred bool
+	for _, v := range t.validators {
+		if v.Deferred() {
+			deferred = true
+			continue
+		}
+		if err := v.Validate(txn, obj); err != nil {
+			return errors.Wrapf(err, "validation of entity %s failed", t.eType)
+		}
+	}
+	if deferred {
+		s := txn.getState()
+		s.validations = append(s.validations, pendingValidation{table: table, id: bytes.Clone(id)})
+	}
+	return nil
+}
+
+// checkValidations executes deferred validators.
+func (txn *Txn) checkValidations() error {
+	s := txn.getState()
+	validated := map[uint64]map[string]struct{}{}
+	for _, v := range s.validations {
+		if _, exists := validated[v.table][string(v.id)]; exists {
+			continue
+		}
+		if validated[v.table] == nil {
+			validated[v.table] = map[string]struct{}{}
+		}
+		validated[v.table][string(v.id)] = struct{}{}
+
+		t, ok := s.schema[v.table]
+		if !ok {
+			continue
+		}
+		obj := txn.readableIndex(t.indices[IDIndexID].id, false).Get(v.id)
+		if obj == defaultPointer {
+			continue
+		}
+		for _, validator := range t.validators {
+			if !validator.Deferred() {
+				continue
+			}
+			if err := validator.Validate(txn, obj); err != nil {
+				return errors.Wrapf(err, "validation of entity %s failed", t.eType)
+			}
+		}
+	}
+	return nil
+}
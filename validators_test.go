@@ -0,0 +1,115 @@
+package memdb_test
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/outofforest/memdb"
+)
+
+var errInvalidName = errors.New("invalid name")
+
+func TestValidator_Immediate(t *testing.T) {
+	requireT := require.New(t)
+
+	db, err := memdb.NewMemDB(memdb.Config{
+		Entities: []reflect.Type{reflect.TypeFor[testProject]()},
+		Validators: []memdb.Validator{
+			memdb.NewValidator(func(txn *memdb.Txn, p *testProject) error {
+				if p.Name == "" {
+					return errInvalidName
+				}
+				return nil
+			}),
+		},
+	})
+	requireT.NoError(err)
+
+	txn := db.Txn(true)
+	err = insert(txn, projectsTableID, unsafe.Pointer(&testProject{ID: testProjectID}))
+	requireT.ErrorIs(err, errInvalidName)
+	requireT.NoError(insert(txn, projectsTableID, unsafe.Pointer(&testProject{ID: testProjectID, Name: "A"})))
+	requireT.NoError(txn.Commit())
+
+	txn = db.Txn(false)
+	o, err := txn.First(projectsTableID, memdb.IDIndexID, testProjectID)
+	requireT.NoError(err)
+	requireT.Equal("A", (*testProject)(o).Name)
+}
+
+func TestValidator_Deferred(t *testing.T) {
+	requireT := require.New(t)
+
+	var calls int
+	db, err := memdb.NewMemDB(memdb.Config{
+		Entities: []reflect.Type{reflect.TypeFor[testProject]()},
+		Validators: []memdb.Validator{
+			memdb.NewDeferredValidator(func(txn *memdb.Txn, p *testProject) error {
+				calls++
+				if p.Name == "" {
+					return errInvalidName
+				}
+				return nil
+			}),
+		},
+	})
+	requireT.NoError(err)
+
+	txn := db.Txn(true)
+	requireT.NoError(insert(txn, projectsTableID, unsafe.Pointer(&testProject{ID: testProjectID})))
+	requireT.NoError(insert(txn, projectsTableID, unsafe.Pointer(&testProject{ID: testProjectID})))
+	requireT.Zero(calls)
+	requireT.ErrorIs(txn.Commit(), errInvalidName)
+	requireT.Equal(1, calls)
+
+	requireT.NoError(insert(txn, projectsTableID, unsafe.Pointer(&testProject{ID: testProjectID, Name: "A"})))
+	requireT.NoError(txn.Commit())
+	requireT.Equal(2, calls)
+
+	// Deleted entities are not validated.
+	txn = db.Txn(true)
+	requireT.NoError(insert(txn, projectsTableID, unsafe.Pointer(&testProject{ID: testMissingID})))
+	_, err = txn.Delete(projectsTableID, unsafe.Pointer(&testProject{ID: testMissingID}))
+	requireT.NoError(err)
+	requireT.NoError(txn.Commit())
+	requireT.Equal(2, calls)
+}
+
+func TestValidator_DeferredInSubTransaction(t *testing.T) {
+	requireT := require.New(t)
+
+	db, err := memdb.NewMemDB(memdb.Config{
+		Entities: []reflect.Type{reflect.TypeFor[testProject]()},
+		Validators: []memdb.Validator{
+			memdb.NewDeferredValidator(func(txn *memdb.Txn, p *testProject) error {
+				if p.Name == "" {
+					return errInvalidName
+				}
+				return nil
+			}),
+		},
+	})
+	requireT.NoError(err)
+
+	txn := db.Txn(true)
+	subTxn := txn.Txn(true)
+	requireT.NoError(insert(subTxn, projectsTableID, unsafe.Pointer(&testProject{ID: testProjectID})))
+	requireT.NoError(subTxn.Commit())
+	requireT.ErrorIs(txn.Commit(), errInvalidName)
+}
+
+func TestValidator_UndefinedEntity(t *testing.T) {
+	_, err := memdb.NewMemDB(memdb.Config{
+		Entities: []reflect.Type{reflect.TypeFor[testJob]()},
+		Validators: []memdb.Validator{
+			memdb.NewValidator(func(txn *memdb.Txn, p *testProject) error {
+				return nil
+			}),
+		},
+	})
+	require.ErrorContains(t, err, "validator for undefined entity")
+}
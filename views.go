@@ -0,0 +1,147 @@
+package memdb
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"unsafe"
+
+	"github.com/pkg/errors"
+)
+
+// View derives entities stored in the view table from entities of the source table. Many views may share the view
+// table, so entities in it may be derived from many source tables.
+//
+// View is updated when top-level transaction is committed. For each source entity changed by the transaction,
+// entities derived from its version existing before the transaction are deleted and entities derived from the current
+// version are inserted. Entities derived from the previous version are used only to find the entities to delete,
+// so only their primary keys matter. Entities in the view table can't be modified directly.
+type View interface {
+	// Type returns type of entities stored in the view table.
+	Type() reflect.Type
+
+	// Source returns type of entities the view is derived from.
+	Source() reflect.Type
+
+	// Derive returns entities derived from the source entity. Transaction may be used to read other entities,
+	// e.g. to join them with the source one.
+	Derive(txn *Txn, o unsafe.Pointer) []unsafe.Pointer
+}
+
+// NewView creates view deriving entities of type V from entities of type S.
+func NewView[S, V any](f func(txn *Txn, s *S) []*V) View {
+	return &view[S, V]{f: f}
+}
+
+type view[S, V any] struct {
+	f func(txn *Txn, s *S) []*V
+}
+
+func (v *view[S, V]) Type() reflect.Type {
+	return reflect.TypeFor[V]()
+}
+
+func (v *view[S, V]) Source() reflect.Type {
+	return reflect.TypeFor[S]()
+}
+
+func (v *view[S, V]) Derive(txn *Txn, o unsafe.Pointer) []unsafe.Pointer {
+	derived := v.f(txn, (*S)(o))
+	result := make([]unsafe.Pointer, 0, len(derived))
+	for _, d := range derived {
+		result = append(result, unsafe.Pointer(d))
+	}
+	return result
+}
+
+// viewChange is the change of the source entity applied to views on commit.
+type viewChange struct {
+	table uint64
+	id    []byte
+
+	// oldObj is the version of the entity existing before the change.
+	oldObj unsafe.Pointer
+}
+
+// addView validates the view and adds it to the schema.
+func (s *state) addView(v View) error {
+	viewTable, ok := s.schema[TableID(v.Type())]
+	if !ok {
+		return fmt.Errorf("view of undefined entity %s", v.Type())
+	}
+	source, ok := s.schema[TableID(v.Source())]
+	if !ok {
+		return fmt.Errorf("view from undefined entity %s", v.Source())
+	}
+	if len(viewTable.views) > 0 || source.isView {
+		return fmt.Errorf("view of entity %s can't be derived from view %s", v.Type(), v.Source())
+	}
+
+	// Schema is modified in place, so it must be called on the schema not shared with other transactions.
+	viewTable.isView = true
+	source.views = append(source.views, v)
+	return nil
+}
+
+// recordChange stores the change of the entity to be applied to views on commit.
+func (txn *Txn) recordChange(table uint64, id []byte, oldObj unsafe.Pointer) {
+	s := txn.getState()
+	s.changes = append(s.changes, viewChange{table: table, id: bytes.Clone(id), oldObj: oldObj})
+}
+
+// updateViews applies changes of source entities to views.
+func (txn *Txn) updateViews() error {
+	s := txn.getState()
+	if len(s.changes) == 0 {
+		return nil
+	}
+
+	txn.updatingViews = true
+	defer func() {
+		txn.updatingViews = false
+	}()
+
+	// applied holds the versions of entities views are derived from. If entity is not there yet, its first change
+	// holds the version existing before the transaction. Triggers executed while views are updated may add
+	// more changes, so slice is iterated by index.
+	applied := map[uint64]map[string]unsafe.Pointer{}
+	for i := 0; i < len(s.changes); i++ {
+		c := s.changes[i]
+		t, ok := s.schema[c.table]
+		if !ok {
+			continue
+		}
+
+		if applied[c.table] == nil {
+			applied[c.table] = map[string]unsafe.Pointer{}
+		}
+		oldObj, exists := applied[c.table][string(c.id)]
+		if !exists {
+			oldObj = c.oldObj
+		}
+		newObj := txn.readableIndex(t.indices[IDIndexID].id, false).Get(c.id)
+		applied[c.table][string(c.id)] = newObj
+		if newObj == oldObj {
+			continue
+		}
+
+		for _, v := range t.views {
+			viewTable := TableID(v.Type())
+			if oldObj != nil {
+				for _, d := range v.Derive(txn, oldObj) {
+					if _, err := txn.Delete(viewTable, d); err != nil && !errors.Is(err, ErrNotFound) {
+						return errors.Wrapf(err, "updating view %s failed", v.Type())
+					}
+				}
+			}
+			if newObj != nil {
+				for _, d := range v.Derive(txn, newObj) {
+					if _, err := txn.Insert(viewTable, d); err != nil {
+						return errors.Wrapf(err, "updating view %s failed", v.Type())
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
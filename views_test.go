@@ -0,0 +1,183 @@
+package memdb_test
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/outofforest/memdb"
+	"github.com/outofforest/memdb/indices"
+)
+
+type testNode struct {
+	ID   memdb.ID
+	Name string
+}
+
+type testAllocation struct {
+	ID     memdb.ID
+	NodeID memdb.ID
+}
+
+type testAllocationNode struct {
+	ID       memdb.ID
+	NodeName string
+}
+
+var (
+	allocation             testAllocation
+	allocationNodeIndex    = indices.NewFieldIndex(&allocation, &allocation.NodeID)
+	allocationNode         testAllocationNode
+	allocationNameIndex    = indices.NewFieldIndex(&allocationNode, &allocationNode.NodeName)
+	nodesTableID           = memdb.TableID(reflect.TypeFor[testNode]())
+	allocationsTableID     = memdb.TableID(reflect.TypeFor[testAllocation]())
+	allocationNodesTableID = memdb.TableID(reflect.TypeFor[testAllocationNode]())
+	testNodeID1            = memdb.ID{1}
+	testNodeID2            = memdb.ID{2}
+	testAllocationID1      = memdb.ID{3}
+	testAllocationID2      = memdb.ID{4}
+	testAllocationID3      = memdb.ID{5}
+)
+
+func testViewDB(t *testing.T) *memdb.MemDB {
+	db, err := memdb.NewMemDB(memdb.Config{
+		Entities: []reflect.Type{
+			reflect.TypeFor[testNode](),
+			reflect.TypeFor[testAllocation](),
+			reflect.TypeFor[testAllocationNode](),
+		},
+		Indices: []memdb.Index{allocationNodeIndex, allocationNameIndex},
+		Views: []memdb.View{
+			memdb.NewView(func(txn *memdb.Txn, a *testAllocation) []*testAllocationNode {
+				n, err := txn.First(nodesTableID, memdb.IDIndexID, a.NodeID)
+				require.NoError(t, err)
+				if n == nil {
+					return nil
+				}
+				return []*testAllocationNode{{ID: a.ID, NodeName: (*testNode)(n).Name}}
+			}),
+			memdb.NewView(func(txn *memdb.Txn, n *testNode) []*testAllocationNode {
+				it, err := txn.Iterator(allocationsTableID, allocationNodeIndex.ID(), n.ID)
+				require.NoError(t, err)
+				var result []*testAllocationNode
+				for a := it.Next(); a != nil; a = it.Next() {
+					result = append(result, &testAllocationNode{ID: (*testAllocation)(a).ID, NodeName: n.Name})
+				}
+				return result
+			}),
+		},
+	})
+	require.NoError(t, err)
+	return db
+}
+
+func readAllocationNodes(t *testing.T, txn *memdb.Txn, nodeName string) []memdb.ID {
+	it, err := txn.Iterator(allocationNodesTableID, allocationNameIndex.ID(), nodeName)
+	require.NoError(t, err)
+	var result []memdb.ID
+	for o := it.Next(); o != nil; o = it.Next() {
+		result = append(result, (*testAllocationNode)(o).ID)
+	}
+	return result
+}
+
+func TestView(t *testing.T) {
+	requireT := require.New(t)
+	db := testViewDB(t)
+
+	txn := db.Txn(true)
+	requireT.NoError(insert(txn, nodesTableID, unsafe.Pointer(&testNode{ID: testNodeID1, Name: "a"})))
+	requireT.NoError(insert(txn, nodesTableID, unsafe.Pointer(&testNode{ID: testNodeID2, Name: "b"})))
+	requireT.NoError(insert(txn, allocationsTableID,
+		unsafe.Pointer(&testAllocation{ID: testAllocationID1, NodeID: testNodeID1})))
+	requireT.NoError(insert(txn, allocationsTableID,
+		unsafe.Pointer(&testAllocation{ID: testAllocationID2, NodeID: testNodeID1})))
+	requireT.NoError(insert(txn, allocationsTableID,
+		unsafe.Pointer(&testAllocation{ID: testAllocationID3, NodeID: testNodeID2})))
+
+	// View is updated on commit.
+	requireT.Empty(readAllocationNodes(t, txn, "a"))
+	requireT.NoError(txn.Commit())
+
+	snapshot := db.Txn(false)
+	requireT.Equal([]memdb.ID{testAllocationID1, testAllocationID2}, readAllocationNodes(t, snapshot, "a"))
+	requireT.Equal([]memdb.ID{testAllocationID3}, readAllocationNodes(t, snapshot, "b"))
+
+	txn = db.Txn(true)
+	requireT.NoError(insert(txn, nodesTableID, unsafe.Pointer(&testNode{ID: testNodeID1, Name: "c"})))
+	requireT.NoError(insert(txn, allocationsTableID,
+		unsafe.Pointer(&testAllocation{ID: testAllocationID3, NodeID: testNodeID1})))
+	_, err := txn.Delete(allocationsTableID, unsafe.Pointer(&testAllocation{ID: testAllocationID1}))
+	requireT.NoError(err)
+	requireT.NoError(txn.Commit())
+
+	txn = db.Txn(false)
+	requireT.Empty(readAllocationNodes(t, txn, "a"))
+	requireT.Empty(readAllocationNodes(t, txn, "b"))
+	requireT.Equal([]memdb.ID{testAllocationID2, testAllocationID3}, readAllocationNodes(t, txn, "c"))
+
+	// Snapshot is not affected.
+	requireT.Equal([]memdb.ID{testAllocationID1, testAllocationID2}, readAllocationNodes(t, snapshot, "a"))
+
+	txn = db.Txn(true)
+	_, err = txn.Delete(nodesTableID, unsafe.Pointer(&testNode{ID: testNodeID1}))
+	requireT.NoError(err)
+	requireT.NoError(txn.Commit())
+
+	txn = db.Txn(false)
+	requireT.Empty(readAllocationNodes(t, txn, "c"))
+}
+
+func TestView_DirectWrite(t *testing.T) {
+	requireT := require.New(t)
+	db := testViewDB(t)
+
+	txn := db.Txn(true)
+	err := insert(txn, allocationNodesTableID, unsafe.Pointer(&testAllocationNode{ID: testAllocationID1}))
+	requireT.ErrorContains(err, "is a view")
+	_, err = txn.Delete(allocationNodesTableID, unsafe.Pointer(&testAllocationNode{ID: testAllocationID1}))
+	requireT.ErrorContains(err, "is a view")
+
+	requireT.ErrorContains(db.DropTable(allocationNodesTableID), "used by views")
+	requireT.ErrorContains(db.DropTable(nodesTableID), "used by views")
+}
+
+func TestView_Validation(t *testing.T) {
+	requireT := require.New(t)
+
+	config := func(views ...memdb.View) memdb.Config {
+		return memdb.Config{
+			Entities: []reflect.Type{
+				reflect.TypeFor[testNode](),
+				reflect.TypeFor[testAllocation](),
+			},
+			Views: views,
+		}
+	}
+
+	_, err := memdb.NewMemDB(config(
+		memdb.NewView(func(txn *memdb.Txn, a *testAllocation) []*testAllocationNode {
+			return nil
+		}),
+	))
+	requireT.ErrorContains(err, "view of undefined entity")
+
+	_, err = memdb.NewMemDB(config(
+		memdb.NewView(func(txn *memdb.Txn, a *testAllocationNode) []*testAllocation {
+			return nil
+		}),
+	))
+	requireT.ErrorContains(err, "view from undefined entity")
+
+	_, err = memdb.NewMemDB(config(
+		memdb.NewView(func(txn *memdb.Txn, n *testNode) []*testAllocation {
+			return nil
+		}),
+		memdb.NewView(func(txn *memdb.Txn, a *testAllocation) []*testNode {
+			return nil
+		}),
+	))
+	requireT.ErrorContains(err, "can't be derived from view")
+}